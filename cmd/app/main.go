@@ -1,11 +1,20 @@
 package main
 
 import (
+	"challenge/internal/attachment"
+	"challenge/internal/eventbus"
+	"challenge/internal/grpcserver"
 	"challenge/internal/httpserver"
+	"challenge/internal/outbox"
 	"challenge/internal/repository"
+	"challenge/internal/webhook"
+	"challenge/pkg/blobstore"
 	"challenge/pkg/env"
 	"challenge/pkg/gormprovider"
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 
 	"github.com/rs/zerolog/log"
@@ -29,11 +38,82 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize database")
 	}
+	err = repository.Migrate(sqlProvider)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate database")
+	}
+
+	blobStore, err := blobstore.NewStoreFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize blob store")
+	}
+
+	// Clean up orphaned attachments in the background
+	attachmentRepository := repository.NewAttachmentRepository(sqlProvider)
+	go attachment.NewCleaner(attachmentRepository, blobStore, attachment.OrphanGrace()).Run(context.Background(), attachment.CleanupInterval())
+
+	// Post webhook deliveries in the background
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(sqlProvider)
+	webhookDispatcher := webhook.NewDispatcher(webhookDeliveryRepository, &http.Client{Timeout: webhook.Timeout()}, webhook.MaxAttempts(), webhook.RetryBackoff())
+	go webhookDispatcher.Run(context.Background(), webhook.DispatchInterval())
+
+	// Publish the events of committed changes in the background
+	outboxRepository := repository.NewOutboxRepository(sqlProvider)
+	outboxPublisher, err := outbox.NewPublisherFromEnv(webhookDeliveryRepository)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize outbox publisher")
+	}
+	go outbox.NewDispatcher(outboxRepository, outboxPublisher, outbox.Lease(), outbox.RetryBackoff()).Run(context.Background(), outbox.DispatchInterval())
+
+	// Question changes are streamed to the subscribers of both servers
+	questionEvents := eventbus.NewBus(eventbus.HistorySize())
+	questionRepository := repository.NewQuestionRepository(sqlProvider)
+	questionOptionRepository := repository.NewQuestionOptionRepository(sqlProvider)
+	questionTagRepository := repository.NewQuestionTagRepository(sqlProvider)
+	questionTranslationRepository := repository.NewQuestionTranslationRepository(sqlProvider)
+	auditEntryRepository := repository.NewAuditEntryRepository(sqlProvider)
+
+	// Serve the question service over gRPC in the background
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%s", grpcserver.Port()))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen for grpc server")
+	}
+	grpcServer := grpcserver.NewServer(questionRepository, httpserver.NewQuestionWriter(
+		questionRepository,
+		questionOptionRepository,
+		questionTagRepository,
+		attachmentRepository,
+		questionTranslationRepository,
+		outboxRepository,
+		auditEntryRepository,
+		questionEvents,
+	))
+	go func() {
+		err := grpcServer.Serve(grpcListener)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to start grpc server")
+		}
+	}()
 
-	server := httpserver.NewServer(
-		repository.NewQuestionRepository(sqlProvider),
-		repository.NewQuestionOptionRepository(sqlProvider),
-	)
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:            questionRepository,
+		QuestionOption:      questionOptionRepository,
+		QuestionTag:         questionTagRepository,
+		QuestionComment:     repository.NewQuestionCommentRepository(sqlProvider),
+		Assessment:          repository.NewAssessmentRepository(sqlProvider),
+		AssessmentQuestion:  repository.NewAssessmentQuestionRepository(sqlProvider),
+		Attempt:             repository.NewAttemptRepository(sqlProvider),
+		AttemptAnswer:       repository.NewAttemptAnswerRepository(sqlProvider),
+		QuestionStats:       repository.NewQuestionStatsRepository(sqlProvider),
+		QuestionOptionStats: repository.NewQuestionOptionStatsRepository(sqlProvider),
+		IdempotencyKey:      repository.NewIdempotencyKeyRepository(sqlProvider),
+		Attachment:          attachmentRepository,
+		QuestionTranslation: questionTranslationRepository,
+		WebhookSubscription: repository.NewWebhookSubscriptionRepository(sqlProvider),
+		WebhookDelivery:     webhookDeliveryRepository,
+		Outbox:              outboxRepository,
+		AuditEntry:          auditEntryRepository,
+	}, blobStore, questionEvents)
 	err = server.Listen(fmt.Sprintf(":%s", httpPort))
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to start http server")
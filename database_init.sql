@@ -1,13 +1,249 @@
+CREATE TABLE IF NOT EXISTS attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	blob_key TEXT NOT NULL UNIQUE,
+	filename TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	sha256 TEXT NOT NULL,
+	author_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS questions (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL DEFAULT '',
 	body TEXT NOT NULL,
-	author_id INTEGER NOT NULL
+	body_format TEXT NOT NULL DEFAULT '',
+	explanation TEXT NOT NULL DEFAULT '',
+	explanation_format TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	difficulty TEXT NOT NULL DEFAULT '',
+	duration_seconds INTEGER NOT NULL DEFAULT 0,
+	numeric_answer REAL,
+	numeric_tolerance REAL NOT NULL DEFAULT 0,
+	attachment_id INTEGER,
+	author_id INTEGER NOT NULL,
+	revision INTEGER NOT NULL DEFAULT 1,
+	FOREIGN KEY(attachment_id) REFERENCES attachments(id)
 );
 
 CREATE TABLE IF NOT EXISTS question_options (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
 	body TEXT NOT NULL,
+  body_format TEXT NOT NULL DEFAULT '',
+  feedback TEXT NOT NULL DEFAULT '',
+  feedback_format TEXT NOT NULL DEFAULT '',
   correct INTEGER NOT NULL,
+  position INTEGER NOT NULL DEFAULT 0,
+  attachment_id INTEGER,
   question_id INTEGER NOT NULL,
-  FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE
-);
\ No newline at end of file
+  FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE,
+  FOREIGN KEY(attachment_id) REFERENCES attachments(id)
+);
+
+CREATE TABLE IF NOT EXISTS question_tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	question_id INTEGER NOT NULL,
+	FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS question_tags_name ON question_tags(name);
+
+CREATE TABLE IF NOT EXISTS question_translations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	question_id INTEGER NOT NULL,
+	question_revision INTEGER NOT NULL,
+	language TEXT NOT NULL,
+	body TEXT NOT NULL,
+	body_format TEXT NOT NULL DEFAULT '',
+	explanation TEXT NOT NULL DEFAULT '',
+	explanation_format TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE,
+	UNIQUE(question_id, language)
+);
+
+CREATE TABLE IF NOT EXISTS question_option_translations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	question_translation_id INTEGER NOT NULL,
+	question_option_id INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	body_format TEXT NOT NULL DEFAULT '',
+	feedback TEXT NOT NULL DEFAULT '',
+	feedback_format TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY(question_translation_id) REFERENCES question_translations(id) ON DELETE CASCADE,
+	FOREIGN KEY(question_option_id) REFERENCES question_options(id) ON DELETE CASCADE,
+	UNIQUE(question_translation_id, question_option_id)
+);
+
+CREATE TABLE IF NOT EXISTS question_comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	body TEXT NOT NULL,
+	resolved INTEGER NOT NULL DEFAULT 0,
+	question_id INTEGER NOT NULL,
+	question_option_id INTEGER,
+	parent_id INTEGER,
+	author_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE,
+	FOREIGN KEY(question_option_id) REFERENCES question_options(id) ON DELETE SET NULL,
+	FOREIGN KEY(parent_id) REFERENCES question_comments(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS assessments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	time_limit_seconds INTEGER NOT NULL DEFAULT 0,
+	passing_score REAL NOT NULL DEFAULT 0,
+	partial_credit INTEGER NOT NULL DEFAULT 0,
+	negative_marking INTEGER NOT NULL DEFAULT 0,
+	author_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS assessment_questions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	position INTEGER NOT NULL,
+	points REAL,
+	question_id INTEGER NOT NULL,
+	question_revision INTEGER NOT NULL,
+	snapshot TEXT NOT NULL,
+	assessment_id INTEGER NOT NULL,
+	FOREIGN KEY(assessment_id) REFERENCES assessments(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at DATETIME NOT NULL,
+	deadline DATETIME,
+	finished_at DATETIME,
+	score REAL,
+	max_score REAL,
+	passed INTEGER,
+	option_seed INTEGER NOT NULL DEFAULT 0,
+	candidate_id INTEGER NOT NULL,
+	assessment_id INTEGER NOT NULL,
+	FOREIGN KEY(assessment_id) REFERENCES assessments(id) ON DELETE CASCADE,
+	UNIQUE(assessment_id, candidate_id)
+);
+
+CREATE TABLE IF NOT EXISTS attempt_answers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	selected_options TEXT NOT NULL,
+	numeric_value REAL,
+	score REAL,
+	answered_at DATETIME NOT NULL,
+	attempt_id INTEGER NOT NULL,
+	assessment_question_id INTEGER NOT NULL,
+	FOREIGN KEY(attempt_id) REFERENCES attempts(id) ON DELETE CASCADE,
+	FOREIGN KEY(assessment_question_id) REFERENCES assessment_questions(id) ON DELETE CASCADE,
+	UNIQUE(attempt_id, assessment_question_id)
+);
+
+CREATE TABLE IF NOT EXISTS question_stats (
+	question_id INTEGER NOT NULL,
+	question_revision INTEGER NOT NULL,
+	responses INTEGER NOT NULL DEFAULT 0,
+	score_sum REAL NOT NULL DEFAULT 0,
+	score_squares_sum REAL NOT NULL DEFAULT 0,
+	total_sum REAL NOT NULL DEFAULT 0,
+	total_squares_sum REAL NOT NULL DEFAULT 0,
+	score_total_sum REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY(question_id, question_revision)
+);
+
+CREATE TABLE IF NOT EXISTS question_option_stats (
+	question_id INTEGER NOT NULL,
+	question_revision INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	correct INTEGER NOT NULL DEFAULT 0,
+	selections INTEGER NOT NULL DEFAULT 0,
+	total_sum REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY(question_id, question_revision, position)
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	user_id INTEGER NOT NULL,
+	key TEXT NOT NULL,
+	request_hash TEXT NOT NULL,
+	status_code INTEGER NOT NULL,
+	response_body BLOB NOT NULL,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	PRIMARY KEY(user_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL,
+	events TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	author_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_subscription_id INTEGER NOT NULL,
+	event_id TEXT NOT NULL,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at DATETIME NOT NULL,
+	last_attempt_at DATETIME,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	delivered_at DATETIME,
+	FOREIGN KEY(webhook_subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_event ON webhook_deliveries(webhook_subscription_id, event_id);
+
+CREATE TABLE IF NOT EXISTS outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id TEXT NOT NULL UNIQUE,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	available_at DATETIME NOT NULL,
+	lease_token TEXT,
+	leased_until DATETIME,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	published_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished ON outbox(published_at, available_at);
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	actor_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	entity_type TEXT NOT NULL,
+	entity_id INTEGER NOT NULL,
+	before TEXT NOT NULL,
+	after TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT '',
+	ip TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_entries_actor ON audit_entries(actor_id);
+
+CREATE INDEX IF NOT EXISTS audit_entries_entity ON audit_entries(entity_type, entity_id);
+
+CREATE INDEX IF NOT EXISTS audit_entries_created_at ON audit_entries(created_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_update BEFORE UPDATE ON audit_entries
+BEGIN
+	SELECT RAISE(ABORT, 'audit entries are append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete BEFORE DELETE ON audit_entries
+BEGIN
+	SELECT RAISE(ABORT, 'audit entries are append-only');
+END;
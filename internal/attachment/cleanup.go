@@ -0,0 +1,121 @@
+// Package attachment removes the attachments and blobs left behind by edits, deletes and failed uploads
+package attachment
+
+import (
+	"challenge/internal/repository"
+	"challenge/pkg/blobstore"
+	"challenge/pkg/env"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OrphanGrace reads how long unreferenced attachments are kept, 24 hours by default
+func OrphanGrace() time.Duration {
+	return env.GetDurationOrDefault("ATTACHMENT_ORPHAN_GRACE", 24*time.Hour)
+}
+
+// CleanupInterval reads how often orphans are cleaned up, every hour by default
+func CleanupInterval() time.Duration {
+	return env.GetDurationOrDefault("ATTACHMENT_CLEANUP_INTERVAL", time.Hour)
+}
+
+type CleanupResult struct {
+	Attachments uint `json:"attachments"` // Unreferenced attachments deleted with their blob
+	Blobs       uint `json:"blobs"`       // Blobs deleted because no attachment has their key
+}
+
+// Cleaner deletes what is orphaned for longer than the grace period, which gives clients
+// time to reference the attachments they upload
+type Cleaner struct {
+	attachmentRepository repository.AttachmentRepository
+	blobStore            blobstore.Store
+	grace                time.Duration
+}
+
+func NewCleaner(attachmentRepository repository.AttachmentRepository, blobStore blobstore.Store, grace time.Duration) *Cleaner {
+	return &Cleaner{attachmentRepository: attachmentRepository, blobStore: blobStore, grace: grace}
+}
+
+func (c *Cleaner) Cleanup(ctx context.Context, now time.Time) (CleanupResult, error) {
+	var result CleanupResult
+	createdBefore := now.Add(-c.grace)
+
+	orphanedAttachments, err := c.attachmentRepository.ListOrphanedAttachments(ctx, createdBefore)
+	if err != nil {
+		return result, err
+	}
+	for _, orphanedAttachment := range orphanedAttachments {
+		// A question may have referenced the attachment since it was listed
+		deleted := false
+		err := c.attachmentRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+			referenced, err := c.attachmentRepository.IsAttachmentReferenced(txCtx, orphanedAttachment.Id)
+			if err != nil || referenced {
+				return err
+			}
+			deleted = true
+			return c.attachmentRepository.DeleteAttachment(txCtx, orphanedAttachment.Id)
+		})
+		if err != nil {
+			return result, err
+		}
+		if !deleted {
+			continue
+		}
+		result.Attachments++
+
+		// A blob left behind by a failed delete is collected below on the next run
+		err = c.blobStore.Delete(ctx, orphanedAttachment.BlobKey)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Uploads store the blob before the attachment, so recent blobs may still get one
+	blobs, err := c.blobStore.List(ctx)
+	if err != nil {
+		return result, err
+	}
+	blobKeys, err := c.attachmentRepository.ListAttachmentBlobKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+	attachedBlobKeys := map[string]bool{}
+	for _, blobKey := range blobKeys {
+		attachedBlobKeys[blobKey] = true
+	}
+	for _, blob := range blobs {
+		if attachedBlobKeys[blob.Key] || !blob.ModifiedAt.Before(createdBefore) {
+			continue
+		}
+		err := c.blobStore.Delete(ctx, blob.Key)
+		if err != nil {
+			return result, err
+		}
+		result.Blobs++
+	}
+
+	return result, nil
+}
+
+// Run cleans up every interval until the context is done
+func (c *Cleaner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := c.Cleanup(ctx, now.UTC())
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to clean up attachments")
+				continue
+			}
+			if result.Attachments > 0 || result.Blobs > 0 {
+				log.Info().Uint("attachments", result.Attachments).Uint("blobs", result.Blobs).Msg("Cleaned up attachments")
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package attachment_test
+
+import (
+	"challenge/internal/attachment"
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/blobstore"
+	"challenge/pkg/gormprovider"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleaner_Cleanup(t *testing.T) {
+	databaseInitSql, err := os.ReadFile("../../database_init.sql")
+	require.NoError(t, err)
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, string(databaseInitSql))
+	dir := t.TempDir()
+	blobStore, err := blobstore.NewFileSystemStore(dir)
+	require.NoError(t, err)
+	attachmentRepo := repository.NewAttachmentRepository(sqlProvider)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	old := now.Add(-2 * time.Hour)
+
+	put := func(blobKey string, modifiedAt time.Time) {
+		require.NoError(t, blobStore.Put(ctx, blobKey, strings.NewReader(blobKey), int64(len(blobKey)), "text/plain"))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, blobKey), modifiedAt, modifiedAt))
+	}
+	create := func(blobKey string, createdAt time.Time) entity.Attachment {
+		put(blobKey, createdAt)
+		attachment := entity.Attachment{BlobKey: blobKey, CreatedAt: createdAt}
+		require.NoError(t, attachmentRepo.CreateAttachment(ctx, &attachment))
+		return attachment
+	}
+	referenced := create("referenced", old)
+	create("orphaned", old)
+	create("recent", now)
+	put("leftover", old)
+	put("uploading", now)
+	err = sqlProvider.DB.Table("questions").Create(&entity.Question{Id: 1, AttachmentId: &referenced.Id}).Error
+	require.NoError(t, err)
+
+	result, err := attachment.NewCleaner(attachmentRepo, blobStore, time.Hour).Cleanup(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.CleanupResult{Attachments: 1, Blobs: 1}, result)
+
+	blobs, err := blobStore.List(ctx)
+	require.NoError(t, err)
+	blobKeys := []string{}
+	for _, blob := range blobs {
+		blobKeys = append(blobKeys, blob.Key)
+	}
+	assert.ElementsMatch(t, []string{"referenced", "recent", "uploading"}, blobKeys)
+	attachmentBlobKeys, err := attachmentRepo.ListAttachmentBlobKeys(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"referenced", "recent"}, attachmentBlobKeys)
+}
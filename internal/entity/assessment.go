@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+type Assessment struct {
+	Id                  uint                 `json:"id" gorm:"primaryKey"`
+	Title               string               `json:"title" validate:"required"`
+	TimeLimitSeconds    uint                 `json:"timeLimitSeconds"`
+	PassingScore        float64              `json:"passingScore" validate:"min=0,max=100"` // Percentage of the total points
+	PartialCredit       bool                 `json:"partialCredit"`
+	NegativeMarking     bool                 `json:"negativeMarking"`
+	AssessmentQuestions []AssessmentQuestion `json:"questions" validate:"required,dive"`
+	AuthorId            uint                 `json:"-"`
+}
+
+type AssessmentQuestion struct {
+	Id               uint             `json:"-" gorm:"primaryKey"`
+	Position         uint             `json:"-"`
+	Points           *float64         `json:"points" validate:"omitempty,min=0"` // Defaults to DefaultAssessmentQuestionPoints
+	QuestionId       uint             `json:"questionId" validate:"required"`
+	QuestionRevision uint             `json:"questionRevision"`
+	Snapshot         QuestionSnapshot `json:"question" validate:"-"`
+	AssessmentId     uint             `json:"-"`
+}
+
+const DefaultAssessmentQuestionPoints = 1
+
+func (aq AssessmentQuestion) GetPoints() float64 {
+	if aq.Points == nil {
+		return DefaultAssessmentQuestionPoints
+	}
+	return *aq.Points
+}
+
+// QuestionSnapshot is a frozen copy of a question revision, so later edits don't change a live assessment
+type QuestionSnapshot Question
+
+func (s QuestionSnapshot) Value() (driver.Value, error) {
+	snapshotBytes, err := json.Marshal(Question(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(snapshotBytes), nil
+}
+
+func (s *QuestionSnapshot) Scan(value any) error {
+	var snapshotBytes []byte
+	switch v := value.(type) {
+	case string:
+		snapshotBytes = []byte(v)
+	case []byte:
+		snapshotBytes = v
+	default:
+		return errors.New("unsupported question snapshot type")
+	}
+	return json.Unmarshal(snapshotBytes, (*Question)(s))
+}
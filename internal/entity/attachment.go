@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Attachment is an uploaded file, such as a diagram or screenshot, that questions and options reference.
+// Its content lives in the blob store under BlobKey
+type Attachment struct {
+	Id          uint      `json:"id" gorm:"primaryKey"`
+	BlobKey     string    `json:"-"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Sha256      string    `json:"sha256"`
+	AuthorId    uint      `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
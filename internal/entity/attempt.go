@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type Attempt struct {
+	Id             uint            `json:"id" gorm:"primaryKey"`
+	StartedAt      time.Time       `json:"startedAt"`
+	Deadline       *time.Time      `json:"deadline"` // Not set when the assessment has no time limit
+	FinishedAt     *time.Time      `json:"finishedAt"`
+	Score          *float64        `json:"score"` // Set once finished
+	MaxScore       *float64        `json:"maxScore"`
+	Passed         *bool           `json:"passed"`
+	OptionSeed     int64           `json:"-"` // Shuffles the options of ordering questions, secret to the candidate
+	CandidateId    uint            `json:"candidateId"`
+	AssessmentId   uint            `json:"assessmentId"`
+	AttemptAnswers []AttemptAnswer `json:"answers"`
+}
+
+func (a Attempt) IsFinished() bool {
+	return a.FinishedAt != nil
+}
+
+func (a Attempt) IsExpired(now time.Time) bool {
+	return a.Deadline != nil && now.After(*a.Deadline)
+}
+
+type AttemptAnswer struct {
+	Id                   uint            `json:"-" gorm:"primaryKey"`
+	SelectedOptions      OptionPositions `json:"selectedOptions"`
+	NumericValue         *float64        `json:"numericValue"`
+	Score                *float64        `json:"score"` // Set once the attempt is finished
+	AnsweredAt           time.Time       `json:"answeredAt"`
+	AttemptId            uint            `json:"-"`
+	AssessmentQuestionId uint            `json:"assessmentQuestionId"`
+}
+
+// OptionPositions references options by their position in a question snapshot, stored as JSON
+type OptionPositions []uint
+
+func (p OptionPositions) Value() (driver.Value, error) {
+	if p == nil {
+		p = OptionPositions{}
+	}
+	positionsBytes, err := json.Marshal([]uint(p))
+	if err != nil {
+		return nil, err
+	}
+	return string(positionsBytes), nil
+}
+
+func (p *OptionPositions) Scan(value any) error {
+	var positionsBytes []byte
+	switch v := value.(type) {
+	case string:
+		positionsBytes = []byte(v)
+	case []byte:
+		positionsBytes = v
+	default:
+		return errors.New("unsupported option positions type")
+	}
+	return json.Unmarshal(positionsBytes, (*[]uint)(p))
+}
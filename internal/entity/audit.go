@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionResolve   = "resolve"
+	AuditActionUnresolve = "unresolve"
+)
+
+// Audited entity types
+const (
+	AuditEntityQuestion            = "question"
+	AuditEntityQuestionTranslation = "question_translation" // Identified by the question id, saving a translation recreates it
+	AuditEntityQuestionComment     = "question_comment"
+)
+
+// AuditEntry records who changed an entity and how. Entries are never updated or deleted
+type AuditEntry struct {
+	Id         uint            `json:"id" gorm:"primaryKey"`
+	ActorId    uint            `json:"actorId"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityId   uint            `json:"entityId"`
+	Before     json.RawMessage `json:"before"` // null for creates
+	After      json.RawMessage `json:"after"`  // null for deletes
+	RequestId  string          `json:"requestId"`
+	Ip         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// IdempotencyKey stores the response of a request sent with an Idempotency-Key header,
+// so retries of the same request replay it instead of running the request again
+type IdempotencyKey struct {
+	UserId       uint   `gorm:"primaryKey"`
+	Key          string `gorm:"primaryKey"`
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
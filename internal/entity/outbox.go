@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// OutboxEvent is an event written in the transaction of the change it describes, so it is published
+// if and only if the change is committed
+type OutboxEvent struct {
+	Id          uint       `json:"id" gorm:"primaryKey"`
+	EventId     string     `json:"eventId"`
+	EventType   string     `json:"eventType"`
+	Payload     string     `json:"-"` // Marshaled WebhookEvent
+	Attempts    uint       `json:"attempts"`
+	AvailableAt time.Time  `json:"availableAt"` // Not published before, pushed back after failures
+	LeaseToken  *string    `json:"-"`           // Identifies the dispatch publishing the event
+	LeasedUntil *time.Time `json:"leasedUntil"`
+	LastError   string     `json:"lastError"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	PublishedAt *time.Time `json:"publishedAt"`
+}
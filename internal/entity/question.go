@@ -1,15 +1,118 @@
 package entity
 
+import (
+	"challenge/internal/richtext"
+	"encoding/json"
+)
+
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+const (
+	QuestionTypeSingleChoice   = "single_choice"
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeNumeric        = "numeric"
+	QuestionTypeOrdering       = "ordering" // Options are stored in the correct order
+)
+
 type Question struct {
-	Id              uint             `json:"id" gorm:"primaryKey"`
-	Body            string           `json:"body" validate:"required"`
-	QuestionOptions []QuestionOption `json:"options" validate:"required"`
-	AuthorId        uint             `json:"-"`
+	Id                uint             `json:"id" gorm:"primaryKey"`
+	Type              string           `json:"type" validate:"omitempty,oneof=single_choice multiple_choice numeric ordering"`
+	Body              string           `json:"body" validate:"required"`
+	BodyFormat        string           `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	BodyHTML          string           `json:"bodyHtml,omitempty" gorm:"-"` // Only filled when rendering is requested
+	Explanation       string           `json:"explanation"`                 // Shown to candidates once their attempt is finished
+	ExplanationFormat string           `json:"explanationFormat" validate:"omitempty,oneof=plain markdown"`
+	ExplanationHTML   string           `json:"explanationHtml,omitempty" gorm:"-"`
+	Language          string           `json:"language" validate:"omitempty,bcp47_language_tag"` // Canonical language of the bodies
+	Translation       string           `json:"translation,omitempty" gorm:"-"`                   // Language of the translation served instead, if any
+	Difficulty        string           `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	DurationSeconds   uint             `json:"durationSeconds"` // Estimated time to answer
+	NumericAnswer     *float64         `json:"numericAnswer" validate:"required_if=Type numeric"`
+	NumericTolerance  float64          `json:"numericTolerance" validate:"min=0"`
+	QuestionOptions   []QuestionOption `json:"options" validate:"required_unless=Type numeric,dive"`
+	Tags              []QuestionTag    `json:"tags" validate:"dive"`
+	AttachmentId      *uint            `json:"attachmentId"` // Image or file shown with the body
+	AuthorId          uint             `json:"-"`
+	Revision          uint             `json:"revision"`
+}
+
+// GetType defaults to single choice, the type of questions created before types existed
+func (q Question) GetType() string {
+	if q.Type == "" {
+		return QuestionTypeSingleChoice
+	}
+	return q.Type
+}
+
+// GetLanguage defaults to english, the language of questions created before languages existed
+func (q Question) GetLanguage() string {
+	if q.Language == "" {
+		return DefaultLanguage
+	}
+	return q.Language
+}
+
+// GetBodyFormat defaults to plain text, the format of questions created before formats existed
+func (q Question) GetBodyFormat() string {
+	if q.BodyFormat == "" {
+		return richtext.FormatPlain
+	}
+	return q.BodyFormat
+}
+
+// GetExplanationFormat defaults to plain text
+func (q Question) GetExplanationFormat() string {
+	if q.ExplanationFormat == "" {
+		return richtext.FormatPlain
+	}
+	return q.ExplanationFormat
 }
 
 type QuestionOption struct {
-	Id         uint   `json:"-" gorm:"primaryKey"`
-	Body       string `json:"body" validate:"required"`
-	Correct    *bool  `json:"correct" validate:"required"` // Because of validations, this bool has to be a pointer
-	QuestionId uint   `json:"-"`
+	Id             uint   `json:"id" gorm:"primaryKey"` // Kept across updates, zero for new options
+	Body           string `json:"body" validate:"required"`
+	BodyFormat     string `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	BodyHTML       string `json:"bodyHtml,omitempty" gorm:"-"` // Only filled when rendering is requested
+	Feedback       string `json:"feedback"`                    // Shown to candidates once their attempt is finished
+	FeedbackFormat string `json:"feedbackFormat" validate:"omitempty,oneof=plain markdown"`
+	FeedbackHTML   string `json:"feedbackHtml,omitempty" gorm:"-"`
+	Correct        *bool  `json:"correct"` // Required except for ordering questions, so this bool has to be a pointer
+	Position       uint   `json:"-"`       // Order of the option in the question
+	AttachmentId   *uint  `json:"attachmentId"`
+	QuestionId     uint   `json:"-"`
+}
+
+// GetBodyFormat defaults to plain text, the format of options created before formats existed
+func (o QuestionOption) GetBodyFormat() string {
+	if o.BodyFormat == "" {
+		return richtext.FormatPlain
+	}
+	return o.BodyFormat
+}
+
+// GetFeedbackFormat defaults to plain text
+func (o QuestionOption) GetFeedbackFormat() string {
+	if o.FeedbackFormat == "" {
+		return richtext.FormatPlain
+	}
+	return o.FeedbackFormat
+}
+
+// QuestionTag is serialized as its name
+type QuestionTag struct {
+	Id         uint   `gorm:"primaryKey"`
+	Name       string `validate:"required,max=50"`
+	QuestionId uint
+}
+
+func (t QuestionTag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+func (t *QuestionTag) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.Name)
 }
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type QuestionComment struct {
+	Id               uint              `json:"id" gorm:"primaryKey"`
+	Body             string            `json:"body" validate:"required"`
+	Resolved         bool              `json:"resolved"`
+	QuestionId       uint              `json:"questionId"`
+	QuestionOptionId *uint             `json:"optionId"`
+	ParentId         *uint             `json:"parentId"`
+	AuthorId         uint              `json:"authorId"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	Replies          []QuestionComment `json:"replies,omitempty" gorm:"-"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+// QuestionStats holds the running sums of the responses to a question revision,
+// so that statistics are updated incrementally as attempts finish
+type QuestionStats struct {
+	QuestionId       uint    `json:"questionId" gorm:"primaryKey"`
+	QuestionRevision uint    `json:"questionRevision" gorm:"primaryKey"`
+	Responses        uint    `json:"responses"`
+	ScoreSum         float64 `json:"scoreSum"` // Fractions of the question points
+	ScoreSquaresSum  float64 `json:"scoreSquaresSum"`
+	TotalSum         float64 `json:"totalSum"` // Fractions of the attempt max score
+	TotalSquaresSum  float64 `json:"totalSquaresSum"`
+	ScoreTotalSum    float64 `json:"scoreTotalSum"`
+}
+
+// QuestionOptionStats holds the running sums of the selections of a question revision option
+type QuestionOptionStats struct {
+	QuestionId       uint    `json:"questionId" gorm:"primaryKey"`
+	QuestionRevision uint    `json:"questionRevision" gorm:"primaryKey"`
+	Position         uint    `json:"position" gorm:"primaryKey"`
+	Correct          bool    `json:"correct"`
+	Selections       uint    `json:"selections"`
+	TotalSum         float64 `json:"totalSum"` // Fractions of the attempt max score of the candidates that selected it
+}
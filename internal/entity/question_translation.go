@@ -0,0 +1,59 @@
+package entity
+
+import "challenge/internal/richtext"
+
+// DefaultLanguage is the canonical language of questions created before languages existed
+const DefaultLanguage = "en"
+
+// QuestionTranslation is the question body and option bodies in a language other than the canonical one.
+// Options without a translation fall back to their canonical body, and an empty explanation or feedback
+// to the canonical one
+type QuestionTranslation struct {
+	Id                uint                        `json:"-" gorm:"primaryKey"`
+	QuestionId        uint                        `json:"-"`
+	QuestionRevision  uint                        `json:"questionRevision"` // Revision of the question that was translated
+	Language          string                      `json:"language"`
+	Body              string                      `json:"body" validate:"required"`
+	BodyFormat        string                      `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	Explanation       string                      `json:"explanation"`
+	ExplanationFormat string                      `json:"explanationFormat" validate:"omitempty,oneof=plain markdown"`
+	Options           []QuestionOptionTranslation `json:"options" validate:"dive"`
+}
+
+func (t QuestionTranslation) GetBodyFormat() string {
+	if t.BodyFormat == "" {
+		return richtext.FormatPlain
+	}
+	return t.BodyFormat
+}
+
+func (t QuestionTranslation) GetExplanationFormat() string {
+	if t.ExplanationFormat == "" {
+		return richtext.FormatPlain
+	}
+	return t.ExplanationFormat
+}
+
+type QuestionOptionTranslation struct {
+	Id                    uint   `json:"-" gorm:"primaryKey"`
+	QuestionTranslationId uint   `json:"-"`
+	QuestionOptionId      uint   `json:"optionId" validate:"required"`
+	Body                  string `json:"body" validate:"required"`
+	BodyFormat            string `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	Feedback              string `json:"feedback"`
+	FeedbackFormat        string `json:"feedbackFormat" validate:"omitempty,oneof=plain markdown"`
+}
+
+func (t QuestionOptionTranslation) GetBodyFormat() string {
+	if t.BodyFormat == "" {
+		return richtext.FormatPlain
+	}
+	return t.BodyFormat
+}
+
+func (t QuestionOptionTranslation) GetFeedbackFormat() string {
+	if t.FeedbackFormat == "" {
+		return richtext.FormatPlain
+	}
+	return t.FeedbackFormat
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookEventQuestionCreated   = "question.created"
+	WebhookEventQuestionUpdated   = "question.updated"
+	WebhookEventQuestionDeleted   = "question.deleted"
+	WebhookEventQuestionPublished = "question.published" // The question was snapshotted into an assessment
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead" // Out of attempts, kept as a dead letter until retried
+)
+
+// WebhookSubscription posts the events of its types to its url, signed with its secret
+type WebhookSubscription struct {
+	Id        uint              `json:"id" gorm:"primaryKey"`
+	Url       string            `json:"url"`
+	Events    WebhookEventTypes `json:"events"`
+	Secret    string            `json:"-"`
+	AuthorId  uint              `json:"-"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// WebhookEventTypes are the event types of a subscription, stored as JSON
+type WebhookEventTypes []string
+
+func (t WebhookEventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		t = WebhookEventTypes{}
+	}
+	typesBytes, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(typesBytes), nil
+}
+
+func (t *WebhookEventTypes) Scan(value any) error {
+	var typesBytes []byte
+	switch v := value.(type) {
+	case string:
+		typesBytes = []byte(v)
+	case []byte:
+		typesBytes = v
+	default:
+		return errors.New("unsupported webhook event types type")
+	}
+	return json.Unmarshal(typesBytes, (*[]string)(t))
+}
+
+// WebhookEvent is the body posted to the subscriptions of its type
+type WebhookEvent struct {
+	Id        string          `json:"id"` // The same in every delivery and retry of the event
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery is an event to post to a subscription and the outcome of its last attempt
+type WebhookDelivery struct {
+	Id                    uint                 `json:"id" gorm:"primaryKey"`
+	WebhookSubscriptionId uint                 `json:"webhookId"`
+	WebhookSubscription   *WebhookSubscription `json:"-"`
+	EventId               string               `json:"eventId"`
+	EventType             string               `json:"eventType"`
+	Payload               string               `json:"-"` // Marshaled WebhookEvent
+	Status                string               `json:"status"`
+	Attempts              uint                 `json:"attempts"`
+	NextAttemptAt         time.Time            `json:"nextAttemptAt"`
+	LastAttemptAt         *time.Time           `json:"lastAttemptAt"`
+	LastStatusCode        int                  `json:"lastStatusCode"` // Zero when the receiver could not be reached
+	LastError             string               `json:"lastError"`
+	CreatedAt             time.Time            `json:"createdAt"`
+	DeliveredAt           *time.Time           `json:"deliveredAt"`
+}
@@ -0,0 +1,156 @@
+// Package eventbus fans question changes out to the subscribers of this process, keeping the
+// latest events so subscribers that reconnect can resume where they left off
+package eventbus
+
+import (
+	"challenge/pkg/env"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// subscriptionBufferSize is how many events a subscription holds before it is too slow to keep up
+const subscriptionBufferSize = 64
+
+// HistorySize reads how many of the latest events are kept to resume subscriptions, 1000 by default
+func HistorySize() int {
+	historySize, err := strconv.Atoi(env.GetOrDefault("EVENT_BUS_HISTORY_SIZE", "1000"))
+	if err != nil || historySize <= 0 {
+		log.Warn().Err(err).Msg("Invalid EVENT_BUS_HISTORY_SIZE, using 1000")
+		return 1000
+	}
+	return historySize
+}
+
+// Event is a change of a question
+type Event struct {
+	Id         string // Assigned on publish, ids increase in publication order
+	Type       string
+	QuestionId uint
+	AuthorId   uint
+	Tags       []string
+	Data       json.RawMessage
+}
+
+// Filter selects the events of a subscription
+type Filter struct {
+	AuthorId uint     // Any author when 0
+	Tags     []string // Events with any of the tags, any event when empty
+}
+
+func (f Filter) Match(event Event) bool {
+	if f.AuthorId != 0 && event.AuthorId != f.AuthorId {
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, tag := range f.Tags {
+		for _, eventTag := range event.Tags {
+			if tag == eventTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type Bus struct {
+	mux           sync.Mutex
+	historySize   int
+	history       []Event
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		historySize:   historySize,
+		history:       []Event{},
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish assigns the event its id and sends it to the matching subscriptions. Subscriptions too slow
+// to keep up are closed instead of blocking the publisher, their subscribers resume from the last event they got
+func (b *Bus) Publish(event Event) Event {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	event.Id = ulid.Make().String()
+	if len(b.history) == b.historySize {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, event)
+
+	for subscription := range b.subscriptions {
+		if !subscription.filter.Match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.unsubscribe(subscription)
+		}
+	}
+	return event
+}
+
+// Subscribe returns a subscription to the events matching the filter. When lastEventId is not empty, the kept
+// events published after it are received first; events older than the kept ones can't be resumed
+func (b *Bus) Subscribe(filter Filter, lastEventId string) *Subscription {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	replay := []Event{}
+	if lastEventId != "" {
+		start := sort.Search(len(b.history), func(i int) bool {
+			return b.history[i].Id > lastEventId
+		})
+		for _, event := range b.history[start:] {
+			if filter.Match(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	subscription := &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan Event, subscriptionBufferSize+len(replay)),
+	}
+	for _, event := range replay {
+		subscription.events <- event
+	}
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+func (b *Bus) unsubscribe(subscription *Subscription) {
+	if _, ok := b.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(b.subscriptions, subscription)
+	close(subscription.events)
+}
+
+type Subscription struct {
+	bus    *Bus
+	filter Filter
+	events chan Event
+}
+
+// Events receives the events of the subscription, it is closed when the subscription is closed
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.bus.mux.Lock()
+	defer s.bus.mux.Unlock()
+	s.bus.unsubscribe(s)
+}
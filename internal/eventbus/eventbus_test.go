@@ -0,0 +1,84 @@
+package eventbus_test
+
+import (
+	"challenge/internal/eventbus"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the events already sent to the subscription
+func receive(subscription *eventbus.Subscription) []eventbus.Event {
+	events := []eventbus.Event{}
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	event := eventbus.Event{AuthorId: 1, Tags: []string{"go", "sql"}}
+	assert.True(t, eventbus.Filter{}.Match(event))
+	assert.True(t, eventbus.Filter{AuthorId: 1, Tags: []string{"rust", "sql"}}.Match(event))
+	assert.False(t, eventbus.Filter{AuthorId: 2}.Match(event))
+	assert.False(t, eventbus.Filter{Tags: []string{"rust"}}.Match(event))
+}
+
+func TestBus_Publish(t *testing.T) {
+	bus := eventbus.NewBus(10)
+	all := bus.Subscribe(eventbus.Filter{}, "")
+	tagged := bus.Subscribe(eventbus.Filter{Tags: []string{"go"}}, "")
+
+	first := bus.Publish(eventbus.Event{Type: "question.created", QuestionId: 1, Tags: []string{"go"}})
+	second := bus.Publish(eventbus.Event{Type: "question.created", QuestionId: 2})
+	assert.Less(t, first.Id, second.Id)
+
+	assert.Equal(t, []eventbus.Event{first, second}, receive(all))
+	assert.Equal(t, []eventbus.Event{first}, receive(tagged))
+
+	// Closed subscriptions get nothing else
+	all.Close()
+	all.Close()
+	bus.Publish(eventbus.Event{Type: "question.deleted", QuestionId: 1, Tags: []string{"go"}})
+	assert.Empty(t, receive(all))
+	assert.Len(t, receive(tagged), 1)
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := eventbus.NewBus(3)
+	events := []eventbus.Event{}
+	for questionId := uint(1); questionId <= 5; questionId++ {
+		events = append(events, bus.Publish(eventbus.Event{Type: "question.created", QuestionId: questionId, AuthorId: questionId % 2}))
+	}
+
+	// Resumes after the last event received, from the kept events matching the filter
+	assert.Equal(t, events[3:], receive(bus.Subscribe(eventbus.Filter{}, events[2].Id)))
+	assert.Equal(t, []eventbus.Event{events[4]}, receive(bus.Subscribe(eventbus.Filter{AuthorId: 1}, events[2].Id)))
+	assert.Equal(t, events[2:], receive(bus.Subscribe(eventbus.Filter{}, events[0].Id)))
+	assert.Empty(t, receive(bus.Subscribe(eventbus.Filter{}, "")))
+	assert.Empty(t, receive(bus.Subscribe(eventbus.Filter{}, events[4].Id)))
+}
+
+func TestBus_SlowSubscription(t *testing.T) {
+	bus := eventbus.NewBus(1000)
+	subscription := bus.Subscribe(eventbus.Filter{}, "")
+	for i := 0; i < 100; i++ {
+		bus.Publish(eventbus.Event{Type: "question.created"})
+	}
+
+	// The subscription was closed once its buffer was full, so its subscriber can resume from what it got
+	events := receive(subscription)
+	require.NotEmpty(t, events)
+	assert.Less(t, len(events), 100)
+	_, ok := <-subscription.Events()
+	assert.False(t, ok)
+	assert.Len(t, receive(bus.Subscribe(eventbus.Filter{}, events[len(events)-1].Id)), 100-len(events))
+}
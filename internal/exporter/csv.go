@@ -0,0 +1,116 @@
+package exporter
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/importer"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{
+	importer.CSVColumnQuestion,
+	importer.CSVColumnType,
+	importer.CSVColumnBody,
+	importer.CSVColumnDifficulty,
+	importer.CSVColumnDurationSeconds,
+	importer.CSVColumnTags,
+	importer.CSVColumnNumericAnswer,
+	importer.CSVColumnNumericTolerance,
+	importer.CSVColumnOption,
+	importer.CSVColumnCorrect,
+	importer.CSVColumnBodyFormat,
+	importer.CSVColumnOptionFormat,
+	importer.CSVColumnExplanation,
+	importer.CSVColumnExplanationFormat,
+	importer.CSVColumnFeedback,
+	importer.CSVColumnFeedbackFormat,
+}
+
+// csvWriter writes a row per option, keyed by the question id, in the columns read by the importer
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) WriteQuestion(question entity.Question) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	tags := make([]string, len(question.Tags))
+	for i, tag := range question.Tags {
+		tags[i] = tag.Name
+	}
+	var numericAnswer string
+	if question.NumericAnswer != nil {
+		numericAnswer = strconv.FormatFloat(*question.NumericAnswer, 'g', -1, 64)
+	}
+	row := []string{
+		strconv.FormatUint(uint64(question.Id), 10),
+		question.Type,
+		question.Body,
+		question.Difficulty,
+		strconv.FormatUint(uint64(question.DurationSeconds), 10),
+		strings.Join(tags, "|"),
+		numericAnswer,
+		strconv.FormatFloat(question.NumericTolerance, 'g', -1, 64),
+		"",
+		"",
+		question.BodyFormat,
+		"",
+		question.Explanation,
+		question.ExplanationFormat,
+		"",
+		"",
+	}
+
+	// Numeric questions have a single row without option
+	if len(question.QuestionOptions) == 0 {
+		return cw.write(row)
+	}
+	for _, questionOption := range question.QuestionOptions {
+		row[8] = questionOption.Body
+		row[11] = questionOption.BodyFormat
+		row[14] = questionOption.Feedback
+		row[15] = questionOption.FeedbackFormat
+		row[9] = ""
+		if questionOption.Correct != nil {
+			row[9] = strconv.FormatBool(*questionOption.Correct)
+		}
+		if err := cw.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+	cw.headerWritten = true
+	return cw.write(csvHeader)
+}
+
+func (cw *csvWriter) write(row []string) error {
+	if err := cw.w.Write(row); err != nil {
+		return err
+	}
+	// Flush every row so that the export is streamed
+	cw.w.Flush()
+	return cw.w.Error()
+}
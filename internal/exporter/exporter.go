@@ -0,0 +1,67 @@
+// Package exporter writes questions one at a time in formats the importer reads back
+package exporter
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/qti"
+	"errors"
+	"io"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatQTI    = "qti" // QTI 2.1 content package zip, with the choice questions only
+)
+
+// FormatVersion is sent with every export, so that imports can reject files they do not understand
+const FormatVersion = "1"
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer writes questions as they are read, without holding the export in memory
+type Writer interface {
+	WriteQuestion(question entity.Question) error
+	// Close finishes the export, it does not close the underlying writer
+	Close() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w}, nil
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatQTI:
+		return qti.NewPackageWriter(w), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the media type of the format
+func ContentType(format string) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv"
+	case FormatQTI:
+		return "application/zip"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileExtension returns the extension of export files in the format
+func FileExtension(format string) string {
+	if format == FormatQTI {
+		return "zip"
+	}
+	return format
+}
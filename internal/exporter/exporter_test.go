@@ -0,0 +1,84 @@
+package exporter_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/exporter"
+	"challenge/internal/importer"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportQuestions() []entity.Question {
+	correct := true
+	incorrect := false
+	numericAnswer := 3.14
+	return []entity.Question{
+		{
+			Id:              1,
+			Body:            "Who wrote Go, \"really\"?",
+			Difficulty:      entity.DifficultyEasy,
+			DurationSeconds: 30,
+			QuestionOptions: []entity.QuestionOption{
+				{Body: "Google", Correct: &correct},
+				{Body: "Oracle, maybe", Correct: &incorrect},
+			},
+			Tags: []entity.QuestionTag{{Name: "go"}, {Name: "history"}},
+		},
+		{
+			Id:               2,
+			Type:             entity.QuestionTypeNumeric,
+			Body:             "pi",
+			NumericAnswer:    &numericAnswer,
+			NumericTolerance: 0.01,
+		},
+	}
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	for _, format := range []string{exporter.FormatJSON, exporter.FormatNDJSON, exporter.FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			questions := newExportQuestions()
+
+			var buf bytes.Buffer
+			writer, err := exporter.NewWriter(format, &buf)
+			require.NoError(t, err)
+			for _, question := range questions {
+				require.NoError(t, writer.WriteQuestion(question))
+			}
+			require.NoError(t, writer.Close())
+
+			items, err := importer.Parse(format, &buf, int64(buf.Len()))
+			require.NoError(t, err)
+			require.Len(t, items, len(questions))
+			for i, item := range items {
+				require.NoError(t, item.Err)
+				// Only JSON formats keep ids, the importer drops them anyway
+				item.Question.Id = questions[i].Id
+				assert.Equal(t, questions[i], item.Question)
+			}
+		})
+	}
+}
+
+func TestWriter_Empty(t *testing.T) {
+	for _, format := range []string{exporter.FormatJSON, exporter.FormatNDJSON, exporter.FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := exporter.NewWriter(format, &buf)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			items, err := importer.Parse(format, &buf, int64(buf.Len()))
+			require.NoError(t, err)
+			assert.Empty(t, items)
+		})
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := exporter.NewWriter("xml", &bytes.Buffer{})
+	assert.ErrorIs(t, err, exporter.ErrUnknownFormat)
+}
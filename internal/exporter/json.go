@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"challenge/internal/entity"
+	"encoding/json"
+	"io"
+)
+
+// jsonWriter writes a JSON array of questions in the shape accepted by POST /questions
+type jsonWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (jw *jsonWriter) WriteQuestion(question entity.Question) error {
+	separator := ","
+	if !jw.written {
+		separator = "["
+		jw.written = true
+	}
+	if _, err := io.WriteString(jw.w, separator); err != nil {
+		return err
+	}
+	return json.NewEncoder(jw.w).Encode(question)
+}
+
+func (jw *jsonWriter) Close() error {
+	if !jw.written {
+		_, err := io.WriteString(jw.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(jw.w, "]\n")
+	return err
+}
+
+// ndjsonWriter writes a question per line
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (nw *ndjsonWriter) WriteQuestion(question entity.Question) error {
+	return json.NewEncoder(nw.w).Encode(question)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
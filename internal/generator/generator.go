@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"challenge/internal/entity"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+var ErrNotEnoughQuestions = errors.New("not enough questions")
+
+// Rule selects Count questions matching every set criteria
+type Rule struct {
+	Tags       []string `json:"tags"`
+	Difficulty string   `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Count      uint     `json:"count" validate:"required,min=1"`
+}
+
+// Generator picks questions from candidate pools, one pool per rule.
+// The same seed and pools always produce the same selection
+type Generator struct {
+	rng *rand.Rand
+	// MaxDurationSeconds is the total time budget, 0 means no budget
+	MaxDurationSeconds uint
+}
+
+func New(seed int64, maxDurationSeconds uint) *Generator {
+	return &Generator{rng: rand.New(rand.NewSource(seed)), MaxDurationSeconds: maxDurationSeconds}
+}
+
+// Generate selects rules[i].Count questions from pools[i] for every rule, without duplicates. Questions in the pools of
+// several rules are moved between them when that lets every rule be filled, so the rule order doesn't matter
+func (g *Generator) Generate(rules []Rule, pools [][]entity.Question) ([]entity.Question, error) {
+	if len(rules) != len(pools) {
+		return nil, errors.New("every rule needs a pool")
+	}
+
+	a := assignment{
+		generator: g,
+		pools:     make([][]entity.Question, len(pools)),
+		ruleOf:    map[uint]int{},
+	}
+	for i := range pools {
+		a.pools[i] = append([]entity.Question{}, pools[i]...)
+		g.rng.Shuffle(len(a.pools[i]), func(x, y int) { a.pools[i][x], a.pools[i][y] = a.pools[i][y], a.pools[i][x] })
+	}
+	for i, rule := range rules {
+		for count := uint(0); count < rule.Count; count++ {
+			if !a.assign(i, map[uint]bool{}) {
+				return nil, fmt.Errorf("%w for rule %d: found %d of %d", ErrNotEnoughQuestions, i, count, rule.Count)
+			}
+		}
+	}
+
+	selected := []entity.Question{}
+	for i, pool := range a.pools {
+		for _, question := range pool {
+			if rule, ok := a.ruleOf[question.Id]; ok && rule == i {
+				selected = append(selected, question)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// assignment is the rule each selected question fills
+type assignment struct {
+	generator       *Generator
+	pools           [][]entity.Question
+	ruleOf          map[uint]int
+	durationSeconds uint
+}
+
+// assign fills one more question of the rule. A question not selected yet is picked when one fits the time budget,
+// otherwise a question selected by another rule is taken when that rule can be filled with another question instead.
+// visited holds the questions already tried for the assignment
+func (a *assignment) assign(rule int, visited map[uint]bool) bool {
+	maxDurationSeconds := a.generator.MaxDurationSeconds
+	for _, question := range a.pools[rule] {
+		if _, selected := a.ruleOf[question.Id]; selected || visited[question.Id] {
+			continue
+		}
+		if maxDurationSeconds != 0 && a.durationSeconds+question.DurationSeconds > maxDurationSeconds {
+			continue
+		}
+		a.ruleOf[question.Id] = rule
+		a.durationSeconds += question.DurationSeconds
+		return true
+	}
+
+	// Moving questions between rules keeps the selection, and so its duration
+	for _, question := range a.pools[rule] {
+		otherRule, selected := a.ruleOf[question.Id]
+		if !selected || otherRule == rule || visited[question.Id] {
+			continue
+		}
+		visited[question.Id] = true
+		if a.assign(otherRule, visited) {
+			a.ruleOf[question.Id] = rule
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the question meets every criterion of the rule, having any of the tags is enough
+func (r Rule) Matches(question entity.Question) bool {
+	if r.Difficulty != "" && question.Difficulty != r.Difficulty {
+		return false
+	}
+	if len(r.Tags) == 0 {
+		return true
+	}
+	for _, tag := range question.Tags {
+		for _, ruleTag := range r.Tags {
+			if tag.Name == ruleTag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package generator_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/generator"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func questionIds(questions []entity.Question) []uint {
+	ids := make([]uint, len(questions))
+	for i, question := range questions {
+		ids[i] = question.Id
+	}
+	return ids
+}
+
+func newPool(fromId uint, toId uint, durationSeconds uint) []entity.Question {
+	pool := []entity.Question{}
+	for id := fromId; id <= toId; id++ {
+		pool = append(pool, entity.Question{Id: id, DurationSeconds: durationSeconds})
+	}
+	return pool
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	type Test struct {
+		TestName           string
+		Rules              []generator.Rule
+		Pools              [][]entity.Question
+		MaxDurationSeconds uint
+		ExpectedCount      int
+		ExpectedErr        error
+	}
+	tests := []Test{
+		{
+			TestName:      "SingleRule",
+			Rules:         []generator.Rule{{Count: 3}},
+			Pools:         [][]entity.Question{newPool(1, 10, 60)},
+			ExpectedCount: 3,
+		},
+		{
+			TestName:      "OverlappingPoolsHaveNoDuplicates",
+			Rules:         []generator.Rule{{Count: 3}, {Count: 2}},
+			Pools:         [][]entity.Question{newPool(1, 5, 60), newPool(1, 5, 60)},
+			ExpectedCount: 5,
+		},
+		{
+			// Picking the first rule questions freely would take questions only the second rule can use
+			TestName:      "OverlappingRules",
+			Rules:         []generator.Rule{{Count: 7}, {Count: 3}},
+			Pools:         [][]entity.Question{newPool(1, 10, 60), newPool(1, 3, 60)},
+			ExpectedCount: 10,
+		},
+		{
+			TestName:           "OverlappingRulesWithTimeBudget",
+			Rules:              []generator.Rule{{Count: 2}, {Count: 1}},
+			Pools:              [][]entity.Question{append(newPool(1, 2, 60), newPool(3, 4, 600)...), newPool(1, 1, 60)},
+			MaxDurationSeconds: 720,
+			ExpectedCount:      3,
+		},
+		{
+			TestName:    "NotEnoughQuestions",
+			Rules:       []generator.Rule{{Count: 3}, {Count: 3}},
+			Pools:       [][]entity.Question{newPool(1, 4, 60), newPool(1, 4, 60)},
+			ExpectedErr: generator.ErrNotEnoughQuestions,
+		},
+		{
+			TestName:           "TimeBudget",
+			Rules:              []generator.Rule{{Count: 2}, {Count: 1}},
+			Pools:              [][]entity.Question{newPool(1, 5, 600), newPool(6, 10, 60)},
+			MaxDurationSeconds: 1260,
+			ExpectedCount:      3,
+		},
+		{
+			TestName:           "TimeBudgetExceeded",
+			Rules:              []generator.Rule{{Count: 3}},
+			Pools:              [][]entity.Question{newPool(1, 5, 600)},
+			MaxDurationSeconds: 1200,
+			ExpectedErr:        generator.ErrNotEnoughQuestions,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questions, err := generator.New(42, test.MaxDurationSeconds).Generate(test.Rules, test.Pools)
+			if test.ExpectedErr != nil {
+				assert.ErrorIs(t, err, test.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			ids := questionIds(questions)
+			assert.Len(t, ids, test.ExpectedCount)
+
+			seen := map[uint]bool{}
+			for _, id := range ids {
+				assert.False(t, seen[id], "question %d selected twice", id)
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateIsReproducible(t *testing.T) {
+	rules := []generator.Rule{{Count: 5}}
+	pools := [][]entity.Question{newPool(1, 100, 60)}
+
+	first, err := generator.New(7, 0).Generate(rules, pools)
+	require.NoError(t, err)
+	second, err := generator.New(7, 0).Generate(rules, pools)
+	require.NoError(t, err)
+	assert.Equal(t, questionIds(first), questionIds(second))
+
+	other, err := generator.New(8, 0).Generate(rules, pools)
+	require.NoError(t, err)
+	assert.NotEqual(t, questionIds(first), questionIds(other))
+}
+
+func TestGenerator_GenerateOverlappingRules(t *testing.T) {
+	// 3 hard questions and 7 go questions, the hard questions are go questions too
+	rules := []generator.Rule{{Tags: []string{"go"}, Count: 7}, {Difficulty: entity.DifficultyHard, Count: 3}}
+	pools := [][]entity.Question{newPool(1, 10, 60), newPool(1, 3, 60)}
+	for seed := int64(0); seed < 20; seed++ {
+		questions, err := generator.New(seed, 0).Generate(rules, pools)
+		require.NoError(t, err)
+		// The questions of every rule follow the rule order
+		ids := questionIds(questions)
+		require.Len(t, ids, 10)
+		assert.ElementsMatch(t, []uint{1, 2, 3}, ids[7:])
+		assert.ElementsMatch(t, []uint{4, 5, 6, 7, 8, 9, 10}, ids[:7])
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	question := entity.Question{Difficulty: entity.DifficultyHard, Tags: []entity.QuestionTag{{Name: "go"}, {Name: "sql"}}}
+	assert.True(t, generator.Rule{}.Matches(question))
+	assert.True(t, generator.Rule{Tags: []string{"python", "sql"}, Difficulty: entity.DifficultyHard}.Matches(question))
+	assert.False(t, generator.Rule{Tags: []string{"python"}}.Matches(question))
+	assert.False(t, generator.Rule{Tags: []string{"go"}, Difficulty: entity.DifficultyEasy}.Matches(question))
+}
@@ -0,0 +1,304 @@
+package grpcserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"challenge/pkg/questionpb"
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPageSize    = 1000
+	exportPageSize = 1000
+)
+
+// questionPreloads loads the options and tags returned with a question
+var questionPreloads = []gormprovider.Option{
+	gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+	gormprovider.PreloadOption("Tags"),
+}
+
+func withQuestionPreloads(opts ...gormprovider.Option) []gormprovider.Option {
+	return append(append([]gormprovider.Option{}, questionPreloads...), opts...)
+}
+
+type QuestionService struct {
+	questionpb.UnimplementedQuestionServiceServer
+	questionRepository repository.QuestionRepository
+	questionWriter     *httpserver.QuestionWriter
+}
+
+// NewQuestionService reads the questions from the repository and changes them through the writer of the REST API
+func NewQuestionService(questionRepository repository.QuestionRepository, questionWriter *httpserver.QuestionWriter) *QuestionService {
+	return &QuestionService{questionRepository: questionRepository, questionWriter: questionWriter}
+}
+
+func (s *QuestionService) ListQuestions(ctx context.Context, req *questionpb.ListQuestionsRequest) (*questionpb.ListQuestionsResponse, error) {
+	if req.PageSize > maxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be at most %d", maxPageSize)
+	}
+	questionFilter, err := newQuestionFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var lastId *uint
+	if req.LastId != nil {
+		id := uint(*req.LastId)
+		lastId = &id
+	}
+
+	questions, err := s.questionRepository.ListQuestions(ctx, uint(req.PageSize), lastId, withQuestionPreloads(questionFilter)...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list questions")
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	res := &questionpb.ListQuestionsResponse{Questions: []*questionpb.Question{}}
+	for _, question := range questions {
+		res.Questions = append(res.Questions, newQuestionMessage(ctx, question))
+	}
+	return res, nil
+}
+
+func (s *QuestionService) GetQuestion(ctx context.Context, req *questionpb.GetQuestionRequest) (*questionpb.Question, error) {
+	question, err := s.questionRepository.GetQuestion(ctx, uint(req.Id), questionPreloads...)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "Not found")
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get question")
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	return newQuestionMessage(ctx, question), nil
+}
+
+func (s *QuestionService) CreateQuestion(ctx context.Context, req *questionpb.CreateQuestionRequest) (*questionpb.Question, error) {
+	authorId := getAuthUserId(ctx)
+	if authorId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "Missing or malformed JWT")
+	}
+	if req.Question == nil {
+		return nil, status.Error(codes.InvalidArgument, "question is required")
+	}
+
+	question := newQuestion(req.Question)
+	err := s.questionWriter.CreateQuestion(ctx, authorId, &question)
+	if err != nil {
+		return nil, newWriteErrorStatus(err)
+	}
+	return newQuestionMessage(ctx, question), nil
+}
+
+func (s *QuestionService) UpdateQuestion(ctx context.Context, req *questionpb.UpdateQuestionRequest) (*questionpb.Question, error) {
+	authorId := getAuthUserId(ctx)
+	if authorId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "Missing or malformed JWT")
+	}
+	if req.Question == nil {
+		return nil, status.Error(codes.InvalidArgument, "question is required")
+	}
+
+	questionUpdate := newQuestion(req.Question)
+	err := s.questionWriter.UpdateQuestion(ctx, uint(req.Id), authorId, &questionUpdate)
+	if err != nil {
+		return nil, newWriteErrorStatus(err)
+	}
+	return newQuestionMessage(ctx, questionUpdate), nil
+}
+
+func (s *QuestionService) DeleteQuestion(ctx context.Context, req *questionpb.DeleteQuestionRequest) (*questionpb.DeleteQuestionResponse, error) {
+	authorId := getAuthUserId(ctx)
+	if authorId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "Missing or malformed JWT")
+	}
+
+	err := s.questionWriter.DeleteQuestion(ctx, uint(req.Id), authorId)
+	if err != nil {
+		return nil, newWriteErrorStatus(err)
+	}
+	return &questionpb.DeleteQuestionResponse{}, nil
+}
+
+// ExportQuestions sends the questions page by page, so the whole library is never held in memory
+func (s *QuestionService) ExportQuestions(req *questionpb.ExportQuestionsRequest, stream questionpb.QuestionService_ExportQuestionsServer) error {
+	questionFilter, err := newQuestionFilter(req.Filter)
+	if err != nil {
+		return err
+	}
+
+	var lastId *uint
+	for {
+		page, err := s.questionRepository.ListQuestions(stream.Context(), exportPageSize, lastId, withQuestionPreloads(questionFilter)...)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list questions")
+			return status.Error(codes.Internal, "Internal error")
+		}
+		for _, question := range page {
+			err = stream.Send(newQuestionMessage(stream.Context(), question))
+			if err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		lastId = &page[len(page)-1].Id
+	}
+}
+
+func newQuestionFilter(filter *questionpb.QuestionFilter) (repository.QuestionFilter, error) {
+	if filter == nil {
+		return repository.QuestionFilter{}, nil
+	}
+	switch filter.Difficulty {
+	case "", entity.DifficultyEasy, entity.DifficultyMedium, entity.DifficultyHard:
+	default:
+		return repository.QuestionFilter{}, status.Error(codes.InvalidArgument, "difficulty must be one of easy medium hard")
+	}
+
+	questionFilter := repository.QuestionFilter{Difficulty: filter.Difficulty, Tags: filter.Tags}
+	if filter.AuthorId != nil {
+		questionFilter.AuthorId = uint(*filter.AuthorId)
+	}
+	return questionFilter, nil
+}
+
+// newWriteErrorStatus turns the status of a failed change of the question writer into a gRPC status
+func newWriteErrorStatus(err error) error {
+	var writeErr httpserver.QuestionWriteError
+	if !errors.As(err, &writeErr) {
+		return status.Error(codes.Internal, "Internal error")
+	}
+
+	code := codes.Internal
+	switch writeErr.Status {
+	case fiber.StatusBadRequest, fiber.StatusUnprocessableEntity:
+		code = codes.InvalidArgument
+	case fiber.StatusUnauthorized:
+		code = codes.PermissionDenied
+	case fiber.StatusNotFound:
+		code = codes.NotFound
+	}
+	st := status.New(code, writeErr.Error())
+
+	// Validation errors are sent as the field violations of the status
+	validationErrRes, ok := writeErr.Response.(httpserver.ValidationErrorResponse)
+	if !ok {
+		return st.Err()
+	}
+	fields := make([]string, 0, len(validationErrRes.Errors))
+	for field := range validationErrRes.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		for _, fieldErr := range validationErrRes.Errors[field] {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: field, Description: fieldErr})
+		}
+	}
+	stWithDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+	return stWithDetails.Err()
+}
+
+// newQuestion returns the question of the message as the REST handlers parse it, before validating it
+func newQuestion(message *questionpb.Question) entity.Question {
+	question := entity.Question{
+		Type:              message.Type,
+		Body:              message.Body,
+		BodyFormat:        message.BodyFormat,
+		Explanation:       message.Explanation,
+		ExplanationFormat: message.ExplanationFormat,
+		Language:          message.Language,
+		Difficulty:        message.Difficulty,
+		DurationSeconds:   uint(message.DurationSeconds),
+		NumericAnswer:     message.NumericAnswer,
+		NumericTolerance:  message.NumericTolerance,
+		AttachmentId:      newOptionalId(message.AttachmentId),
+	}
+	// Repeated fields can't tell empty from missing, questions without options are sent without any
+	for _, optionMessage := range message.Options {
+		question.QuestionOptions = append(question.QuestionOptions, entity.QuestionOption{
+			Id:             uint(optionMessage.Id),
+			Body:           optionMessage.Body,
+			BodyFormat:     optionMessage.BodyFormat,
+			Feedback:       optionMessage.Feedback,
+			FeedbackFormat: optionMessage.FeedbackFormat,
+			Correct:        optionMessage.Correct,
+			AttachmentId:   newOptionalId(optionMessage.AttachmentId),
+		})
+	}
+	for _, tag := range message.Tags {
+		question.Tags = append(question.Tags, entity.QuestionTag{Name: tag})
+	}
+	return question
+}
+
+// newQuestionMessage converts the question, without the explanation and feedback the caller can't review
+func newQuestionMessage(ctx context.Context, question entity.Question) *questionpb.Question {
+	httpserver.HideExplanations(&question, getAuthUserId(ctx), getAuthUserRole(ctx))
+	message := &questionpb.Question{
+		Id:                uint64(question.Id),
+		Type:              question.GetType(),
+		Body:              question.Body,
+		BodyFormat:        question.GetBodyFormat(),
+		Explanation:       question.Explanation,
+		ExplanationFormat: question.GetExplanationFormat(),
+		Language:          question.GetLanguage(),
+		Difficulty:        question.Difficulty,
+		DurationSeconds:   uint32(question.DurationSeconds),
+		NumericAnswer:     question.NumericAnswer,
+		NumericTolerance:  question.NumericTolerance,
+		Options:           []*questionpb.QuestionOption{},
+		Tags:              []string{},
+		AttachmentId:      newOptionalMessageId(question.AttachmentId),
+		AuthorId:          uint64(question.AuthorId),
+		Revision:          uint64(question.Revision),
+	}
+	for _, questionOption := range question.QuestionOptions {
+		message.Options = append(message.Options, &questionpb.QuestionOption{
+			Id:             uint64(questionOption.Id),
+			Body:           questionOption.Body,
+			BodyFormat:     questionOption.GetBodyFormat(),
+			Feedback:       questionOption.Feedback,
+			FeedbackFormat: questionOption.GetFeedbackFormat(),
+			Correct:        questionOption.Correct,
+			AttachmentId:   newOptionalMessageId(questionOption.AttachmentId),
+		})
+	}
+	for _, tag := range question.Tags {
+		message.Tags = append(message.Tags, tag.Name)
+	}
+	return message
+}
+
+func newOptionalId(id *uint64) *uint {
+	if id == nil {
+		return nil
+	}
+	value := uint(*id)
+	return &value
+}
+
+func newOptionalMessageId(id *uint) *uint64 {
+	if id == nil {
+		return nil
+	}
+	value := uint64(*id)
+	return &value
+}
@@ -0,0 +1,304 @@
+package grpcserver_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/eventbus"
+	"challenge/internal/grpcserver"
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/mocks"
+	"challenge/pkg/questionpb"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+// newClient serves the question service in memory and connects to it
+func newClient(t *testing.T, questionRepository repository.QuestionRepository, questionWriter *httpserver.QuestionWriter) questionpb.QuestionServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcserver.NewServer(questionRepository, questionWriter)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return questionpb.NewQuestionServiceClient(conn)
+}
+
+// withAuth adds the JWT of the user to the metadata of the calls made with the context
+func withAuth(t *testing.T, ctx context.Context, userId uint) context.Context {
+	claims := jwt.MapClaims{"user_id": strconv.FormatUint(uint64(userId), 10)}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+func TestGetQuestion(t *testing.T) {
+	correct := true
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything, mock.Anything).
+		Return(entity.Question{
+			Id:              1,
+			Body:            "Which?",
+			Explanation:     "because",
+			QuestionOptions: []entity.QuestionOption{{Id: 2, Body: "this", Feedback: "right", Correct: &correct}},
+			Tags:            []entity.QuestionTag{{Name: "go"}},
+			AuthorId:        3,
+		}, nil)
+	questionRepository.On("GetQuestion", mock.Anything, uint(2), mock.Anything, mock.Anything).Return(entity.Question{}, gorm.ErrRecordNotFound)
+	client := newClient(t, questionRepository, nil)
+
+	question, err := client.GetQuestion(context.Background(), &questionpb.GetQuestionRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), question.Id)
+	assert.Equal(t, entity.QuestionTypeSingleChoice, question.Type)
+	assert.Equal(t, uint64(3), question.AuthorId)
+	assert.Equal(t, []string{"go"}, question.Tags)
+	require.Len(t, question.Options, 1)
+	assert.Equal(t, "this", question.Options[0].Body)
+	assert.True(t, question.Options[0].GetCorrect())
+	// Only the author and reviewers read the explanation and feedback
+	assert.Empty(t, question.Explanation)
+	assert.Empty(t, question.Options[0].Feedback)
+
+	question, err = client.GetQuestion(withAuth(t, context.Background(), 3), &questionpb.GetQuestionRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "because", question.Explanation)
+	assert.Equal(t, "right", question.Options[0].Feedback)
+
+	_, err = client.GetQuestion(context.Background(), &questionpb.GetQuestionRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListQuestions(t *testing.T) {
+	lastId := uint(1)
+	requestLastId := uint64(1)
+	authorId := uint64(2)
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(10), &lastId, mock.Anything, mock.Anything, repository.QuestionFilter{AuthorId: 2, Tags: []string{"go"}}).
+		Return([]entity.Question{{Id: 2, AuthorId: 2}, {Id: 3, AuthorId: 2}}, nil)
+	client := newClient(t, questionRepository, nil)
+
+	res, err := client.ListQuestions(context.Background(), &questionpb.ListQuestionsRequest{
+		PageSize: 10,
+		LastId:   &requestLastId,
+		Filter:   &questionpb.QuestionFilter{AuthorId: &authorId, Tags: []string{"go"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Questions, 2)
+	assert.Equal(t, uint64(2), res.Questions[0].Id)
+	assert.Equal(t, uint64(3), res.Questions[1].Id)
+
+	_, err = client.ListQuestions(context.Background(), &questionpb.ListQuestionsRequest{PageSize: 1001})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ListQuestions(context.Background(), &questionpb.ListQuestionsRequest{Filter: &questionpb.QuestionFilter{Difficulty: "impossible"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestExportQuestions(t *testing.T) {
+	firstPage := make([]entity.Question, 1000)
+	for i := range firstPage {
+		firstPage[i] = entity.Question{Id: uint(i + 1)}
+	}
+	lastId := uint(1000)
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(1000), (*uint)(nil), mock.Anything, mock.Anything, repository.QuestionFilter{Difficulty: "easy"}).
+		Return(firstPage, nil).
+		Once()
+	questionRepository.On("ListQuestions", mock.Anything, uint(1000), &lastId, mock.Anything, mock.Anything, repository.QuestionFilter{Difficulty: "easy"}).
+		Return([]entity.Question{{Id: 1001}}, nil).
+		Once()
+	client := newClient(t, questionRepository, nil)
+
+	stream, err := client.ExportQuestions(context.Background(), &questionpb.ExportQuestionsRequest{Filter: &questionpb.QuestionFilter{Difficulty: "easy"}})
+	require.NoError(t, err)
+	var ids []uint64
+	for {
+		question, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, question.Id)
+	}
+	require.Len(t, ids, 1001)
+	assert.Equal(t, uint64(1), ids[0])
+	assert.Equal(t, uint64(1001), ids[1000])
+}
+
+func TestQuestionServiceChanges(t *testing.T) {
+	correct := true
+	incorrect := false
+	question := &questionpb.Question{
+		Body:    "Which?",
+		Options: []*questionpb.QuestionOption{{Body: "this", Correct: &correct}, {Body: "that", Correct: &incorrect}},
+		Tags:    []string{"go"},
+	}
+	type Test struct {
+		TestName           string
+		UserId             uint
+		Call               func(ctx context.Context, client questionpb.QuestionServiceClient) error
+		ExpectedCode       codes.Code
+		ExpectedViolations []string
+		SetupMocks         func(questionRepository *mocks.QuestionRepository, auditEntryRepository *mocks.AuditEntryRepository)
+	}
+	tests := []Test{
+		{
+			TestName: "CreateAnonymous",
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				_, err := client.CreateQuestion(ctx, &questionpb.CreateQuestionRequest{Question: question})
+				return err
+			},
+			ExpectedCode: codes.Unauthenticated,
+		},
+		{
+			TestName: "CreateInvalidToken",
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer invalid")
+				_, err := client.CreateQuestion(ctx, &questionpb.CreateQuestionRequest{Question: question})
+				return err
+			},
+			ExpectedCode: codes.Unauthenticated,
+		},
+		{
+			TestName: "CreateInvalid",
+			UserId:   1,
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				_, err := client.CreateQuestion(ctx, &questionpb.CreateQuestionRequest{Question: &questionpb.Question{Difficulty: "impossible"}})
+				return err
+			},
+			ExpectedCode:       codes.InvalidArgument,
+			ExpectedViolations: []string{"Question.Body", "Question.Difficulty", "Question.QuestionOptions"},
+		},
+		{
+			TestName: "Create",
+			UserId:   1,
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				res, err := client.CreateQuestion(ctx, &questionpb.CreateQuestionRequest{Question: question})
+				if err != nil {
+					return err
+				}
+				if res.Id != 1 || res.AuthorId != 1 || len(res.Options) != 2 {
+					return fmt.Errorf("unexpected question %v", res)
+				}
+				return nil
+			},
+			ExpectedCode: codes.OK,
+			SetupMocks: func(questionRepository *mocks.QuestionRepository, auditEntryRepository *mocks.AuditEntryRepository) {
+				questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, question *entity.Question) error {
+						question.Id = 1
+						return nil
+					})
+				// The audit entry records the user of the JWT
+				auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(auditEntry *entity.AuditEntry) bool {
+					return auditEntry.ActorId == 1 && auditEntry.Action == entity.AuditActionCreate
+				})).Return(nil)
+			},
+		},
+		{
+			TestName: "UpdateNotAuthor",
+			UserId:   2,
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				_, err := client.UpdateQuestion(ctx, &questionpb.UpdateQuestionRequest{Id: 1, Question: question})
+				return err
+			},
+			ExpectedCode: codes.PermissionDenied,
+			SetupMocks: func(questionRepository *mocks.QuestionRepository, _ *mocks.AuditEntryRepository) {
+				questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything).Return(entity.Question{Id: 1, AuthorId: 1}, nil)
+			},
+		},
+		{
+			TestName: "DeleteAnonymous",
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				_, err := client.DeleteQuestion(ctx, &questionpb.DeleteQuestionRequest{Id: 1})
+				return err
+			},
+			ExpectedCode: codes.Unauthenticated,
+		},
+		{
+			TestName: "DeleteMissing",
+			UserId:   1,
+			Call: func(ctx context.Context, client questionpb.QuestionServiceClient) error {
+				_, err := client.DeleteQuestion(ctx, &questionpb.DeleteQuestionRequest{Id: 1})
+				return err
+			},
+			ExpectedCode: codes.OK,
+			SetupMocks: func(questionRepository *mocks.QuestionRepository, _ *mocks.AuditEntryRepository) {
+				questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything, mock.Anything).Return(entity.Question{}, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			outboxRepository := mocks.NewOutboxRepository(t)
+			auditEntryRepository := mocks.NewAuditEntryRepository(t)
+			if test.SetupMocks != nil {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					}).
+					Maybe()
+				questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+				questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+				outboxRepository.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+				test.SetupMocks(questionRepository, auditEntryRepository)
+			}
+
+			client := newClient(t, questionRepository, httpserver.NewQuestionWriter(
+				questionRepository,
+				questionOptionRepository,
+				questionTagRepository,
+				nil,
+				nil,
+				outboxRepository,
+				auditEntryRepository,
+				eventbus.NewBus(10),
+			))
+			ctx := context.Background()
+			if test.UserId != 0 {
+				ctx = withAuth(t, ctx, test.UserId)
+			}
+			err := test.Call(ctx, client)
+			require.Equal(t, test.ExpectedCode, status.Code(err), err)
+			if test.ExpectedViolations == nil {
+				return
+			}
+
+			var fields []string
+			for _, detail := range status.Convert(err).Details() {
+				for _, violation := range detail.(*errdetails.BadRequest).FieldViolations {
+					fields = append(fields, violation.Field)
+				}
+			}
+			assert.Equal(t, test.ExpectedViolations, fields)
+		})
+	}
+}
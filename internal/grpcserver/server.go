@@ -0,0 +1,129 @@
+// Package grpcserver serves the question library over gRPC, next to the REST API of httpserver and
+// with the same JWT auth, checks and side effects
+package grpcserver
+
+import (
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/pkg/env"
+	"challenge/pkg/questionpb"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIdMetadataKey carries the id of the request like the X-Request-ID header of the REST API
+const requestIdMetadataKey = "x-request-id"
+
+// Port reads the port of the gRPC server, 50051 by default
+func Port() string {
+	return env.GetOrDefault("GRPC_PORT", "50051")
+}
+
+// NewServer serves the QuestionService, verifying the JWT of the calls that have one
+func NewServer(questionRepository repository.QuestionRepository, questionWriter *httpserver.QuestionWriter) *grpc.Server {
+	auth := &jwtAuth{signingKey: []byte(env.GetOrDefault("JWT_SIGNING_KEY", "secret"))}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.unaryInterceptor),
+		grpc.StreamInterceptor(auth.streamInterceptor),
+	)
+	questionpb.RegisterQuestionServiceServer(server, NewQuestionService(questionRepository, questionWriter))
+
+	return server
+}
+
+type authUserIdContextKey struct{}
+
+type authUserRoleContextKey struct{}
+
+// getAuthUserId returns the id of the user of the verified JWT of the call, 0 for anonymous calls
+func getAuthUserId(ctx context.Context) uint {
+	userId, _ := ctx.Value(authUserIdContextKey{}).(uint)
+	return userId
+}
+
+// getAuthUserRole returns the role of the user of the verified JWT of the call, empty for anonymous calls
+func getAuthUserRole(ctx context.Context) string {
+	role, _ := ctx.Value(authUserRoleContextKey{}).(string)
+	return role
+}
+
+// jwtAuth verifies the "Bearer <token>" authorization metadata like the JWT middleware of the REST API
+type jwtAuth struct {
+	signingKey []byte
+}
+
+func (a *jwtAuth) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *jwtAuth) streamInterceptor(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, authServerStream{stream, ctx})
+}
+
+// authenticate adds the user of the JWT of the call and its audit context to the context of the call
+func (a *jwtAuth) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var requestId string
+	if values := md.Get(requestIdMetadataKey); len(values) > 0 {
+		requestId = values[0]
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return httpserver.WithAuditContext(ctx, 0, requestId, ip), nil
+	}
+	if !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "Missing or malformed JWT")
+	}
+	token, err := jwt.Parse(strings.TrimPrefix(values[0], "Bearer "), func(token *jwt.Token) (any, error) {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", token.Header["alg"])
+		}
+		return a.signingKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, status.Error(codes.Unauthenticated, "Invalid or expired JWT")
+	}
+
+	userIdString, _ := token.Claims.(jwt.MapClaims)["user_id"].(string)
+	userId, err := strconv.ParseUint(userIdString, 10, 64)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid JWT claims")
+	}
+	role, _ := token.Claims.(jwt.MapClaims)["role"].(string)
+	ctx = context.WithValue(ctx, authUserIdContextKey{}, uint(userId))
+	ctx = context.WithValue(ctx, authUserRoleContextKey{}, role)
+	return httpserver.WithAuditContext(ctx, uint(userId), requestId, ip), nil
+}
+
+// authServerStream is a stream with the context of its verified JWT
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authServerStream) Context() context.Context {
+	return s.ctx
+}
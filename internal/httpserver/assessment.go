@@ -0,0 +1,433 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/generator"
+	"challenge/internal/outbox"
+	"challenge/internal/repository"
+	"challenge/internal/webhook"
+	"challenge/pkg/gormprovider"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var assessmentQuestionsPreload = gormprovider.OrderedPreloadOption{Association: "AssessmentQuestions", Order: "position"}
+
+type AssessmentServer struct {
+	questionRepository           repository.QuestionRepository
+	assessmentRepository         repository.AssessmentRepository
+	assessmentQuestionRepository repository.AssessmentQuestionRepository
+	attemptRepository            repository.AttemptRepository
+	outboxRepository             repository.OutboxRepository
+}
+
+func NewAssessmentServer(
+	questionRepository repository.QuestionRepository,
+	assessmentRepository repository.AssessmentRepository,
+	assessmentQuestionRepository repository.AssessmentQuestionRepository,
+	attemptRepository repository.AttemptRepository,
+	outboxRepository repository.OutboxRepository,
+) *fiber.App {
+	server := &AssessmentServer{
+		questionRepository,
+		assessmentRepository,
+		assessmentQuestionRepository,
+		attemptRepository,
+		outboxRepository,
+	}
+	app := fiber.New()
+	app.Use(newJwtAuth())
+	app.Get("/", server.ListAssessments)
+	app.Post("/", server.CreateAssessment)
+	app.Post("/generate", server.GenerateAssessment)
+	app.Get("/:id", server.GetAssessment)
+	app.Put("/:id", server.UpdateAssessment)
+	app.Delete("/:id", server.DeleteAssessment)
+
+	return app
+}
+
+type ListAssessmentsRequest struct {
+	LastId   *uint `json:"lastId"`
+	PageSize uint  `json:"pageSize" validate:"max=1000"`
+}
+
+func (s AssessmentServer) ListAssessments(c *fiber.Ctx) error {
+	var req ListAssessmentsRequest
+
+	// Validate and parse request
+	if c.Request().Header.ContentLength() > 0 {
+		errRes, valid := validateRequest(c, &req)
+		if !valid {
+			return c.Status(fiber.StatusBadRequest).JSON(errRes)
+		}
+	}
+
+	// Get authenticated user id - admins see every assessment
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+	assessmentFilter := repository.AssessmentFilter{AuthorId: authorId}
+	if getAuthUserRole(c) == RoleAdmin {
+		assessmentFilter.AuthorId = 0
+	}
+
+	assessments, err := s.assessmentRepository.ListAssessments(
+		c.UserContext(),
+		req.PageSize,
+		req.LastId,
+		assessmentQuestionsPreload,
+		assessmentFilter,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list assessments")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(assessments)
+}
+
+func (s AssessmentServer) CreateAssessment(c *fiber.Ctx) error {
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Validate and parse request
+	var assessment entity.Assessment
+	errRes, valid := validateRequest(c, &assessment)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	assessment.AuthorId = authorId
+
+	// Snapshot referenced questions
+	errStatus, errRes := s.snapshotAssessmentQuestions(c.UserContext(), nil, assessment.AssessmentQuestions)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err = s.assessmentRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		// Create assessment
+		err = s.assessmentRepository.CreateAssessment(txCtx, &assessment)
+		if err != nil {
+			return err
+		}
+
+		// Create assessment questions
+		err = s.assessmentQuestionRepository.BulkCreateAssessmentQuestions(txCtx, assessment.Id, assessment.AssessmentQuestions)
+		if err != nil {
+			return err
+		}
+
+		return s.emitQuestionsPublished(txCtx, nil, assessment)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(assessment)
+}
+
+type GenerateAssessmentRequest struct {
+	Title                string           `json:"title" validate:"required"`
+	TimeLimitSeconds     uint             `json:"timeLimitSeconds"`
+	PassingScore         float64          `json:"passingScore" validate:"min=0,max=100"`
+	Seed                 *int64           `json:"seed"` // Random when not set
+	MaxDurationSeconds   uint             `json:"maxDurationSeconds"`
+	ExcludeQuestionIds   []uint           `json:"excludeQuestionIds"`
+	ExcludeAssessmentIds []uint           `json:"excludeAssessmentIds"`
+	Rules                []generator.Rule `json:"rules" validate:"required,dive"`
+}
+
+type GenerateAssessmentResponse struct {
+	Seed       int64             `json:"seed"`
+	Assessment entity.Assessment `json:"assessment"`
+}
+
+// GenerateAssessment previews an assessment with questions picked by the request rules.
+// Nothing is stored, the preview is saved with CreateAssessment
+func (s AssessmentServer) GenerateAssessment(c *fiber.Ctx) error {
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Validate and parse request
+	var req GenerateAssessmentRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	// Exclude questions used by previous assessments
+	excludeIds := append([]uint{}, req.ExcludeQuestionIds...)
+	for _, assessmentId := range req.ExcludeAssessmentIds {
+		assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), assessmentId, assessmentQuestionsPreload)
+		if err != nil || (assessment.AuthorId != authorId && getAuthUserRole(c) != RoleAdmin) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: fmt.Sprintf("assessment %d not found", assessmentId)})
+		}
+		for _, assessmentQuestion := range assessment.AssessmentQuestions {
+			excludeIds = append(excludeIds, assessmentQuestion.QuestionId)
+		}
+	}
+
+	// Get the candidate questions of all rules at once, then the ones of every rule
+	candidates, err := listAllQuestions(c.UserContext(), s.questionRepository, withQuestionPreloads(newRulesFilter(req.Rules, excludeIds))...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	pools := make([][]entity.Question, len(req.Rules))
+	for i, rule := range req.Rules {
+		pools[i] = []entity.Question{}
+		for _, question := range candidates {
+			if rule.Matches(question) {
+				pools[i] = append(pools[i], question)
+			}
+		}
+	}
+
+	questions, err := generator.New(seed, req.MaxDurationSeconds).Generate(req.Rules, pools)
+	if err != nil {
+		if errors.Is(err, generator.ErrNotEnoughQuestions) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(ErrorResponse{Error: err.Error()})
+		}
+		log.Error().Err(err).Msg("Failed to generate assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	assessment := entity.Assessment{
+		Title:               req.Title,
+		TimeLimitSeconds:    req.TimeLimitSeconds,
+		PassingScore:        req.PassingScore,
+		AssessmentQuestions: make([]entity.AssessmentQuestion, len(questions)),
+		AuthorId:            authorId,
+	}
+	for i, question := range questions {
+		assessment.AssessmentQuestions[i] = entity.AssessmentQuestion{
+			Position:         uint(i),
+			QuestionId:       question.Id,
+			QuestionRevision: question.Revision,
+			Snapshot:         entity.QuestionSnapshot(question),
+		}
+	}
+
+	return c.JSON(GenerateAssessmentResponse{Seed: seed, Assessment: assessment})
+}
+
+// newRulesFilter returns a filter matching the questions of any of the rules: the difficulty shared by every rule and
+// the tags of all rules, unless a rule has no tags
+func newRulesFilter(rules []generator.Rule, excludeIds []uint) repository.QuestionFilter {
+	questionFilter := repository.QuestionFilter{ExcludeIds: excludeIds}
+	if len(rules) == 0 {
+		return questionFilter
+	}
+
+	questionFilter.Difficulty = rules[0].Difficulty
+	tags := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Difficulty != questionFilter.Difficulty {
+			questionFilter.Difficulty = ""
+		}
+		if len(rule.Tags) == 0 {
+			tags = nil
+		}
+		for _, tag := range rule.Tags {
+			if tags != nil && !tags[tag] {
+				tags[tag] = true
+				questionFilter.Tags = append(questionFilter.Tags, tag)
+			}
+		}
+	}
+	if tags == nil {
+		questionFilter.Tags = nil
+	}
+	return questionFilter
+}
+
+func (s AssessmentServer) GetAssessment(c *fiber.Ctx) error {
+	render, errRes := parseRender(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	assessment, errStatus, errRes := s.getOwnAssessment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	if render {
+		for i := range assessment.AssessmentQuestions {
+			question := entity.Question(assessment.AssessmentQuestions[i].Snapshot)
+			if err := renderQuestionHTML(&question); err != nil {
+				log.Error().Err(err).Msg("Failed to render assessment questions")
+				return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+			}
+			assessment.AssessmentQuestions[i].Snapshot = entity.QuestionSnapshot(question)
+		}
+	}
+
+	return c.JSON(assessment)
+}
+
+func (s AssessmentServer) UpdateAssessment(c *fiber.Ctx) error {
+	assessment, errStatus, errRes := s.getOwnAssessment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	// Validate and parse request
+	var assessmentUpdate entity.Assessment
+	errRes, valid := validateRequest(c, &assessmentUpdate)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	assessmentUpdate.Id = assessment.Id
+
+	// Assessments are frozen once candidates started taking them
+	attemptCount, err := s.attemptRepository.CountAssessmentAttempts(c.UserContext(), assessment.Id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to count assessment attempts")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if attemptCount > 0 {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Assessment already has attempts"})
+	}
+
+	// Keep snapshots of questions already in the assessment, snapshot new ones
+	errStatus, errRes = s.snapshotAssessmentQuestions(c.UserContext(), assessment.AssessmentQuestions, assessmentUpdate.AssessmentQuestions)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err = s.assessmentRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		// Update assessment
+		err := s.assessmentRepository.UpdateAssessment(txCtx, assessment.Id, &assessmentUpdate)
+		if err != nil {
+			return err
+		}
+
+		err = s.assessmentQuestionRepository.BulkReplaceAssessmentQuestions(txCtx, assessment.Id, assessmentUpdate.AssessmentQuestions)
+		if err != nil {
+			return err
+		}
+
+		return s.emitQuestionsPublished(txCtx, assessment.AssessmentQuestions, assessmentUpdate)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(assessmentUpdate)
+}
+
+func (s AssessmentServer) DeleteAssessment(c *fiber.Ctx) error {
+	assessment, errStatus, errRes := s.getOwnAssessment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.assessmentRepository.DeleteAssessment(c.UserContext(), assessment.Id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return nil
+}
+
+// getOwnAssessment loads the assessment in the url and checks that the auth user is its author or an admin
+func (s AssessmentServer) getOwnAssessment(c *fiber.Ctx) (entity.Assessment, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Assessment{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Assessment{}, fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), uint(id), assessmentQuestionsPreload)
+	if err != nil {
+		return entity.Assessment{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	if assessment.AuthorId != userId && getAuthUserRole(c) != RoleAdmin {
+		return entity.Assessment{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return assessment, 0, nil
+}
+
+// snapshotAssessmentQuestions freezes the current revision of every referenced question.
+// Questions found in existing keep their snapshot, so editing a question doesn't change a live assessment
+func (s AssessmentServer) snapshotAssessmentQuestions(ctx context.Context, existing []entity.AssessmentQuestion, assessmentQuestions []entity.AssessmentQuestion) (int, any) {
+	existingByQuestionId := map[uint]entity.AssessmentQuestion{}
+	for _, assessmentQuestion := range existing {
+		existingByQuestionId[assessmentQuestion.QuestionId] = assessmentQuestion
+	}
+
+	seen := map[uint]bool{}
+	for _, assessmentQuestion := range assessmentQuestions {
+		if seen[assessmentQuestion.QuestionId] {
+			return fiber.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("question %d is duplicated", assessmentQuestion.QuestionId)}
+		}
+		seen[assessmentQuestion.QuestionId] = true
+	}
+
+	for i := range assessmentQuestions {
+		assessmentQuestion := &assessmentQuestions[i]
+		if existingAssessmentQuestion, found := existingByQuestionId[assessmentQuestion.QuestionId]; found {
+			assessmentQuestion.QuestionRevision = existingAssessmentQuestion.QuestionRevision
+			assessmentQuestion.Snapshot = existingAssessmentQuestion.Snapshot
+			continue
+		}
+
+		question, err := s.questionRepository.GetQuestion(ctx, assessmentQuestion.QuestionId, questionPreloads...)
+		if err != nil {
+			return fiber.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("question %d not found", assessmentQuestion.QuestionId)}
+		}
+		assessmentQuestion.QuestionRevision = question.Revision
+		assessmentQuestion.Snapshot = entity.QuestionSnapshot(question)
+	}
+
+	return 0, nil
+}
+
+// emitQuestionsPublished emits the publication of the questions snapshotted by the assessment, skipping the ones
+// already in the existing assessment questions
+func (s AssessmentServer) emitQuestionsPublished(ctx context.Context, existing []entity.AssessmentQuestion, assessment entity.Assessment) error {
+	existingQuestionIds := map[uint]bool{}
+	for _, assessmentQuestion := range existing {
+		existingQuestionIds[assessmentQuestion.QuestionId] = true
+	}
+	for _, assessmentQuestion := range assessment.AssessmentQuestions {
+		if existingQuestionIds[assessmentQuestion.QuestionId] {
+			continue
+		}
+		err := outbox.Emit(ctx, s.outboxRepository, entity.WebhookEventQuestionPublished, webhook.QuestionPublished{
+			AssessmentId: assessment.Id,
+			Question:     entity.Question(assessmentQuestion.Snapshot),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/generator"
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAssessment(t *testing.T) {
+	var authorId uint = 1
+	var assessmentId uint = 1
+	correct := true
+	question := entity.Question{
+		Id:              1,
+		Body:            "question",
+		QuestionOptions: []entity.QuestionOption{{Body: "option", Correct: &correct}},
+		Revision:        3,
+	}
+
+	type Test struct {
+		TestName               string
+		Req                    map[string]any
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName: "Success",
+			Req: map[string]any{
+				"title":     "assessment",
+				"questions": []map[string]any{{"questionId": question.Id, "points": 2}},
+			},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName: "DuplicatedQuestion",
+			Req: map[string]any{
+				"title":     "assessment",
+				"questions": []map[string]any{{"questionId": question.Id}, {"questionId": question.Id}},
+			},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "MissingQuestions",
+			Req:                    map[string]any{"title": "assessment"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			assessmentRepository := mocks.NewAssessmentRepository(t)
+			assessmentQuestionRepository := mocks.NewAssessmentQuestionRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionRepository.On("GetQuestion", mock.Anything, question.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).
+					Return(question, nil)
+				assessmentRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				assessmentRepository.On("CreateAssessment", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, assessment *entity.Assessment) error {
+						assert.Equal(t, authorId, assessment.AuthorId)
+						assessment.Id = assessmentId
+						return nil
+					})
+				assessmentQuestionRepository.On("BulkCreateAssessmentQuestions", mock.Anything, assessmentId, mock.Anything).
+					Return(func(_ context.Context, _ uint, assessmentQuestions []entity.AssessmentQuestion) error {
+						require.Len(t, assessmentQuestions, 1)
+						assert.Equal(t, question.Revision, assessmentQuestions[0].QuestionRevision)
+						assert.Equal(t, entity.QuestionSnapshot(question), assessmentQuestions[0].Snapshot)
+						assert.Equal(t, 2.0, assessmentQuestions[0].GetPoints())
+						return nil
+					})
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:           questionRepository,
+				Assessment:         assessmentRepository,
+				AssessmentQuestion: assessmentQuestionRepository,
+				Outbox:             newOutboxRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/assessments", bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestUpdateAssessment(t *testing.T) {
+	var authorId uint = 1
+	var assessmentId uint = 1
+	existingQuestion := entity.Question{Id: 1, Body: "question at revision 1", Revision: 1}
+	newQuestion := entity.Question{Id: 2, Body: "new question", Revision: 4}
+	assessment := entity.Assessment{
+		Id:    assessmentId,
+		Title: "assessment",
+		AssessmentQuestions: []entity.AssessmentQuestion{
+			{QuestionId: existingQuestion.Id, QuestionRevision: 1, Snapshot: entity.QuestionSnapshot(existingQuestion)},
+		},
+		AuthorId: authorId,
+	}
+
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, newQuestion.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).
+		Return(newQuestion, nil)
+
+	assessmentRepository := mocks.NewAssessmentRepository(t)
+	assessmentRepository.On("GetAssessment", mock.Anything, assessmentId, mock.Anything).Return(assessment, nil)
+	assessmentRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	assessmentRepository.On("UpdateAssessment", mock.Anything, assessmentId, mock.Anything).Return(nil)
+
+	assessmentQuestionRepository := mocks.NewAssessmentQuestionRepository(t)
+	assessmentQuestionRepository.On("BulkReplaceAssessmentQuestions", mock.Anything, assessmentId, mock.Anything).Return(nil)
+
+	attemptRepository := mocks.NewAttemptRepository(t)
+	attemptRepository.On("CountAssessmentAttempts", mock.Anything, assessmentId).Return(int64(0), nil)
+
+	reqBodyBytes, err := json.Marshal(map[string]any{
+		"title": "updated assessment",
+		"questions": []map[string]any{
+			{"questionId": newQuestion.Id},
+			{"questionId": existingQuestion.Id},
+		},
+	})
+	require.NoError(t, err)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:           questionRepository,
+		Assessment:         assessmentRepository,
+		AssessmentQuestion: assessmentQuestionRepository,
+		Attempt:            attemptRepository,
+		Outbox:             newOutboxRepository(t),
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/assessments/%d", assessmentId), bytes.NewReader(reqBodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBodyBytes, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	var assessmentUpdate entity.Assessment
+	require.NoError(t, json.Unmarshal(resBodyBytes, &assessmentUpdate))
+	require.Len(t, assessmentUpdate.AssessmentQuestions, 2)
+	assert.Equal(t, newQuestion.Revision, assessmentUpdate.AssessmentQuestions[0].QuestionRevision)
+	assert.Equal(t, existingQuestion.Body, assessmentUpdate.AssessmentQuestions[1].Snapshot.Body)
+}
+
+func TestGenerateAssessment(t *testing.T) {
+	var authorId uint = 1
+	var seed int64 = 42
+	tags := []entity.QuestionTag{{Name: "go"}}
+	pool := []entity.Question{
+		{Id: 1, Body: "first", Difficulty: entity.DifficultyHard, Tags: tags},
+		{Id: 2, Body: "second", Difficulty: entity.DifficultyHard, Tags: tags},
+		{Id: 3, Body: "third", Difficulty: entity.DifficultyHard, Tags: tags},
+	}
+	previousAssessment := entity.Assessment{
+		Id:                  1,
+		AssessmentQuestions: []entity.AssessmentQuestion{{QuestionId: 4}},
+		AuthorId:            authorId,
+	}
+
+	type Test struct {
+		TestName               string
+		Count                  uint
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Success",
+			Count:                  2,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "NotEnoughQuestions",
+			Count:                  4,
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.
+				On(
+					"ListQuestions",
+					mock.Anything,
+					uint(1000),
+					(*uint)(nil),
+					gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+					gormprovider.PreloadOption("Tags"),
+					repository.QuestionFilter{Difficulty: entity.DifficultyHard, Tags: []string{"go"}, ExcludeIds: []uint{5, 4}},
+				).
+				Return(pool, nil)
+
+			assessmentRepository := mocks.NewAssessmentRepository(t)
+			assessmentRepository.On("GetAssessment", mock.Anything, previousAssessment.Id, mock.Anything).Return(previousAssessment, nil)
+
+			reqBodyBytes, err := json.Marshal(httpserver.GenerateAssessmentRequest{
+				Title:                "generated",
+				Seed:                 &seed,
+				ExcludeQuestionIds:   []uint{5},
+				ExcludeAssessmentIds: []uint{previousAssessment.Id},
+				Rules:                []generator.Rule{{Tags: []string{"go"}, Difficulty: entity.DifficultyHard, Count: test.Count}},
+			})
+			require.NoError(t, err)
+
+			server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository, Assessment: assessmentRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/assessments/generate", bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				return
+			}
+
+			var generateRes httpserver.GenerateAssessmentResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&generateRes))
+			assert.Equal(t, seed, generateRes.Seed)
+			assert.Len(t, generateRes.Assessment.AssessmentQuestions, int(test.Count))
+		})
+	}
+}
+
+func TestGenerateAssessment_OverlappingRules(t *testing.T) {
+	var authorId uint = 1
+	var seed int64 = 42
+	candidates := []entity.Question{}
+	for id := uint(1); id <= 10; id++ {
+		question := entity.Question{Id: id, Difficulty: entity.DifficultyEasy, Tags: []entity.QuestionTag{{Name: "go"}}}
+		if id <= 3 {
+			question.Difficulty = entity.DifficultyHard
+		}
+		candidates = append(candidates, question)
+	}
+	candidates = append(candidates, entity.Question{Id: 11, Difficulty: entity.DifficultyEasy, Tags: []entity.QuestionTag{{Name: "sql"}}})
+
+	// The candidates of every rule are loaded at once
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.
+		On(
+			"ListQuestions",
+			mock.Anything,
+			uint(1000),
+			(*uint)(nil),
+			gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+			gormprovider.PreloadOption("Tags"),
+			repository.QuestionFilter{ExcludeIds: []uint{}},
+		).
+		Return(candidates, nil).
+		Once()
+
+	reqBodyBytes, err := json.Marshal(httpserver.GenerateAssessmentRequest{
+		Title: "generated",
+		Seed:  &seed,
+		Rules: []generator.Rule{{Tags: []string{"go"}, Count: 7}, {Difficulty: entity.DifficultyHard, Count: 3}},
+	})
+	require.NoError(t, err)
+
+	server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/assessments/generate", bytes.NewReader(reqBodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var generateRes httpserver.GenerateAssessmentResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&generateRes))
+	questionIds := []uint{}
+	for _, assessmentQuestion := range generateRes.Assessment.AssessmentQuestions {
+		questionIds = append(questionIds, assessmentQuestion.QuestionId)
+	}
+	assert.ElementsMatch(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, questionIds)
+}
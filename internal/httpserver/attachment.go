@@ -0,0 +1,310 @@
+package httpserver
+
+import (
+	"challenge/internal/attachment"
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/blobstore"
+	"challenge/pkg/env"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultAttachmentMaxSize = 5 << 20
+	attachmentFilenameMax    = 255
+	// attachmentBodyLimitMargin leaves room for the multipart headers around the file
+	attachmentBodyLimitMargin = 64 << 10
+)
+
+// attachmentContentTypes are the sniffed content types accepted for uploads.
+// SVG is left out since it can run scripts
+var attachmentContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+var errAttachmentReferenced = errors.New("attachment is referenced")
+
+type AttachmentServer struct {
+	attachmentRepository repository.AttachmentRepository
+	blobStore            blobstore.Store
+	cleaner              *attachment.Cleaner
+	maxSize              int64
+}
+
+func NewAttachmentServer(attachmentRepository repository.AttachmentRepository, blobStore blobstore.Store, maxSize int64) *fiber.App {
+	jwtAuth := newJwtAuth()
+	server := &AttachmentServer{
+		attachmentRepository: attachmentRepository,
+		blobStore:            blobStore,
+		cleaner:              attachment.NewCleaner(attachmentRepository, blobStore, attachment.OrphanGrace()),
+		maxSize:              maxSize,
+	}
+	app := fiber.New()
+	app.Post("/", jwtAuth, server.UploadAttachment)
+	app.Post("/cleanup", jwtAuth, server.CleanupAttachments)
+	app.Get("/:id", server.GetAttachment)
+	app.Get("/:id/content", server.GetAttachmentContent)
+	app.Delete("/:id", jwtAuth, server.DeleteAttachment)
+
+	return app
+}
+
+// newAttachmentMaxSize reads the upload size limit in bytes, 5 MiB by default
+func newAttachmentMaxSize() int64 {
+	maxSize, err := strconv.ParseInt(env.GetOrDefault("ATTACHMENT_MAX_SIZE", strconv.Itoa(defaultAttachmentMaxSize)), 10, 64)
+	if err != nil || maxSize <= 0 {
+		log.Warn().Err(err).Msgf("Invalid ATTACHMENT_MAX_SIZE, using %d", defaultAttachmentMaxSize)
+		return defaultAttachmentMaxSize
+	}
+	return maxSize
+}
+
+// newBodyLimit raises the default request body limit when uploads need more
+func newBodyLimit(attachmentMaxSize int64) int {
+	if limit := int(attachmentMaxSize) + attachmentBodyLimitMargin; limit > fiber.DefaultBodyLimit {
+		return limit
+	}
+	return fiber.DefaultBodyLimit
+}
+
+// UploadAttachment stores the file of the multipart "file" field. The content type is sniffed
+// from the content instead of trusting the client
+func (s AttachmentServer) UploadAttachment(c *fiber.Ctx) error {
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "file is required"})
+	}
+	if fileHeader.Size == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "file is empty"})
+	}
+	if fileHeader.Size > s.maxSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: fmt.Sprintf("file is larger than %d bytes", s.maxSize)})
+	}
+	filename := filepath.Base(filepath.Clean("/" + fileHeader.Filename))
+	if len(filename) > attachmentFilenameMax {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "filename is too long"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open uploaded file")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	defer file.Close()
+
+	// Sniff the content type and check it against the declared one
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		log.Error().Err(err).Msg("Failed to read uploaded file")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !attachmentContentTypes[contentType] {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(ErrorResponse{Error: "Unsupported content type"})
+	}
+	declaredContentType, _, _ := mime.ParseMediaType(fileHeader.Header.Get(fiber.HeaderContentType))
+	if declaredContentType != "" && declaredContentType != fiber.MIMEOctetStream && declaredContentType != contentType {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(ErrorResponse{Error: "Content type does not match the file"})
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read uploaded file")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	hash := sha256.New()
+	blobKey := ulid.Make().String()
+	err = s.blobStore.Put(c.UserContext(), blobKey, io.TeeReader(file, hash), fileHeader.Size, contentType)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to store attachment blob")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	newAttachment := entity.Attachment{
+		BlobKey:     blobKey,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+		Sha256:      hex.EncodeToString(hash.Sum(nil)),
+		AuthorId:    authorId,
+		CreatedAt:   time.Now().UTC(),
+	}
+	err = s.attachmentRepository.CreateAttachment(c.UserContext(), &newAttachment)
+	if err != nil {
+		// The blob is collected by the orphan cleanup if this fails too
+		if err := s.blobStore.Delete(c.UserContext(), blobKey); err != nil {
+			log.Error().Err(err).Msg("Failed to delete attachment blob")
+		}
+		log.Error().Err(err).Msg("Failed to create attachment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(newAttachment)
+}
+
+func (s AttachmentServer) GetAttachment(c *fiber.Ctx) error {
+	existingAttachment, errStatus, errRes := s.getAttachment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(existingAttachment)
+}
+
+// GetAttachmentContent streams the blob. Attachments are public like the questions showing them
+func (s AttachmentServer) GetAttachmentContent(c *fiber.Ctx) error {
+	existingAttachment, errStatus, errRes := s.getAttachment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	content, err := s.blobStore.Get(c.UserContext(), existingAttachment.BlobKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get attachment blob")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	// The content of an attachment never changes
+	c.Set(fiber.HeaderContentType, existingAttachment.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	c.Set(fiber.HeaderETag, strconv.Quote(existingAttachment.Sha256))
+	if contentDisposition := mime.FormatMediaType("inline", map[string]string{"filename": existingAttachment.Filename}); contentDisposition != "" {
+		c.Set(fiber.HeaderContentDisposition, contentDisposition)
+	}
+	return c.SendStream(content, int(existingAttachment.Size))
+}
+
+// DeleteAttachment deletes an attachment that nothing references, questions have to drop it first
+func (s AttachmentServer) DeleteAttachment(c *fiber.Ctx) error {
+	existingAttachment, errStatus, errRes := s.getAttachment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+	if existingAttachment.AuthorId != userId && getAuthUserRole(c) != RoleAdmin {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	}
+
+	err = s.attachmentRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		referenced, err := s.attachmentRepository.IsAttachmentReferenced(txCtx, existingAttachment.Id)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return errAttachmentReferenced
+		}
+		return s.attachmentRepository.DeleteAttachment(txCtx, existingAttachment.Id)
+	})
+	if errors.Is(err, errAttachmentReferenced) {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attachment is referenced"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete attachment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	// A blob left behind is collected by the orphan cleanup
+	err = s.blobStore.Delete(c.UserContext(), existingAttachment.BlobKey)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete attachment blob")
+	}
+
+	return nil
+}
+
+// CleanupAttachments runs the orphan cleanup now instead of waiting for the periodic one
+func (s AttachmentServer) CleanupAttachments(c *fiber.Ctx) error {
+	if getAuthUserRole(c) != RoleAdmin {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	}
+
+	result, err := s.cleaner.Cleanup(c.UserContext(), time.Now().UTC())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clean up attachments")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(result)
+}
+
+func (s AttachmentServer) getAttachment(c *fiber.Ctx) (entity.Attachment, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Attachment{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	existingAttachment, err := s.attachmentRepository.GetAttachment(c.UserContext(), uint(id))
+	if err != nil {
+		return entity.Attachment{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	return existingAttachment, 0, nil
+}
+
+// checkQuestionAttachments requires the attachments referenced by the question and its options
+// to be uploaded by the user
+func checkQuestionAttachments(ctx context.Context, attachmentRepository repository.AttachmentRepository, question entity.Question, userId uint) (int, any) {
+	attachmentIds := []uint{}
+	if question.AttachmentId != nil {
+		attachmentIds = append(attachmentIds, *question.AttachmentId)
+	}
+	for _, questionOption := range question.QuestionOptions {
+		if questionOption.AttachmentId != nil {
+			attachmentIds = append(attachmentIds, *questionOption.AttachmentId)
+		}
+	}
+	if len(attachmentIds) == 0 {
+		return 0, nil
+	}
+
+	attachments, err := attachmentRepository.ListAttachments(ctx, attachmentIds)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list attachments")
+		return fiber.StatusInternalServerError, ErrorResponse{Error: "Internal error"}
+	}
+	ownAttachmentIds := map[uint]bool{}
+	for _, existingAttachment := range attachments {
+		if existingAttachment.AuthorId == userId {
+			ownAttachmentIds[existingAttachment.Id] = true
+		}
+	}
+	for _, attachmentId := range attachmentIds {
+		if !ownAttachmentIds[attachmentId] {
+			return fiber.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Attachment %d is not an attachment of the user", attachmentId)}
+		}
+	}
+
+	return 0, nil
+}
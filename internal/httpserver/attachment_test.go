@@ -0,0 +1,252 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/blobstore"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var pngContent = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+
+func newUploadRequest(t *testing.T, filename string, contentType string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/attachments", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	return req
+}
+
+func TestUploadAttachment(t *testing.T) {
+	t.Setenv("ATTACHMENT_MAX_SIZE", "1024")
+
+	type Test struct {
+		TestName               string
+		Filename               string
+		ContentType            string
+		Content                []byte
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Png",
+			Filename:               "diagram.png",
+			ContentType:            "image/png",
+			Content:                pngContent,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "UndeclaredContentType",
+			Filename:               "diagram.png",
+			Content:                pngContent,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Svg",
+			Filename:               "diagram.svg",
+			ContentType:            "image/svg+xml",
+			Content:                []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+			ExpectedHttpStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			TestName:               "ContentTypeMismatch",
+			Filename:               "diagram.jpg",
+			ContentType:            "image/jpeg",
+			Content:                pngContent,
+			ExpectedHttpStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			TestName:               "Empty",
+			Filename:               "diagram.png",
+			ContentType:            "image/png",
+			Content:                []byte{},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "TooLarge",
+			Filename:               "diagram.png",
+			ContentType:            "image/png",
+			Content:                append(append([]byte{}, pngContent...), make([]byte, 1024)...),
+			ExpectedHttpStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			blobStore, err := blobstore.NewFileSystemStore(t.TempDir())
+			require.NoError(t, err)
+			attachmentRepository := mocks.NewAttachmentRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				attachmentRepository.On("CreateAttachment", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, attachment *entity.Attachment) error {
+						assert.Equal(t, "diagram.png", attachment.Filename)
+						assert.Equal(t, "image/png", attachment.ContentType)
+						assert.Equal(t, int64(len(pngContent)), attachment.Size)
+						assert.Equal(t, uint(1), attachment.AuthorId)
+						attachment.Id = 1
+						return nil
+					})
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{Attachment: attachmentRepository}, blobStore, nil)
+			res, err := server.Test(newUploadRequest(t, test.Filename, test.ContentType, test.Content))
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+
+			blobs, err := blobStore.List(context.Background())
+			require.NoError(t, err)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				assert.Empty(t, blobs)
+				return
+			}
+			require.Len(t, blobs, 1)
+			var attachment map[string]any
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&attachment))
+			assert.Equal(t, float64(1), attachment["id"])
+			assert.NotContains(t, attachment, "BlobKey")
+		})
+	}
+}
+
+func TestGetAttachmentContent(t *testing.T) {
+	blobStore, err := blobstore.NewFileSystemStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, blobStore.Put(context.Background(), "blob", bytes.NewReader(pngContent), int64(len(pngContent)), "image/png"))
+	attachmentRepository := mocks.NewAttachmentRepository(t)
+	attachmentRepository.On("GetAttachment", mock.Anything, uint(1)).Return(entity.Attachment{
+		Id:          1,
+		BlobKey:     "blob",
+		Filename:    "diagram.png",
+		ContentType: "image/png",
+		Size:        int64(len(pngContent)),
+		Sha256:      "abc",
+	}, nil)
+	attachmentRepository.On("GetAttachment", mock.Anything, uint(2)).Return(entity.Attachment{}, gorm.ErrRecordNotFound)
+
+	server := httpserver.NewServer(httpserver.Repositories{Attachment: attachmentRepository}, blobStore, nil)
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/attachments/1/content", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+	assert.Equal(t, "nosniff", res.Header.Get("X-Content-Type-Options"))
+	assert.Equal(t, `inline; filename=diagram.png`, res.Header.Get("Content-Disposition"))
+	content, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, pngContent, content)
+
+	res, err = server.Test(httptest.NewRequest(http.MethodGet, "/attachments/2/content", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	type Test struct {
+		TestName               string
+		UserId                 uint
+		Referenced             bool
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Delete",
+			UserId:                 1,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Referenced",
+			UserId:                 1,
+			Referenced:             true,
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "NotAuthor",
+			UserId:                 2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			blobStore, err := blobstore.NewFileSystemStore(t.TempDir())
+			require.NoError(t, err)
+			require.NoError(t, blobStore.Put(context.Background(), "blob", bytes.NewReader(pngContent), int64(len(pngContent)), "image/png"))
+			attachmentRepository := mocks.NewAttachmentRepository(t)
+			attachmentRepository.On("GetAttachment", mock.Anything, uint(1)).
+				Return(entity.Attachment{Id: 1, BlobKey: "blob", AuthorId: 1}, nil)
+			if test.UserId == 1 {
+				attachmentRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				attachmentRepository.On("IsAttachmentReferenced", mock.Anything, uint(1)).Return(test.Referenced, nil)
+			}
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				attachmentRepository.On("DeleteAttachment", mock.Anything, uint(1)).Return(nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{Attachment: attachmentRepository}, blobStore, nil)
+			req := httptest.NewRequest(http.MethodDelete, "/attachments/1", nil)
+			req.Header.Set("Authorization", newAuthHeader(t, test.UserId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+
+			_, err = blobStore.Get(context.Background(), "blob")
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				assert.ErrorIs(t, err, blobstore.ErrNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateQuestionAttachments(t *testing.T) {
+	attachmentRepository := mocks.NewAttachmentRepository(t)
+	attachmentRepository.On("ListAttachments", mock.Anything, []uint{1, 2}).
+		Return([]entity.Attachment{{Id: 1, AuthorId: 1}, {Id: 2, AuthorId: 2}}, nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{Attachment: attachmentRepository}, nil, nil)
+	reqBody := `{
+		"body": "What does the diagram show?",
+		"attachmentId": 1,
+		"options": [
+			{"body": "A", "correct": true, "attachmentId": 2},
+			{"body": "B", "correct": false}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/questions", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	var errRes httpserver.ErrorResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&errRes))
+	assert.Equal(t, "Attachment 2 is not an attachment of the user", errRes.Error)
+}
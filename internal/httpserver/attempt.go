@@ -0,0 +1,706 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/scoring"
+	"challenge/internal/stats"
+	"challenge/pkg/gormprovider"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var attemptAnswersPreload = gormprovider.PreloadOption("AttemptAnswers")
+
+type AttemptServer struct {
+	assessmentRepository          repository.AssessmentRepository
+	attemptRepository             repository.AttemptRepository
+	attemptAnswerRepository       repository.AttemptAnswerRepository
+	questionStatsRepository       repository.QuestionStatsRepository
+	questionOptionStatsRepository repository.QuestionOptionStatsRepository
+	questionTranslationRepository repository.QuestionTranslationRepository
+}
+
+func NewAttemptServer(
+	assessmentRepository repository.AssessmentRepository,
+	attemptRepository repository.AttemptRepository,
+	attemptAnswerRepository repository.AttemptAnswerRepository,
+	questionStatsRepository repository.QuestionStatsRepository,
+	questionOptionStatsRepository repository.QuestionOptionStatsRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+) *fiber.App {
+	server := &AttemptServer{
+		assessmentRepository,
+		attemptRepository,
+		attemptAnswerRepository,
+		questionStatsRepository,
+		questionOptionStatsRepository,
+		questionTranslationRepository,
+	}
+	app := fiber.New()
+	app.Use(newJwtAuth())
+	app.Post("/", server.StartAttempt)
+	app.Get("/:id", server.GetAttempt)
+	app.Get("/:id/questions", server.ListAttemptQuestions)
+	app.Put("/:id/answers/:position", server.SubmitAttemptAnswer)
+	app.Post("/:id/finish", server.FinishAttempt)
+	app.Get("/:id/review", server.ReviewAttempt)
+
+	return app
+}
+
+type StartAttemptRequest struct {
+	AssessmentId uint `json:"assessmentId" validate:"required"`
+}
+
+type SubmitAttemptAnswerRequest struct {
+	SelectedOptions entity.OptionPositions `json:"selectedOptions"` // Positions shown to the candidate, in the chosen order for ordering questions
+	NumericValue    *float64               `json:"numericValue"`
+}
+
+// CandidateQuestion is a question as shown to candidates, without the correct options
+type CandidateQuestion struct {
+	Position     uint                      `json:"position"`
+	Type         string                    `json:"type"`
+	Body         string                    `json:"body"`
+	BodyFormat   string                    `json:"bodyFormat"`
+	BodyHTML     string                    `json:"bodyHtml,omitempty"`
+	Language     string                    `json:"language"` // Language of the bodies, the translation served if any
+	AttachmentId *uint                     `json:"attachmentId"`
+	Points       float64                   `json:"points"`
+	Options      []CandidateQuestionOption `json:"options"`
+}
+
+type CandidateQuestionOption struct {
+	Position     uint   `json:"position"`
+	Body         string `json:"body"`
+	BodyFormat   string `json:"bodyFormat"`
+	BodyHTML     string `json:"bodyHtml,omitempty"`
+	AttachmentId *uint  `json:"attachmentId"`
+}
+
+// ReviewQuestion is a question of a finished attempt with its answer key, explanation and feedbacks
+// next to the candidate answer
+type ReviewQuestion struct {
+	Position          uint                   `json:"position"`
+	Type              string                 `json:"type"`
+	Body              string                 `json:"body"`
+	BodyFormat        string                 `json:"bodyFormat"`
+	BodyHTML          string                 `json:"bodyHtml,omitempty"`
+	Explanation       string                 `json:"explanation"`
+	ExplanationFormat string                 `json:"explanationFormat"`
+	ExplanationHTML   string                 `json:"explanationHtml,omitempty"`
+	Language          string                 `json:"language"`
+	AttachmentId      *uint                  `json:"attachmentId"`
+	Points            float64                `json:"points"`
+	NumericAnswer     *float64               `json:"numericAnswer"`
+	NumericTolerance  float64                `json:"numericTolerance"`
+	Options           []ReviewQuestionOption `json:"options"` // In the correct order for ordering questions
+	Answer            *entity.AttemptAnswer  `json:"answer"`  // Not set when the question was not answered
+}
+
+type ReviewQuestionOption struct {
+	Position       uint   `json:"position"`
+	Body           string `json:"body"`
+	BodyFormat     string `json:"bodyFormat"`
+	BodyHTML       string `json:"bodyHtml,omitempty"`
+	Correct        bool   `json:"correct"`
+	Feedback       string `json:"feedback"`
+	FeedbackFormat string `json:"feedbackFormat"`
+	FeedbackHTML   string `json:"feedbackHtml,omitempty"`
+	AttachmentId   *uint  `json:"attachmentId"`
+}
+
+func (s AttemptServer) StartAttempt(c *fiber.Ctx) error {
+	// Get authenticated user id - candidate
+	candidateId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Validate and parse request
+	var req StartAttemptRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), req.AssessmentId)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+
+	optionSeed, err := newOptionSeed()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate option seed")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	attempt := entity.Attempt{
+		StartedAt:    time.Now().UTC(),
+		OptionSeed:   optionSeed,
+		CandidateId:  candidateId,
+		AssessmentId: assessment.Id,
+	}
+	if assessment.TimeLimitSeconds != 0 {
+		deadline := attempt.StartedAt.Add(time.Duration(assessment.TimeLimitSeconds) * time.Second)
+		attempt.Deadline = &deadline
+	}
+
+	err = s.attemptRepository.CreateAttempt(c.UserContext(), &attempt)
+	if err != nil {
+		// Candidates get a single attempt per assessment
+		if isUniqueConstraintError(err) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attempt already started"})
+		}
+		log.Error().Err(err).Msg("Failed to create attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(attempt)
+}
+
+func (s AttemptServer) GetAttempt(c *fiber.Ctx) error {
+	attempt, errStatus, errRes := s.getVisibleAttempt(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.finishExpiredAttempt(c.UserContext(), &attempt)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to finish attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	err = s.showCandidatePositions(c.UserContext(), &attempt)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(attempt)
+}
+
+// ReviewAttempt returns the questions of a finished attempt with the correct options, explanations
+// and feedbacks, which candidates don't see while answering
+func (s AttemptServer) ReviewAttempt(c *fiber.Ctx) error {
+	render, errRes := parseRender(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	langs, errRes := parseLanguages(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	attempt, errStatus, errRes := s.getVisibleAttempt(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.finishExpiredAttempt(c.UserContext(), &attempt)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to finish attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if !attempt.IsFinished() {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attempt is not finished"})
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), attempt.AssessmentId, assessmentQuestionsPreload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	questions := make([]entity.Question, len(assessment.AssessmentQuestions))
+	for i, assessmentQuestion := range assessment.AssessmentQuestions {
+		questions[i] = entity.Question(assessmentQuestion.Snapshot)
+	}
+	err = translateQuestions(c.UserContext(), s.questionTranslationRepository, questions, langs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to translate attempt questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	attemptAnswers := map[uint]entity.AttemptAnswer{}
+	for _, attemptAnswer := range attempt.AttemptAnswers {
+		attemptAnswers[attemptAnswer.AssessmentQuestionId] = attemptAnswer
+	}
+	reviewQuestions := make([]ReviewQuestion, len(assessment.AssessmentQuestions))
+	for i, assessmentQuestion := range assessment.AssessmentQuestions {
+		if render {
+			if err := renderQuestionHTML(&questions[i]); err != nil {
+				log.Error().Err(err).Msg("Failed to render attempt questions")
+				return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+			}
+		}
+		reviewQuestions[i] = newReviewQuestion(uint(i), assessmentQuestion, questions[i])
+		if attemptAnswer, found := attemptAnswers[assessmentQuestion.Id]; found {
+			reviewQuestions[i].Answer = &attemptAnswer
+		}
+	}
+
+	return c.JSON(reviewQuestions)
+}
+
+func (s AttemptServer) ListAttemptQuestions(c *fiber.Ctx) error {
+	render, errRes := parseRender(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	langs, errRes := parseLanguages(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	attempt, errStatus, errRes := s.getCandidateAttempt(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), attempt.AssessmentId, assessmentQuestionsPreload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	// Snapshots keep the question id, so they are translated with the current translations of the question
+	questions := make([]entity.Question, len(assessment.AssessmentQuestions))
+	for i, assessmentQuestion := range assessment.AssessmentQuestions {
+		questions[i] = entity.Question(assessmentQuestion.Snapshot)
+	}
+	err = translateQuestions(c.UserContext(), s.questionTranslationRepository, questions, langs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to translate attempt questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	candidateQuestions := make([]CandidateQuestion, len(assessment.AssessmentQuestions))
+	for i, assessmentQuestion := range assessment.AssessmentQuestions {
+		if render {
+			if err := renderQuestionHTML(&questions[i]); err != nil {
+				log.Error().Err(err).Msg("Failed to render attempt questions")
+				return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+			}
+		}
+		assessmentQuestion.Snapshot = entity.QuestionSnapshot(questions[i])
+		candidateQuestions[i] = newCandidateQuestion(attempt, uint(i), assessmentQuestion)
+	}
+
+	return c.JSON(candidateQuestions)
+}
+
+func (s AttemptServer) SubmitAttemptAnswer(c *fiber.Ctx) error {
+	attempt, errStatus, errRes := s.getCandidateAttempt(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	position, err := c.ParamsInt("position")
+	if err != nil || position < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "position is invalid"})
+	}
+
+	// Validate and parse request
+	var req SubmitAttemptAnswerRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Answers are only accepted while the attempt is open
+	err = s.finishExpiredAttempt(c.UserContext(), &attempt)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to finish attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if attempt.IsFinished() {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attempt is finished"})
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), attempt.AssessmentId, assessmentQuestionsPreload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get assessment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if position >= len(assessment.AssessmentQuestions) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+	assessmentQuestion := assessment.AssessmentQuestions[position]
+
+	errRes = validateAttemptAnswer(entity.Question(assessmentQuestion.Snapshot), req)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Answers are scored against the snapshot positions
+	question := entity.Question(assessmentQuestion.Snapshot)
+	selectedOptions := req.SelectedOptions
+	if question.GetType() == entity.QuestionTypeOrdering {
+		order := candidateOptionOrder(attempt, uint(position), len(question.QuestionOptions))
+		selectedOptions = toSnapshotPositions(order, req.SelectedOptions)
+	}
+
+	attemptAnswer := entity.AttemptAnswer{
+		SelectedOptions:      selectedOptions,
+		NumericValue:         req.NumericValue,
+		AnsweredAt:           time.Now().UTC(),
+		AttemptId:            attempt.Id,
+		AssessmentQuestionId: assessmentQuestion.Id,
+	}
+	// The attempt may have been finished since it was read, it cannot be finished while the answer is saved
+	var open bool
+	err = s.attemptRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		open, err = s.attemptRepository.LockOpenAttempt(txCtx, attempt.Id)
+		if err != nil || !open {
+			return err
+		}
+		return s.attemptAnswerRepository.SaveAttemptAnswer(txCtx, &attemptAnswer)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save attempt answer")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if !open {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attempt is finished"})
+	}
+
+	attemptAnswer.SelectedOptions = req.SelectedOptions
+	return c.JSON(attemptAnswer)
+}
+
+func (s AttemptServer) FinishAttempt(c *fiber.Ctx) error {
+	attempt, errStatus, errRes := s.getCandidateAttempt(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.finishExpiredAttempt(c.UserContext(), &attempt)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to finish attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if attempt.IsFinished() {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Attempt is finished"})
+	}
+
+	err = s.finishAttempt(c.UserContext(), &attempt, time.Now().UTC())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to finish attempt")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(attempt)
+}
+
+// getVisibleAttempt loads the attempt in the url and checks that the auth user is its candidate,
+// the assessment author or an admin
+func (s AttemptServer) getVisibleAttempt(c *fiber.Ctx) (entity.Attempt, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	attempt, err := s.attemptRepository.GetAttempt(c.UserContext(), uint(id), attemptAnswersPreload)
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	if attempt.CandidateId != userId && getAuthUserRole(c) != RoleAdmin {
+		assessment, err := s.assessmentRepository.GetAssessment(c.UserContext(), attempt.AssessmentId)
+		if err != nil || assessment.AuthorId != userId {
+			return entity.Attempt{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+		}
+	}
+
+	return attempt, 0, nil
+}
+
+// getCandidateAttempt loads the attempt in the url and checks that the auth user is its candidate
+func (s AttemptServer) getCandidateAttempt(c *fiber.Ctx) (entity.Attempt, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	candidateId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	attempt, err := s.attemptRepository.GetAttempt(c.UserContext(), uint(id), attemptAnswersPreload)
+	if err != nil {
+		return entity.Attempt{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	if attempt.CandidateId != candidateId {
+		return entity.Attempt{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return attempt, 0, nil
+}
+
+// finishExpiredAttempt locks the attempt at its deadline once the time limit is exceeded
+func (s AttemptServer) finishExpiredAttempt(ctx context.Context, attempt *entity.Attempt) error {
+	if attempt.IsFinished() || !attempt.IsExpired(time.Now()) {
+		return nil
+	}
+
+	return s.finishAttempt(ctx, attempt, *attempt.Deadline)
+}
+
+// finishAttempt scores the attempt answers, locks the attempt with its scores
+// and adds its responses to the question stats
+func (s AttemptServer) finishAttempt(ctx context.Context, attempt *entity.Attempt, finishedAt time.Time) error {
+	assessment, err := s.assessmentRepository.GetAssessment(ctx, attempt.AssessmentId, assessmentQuestionsPreload)
+	if err != nil {
+		return err
+	}
+
+	var finished bool
+	var finishedAttempt entity.Attempt
+	err = s.attemptRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		finished, err = s.attemptRepository.LockOpenAttempt(txCtx, attempt.Id)
+		if err != nil || !finished {
+			return err
+		}
+
+		// Answers saved since the attempt was read are scored too, no answer is saved until the attempt is finished
+		finishedAttempt, err = s.attemptRepository.GetAttempt(txCtx, attempt.Id, attemptAnswersPreload)
+		if err != nil {
+			return err
+		}
+		attemptScore := scoring.ScoreAttempt(assessment, finishedAttempt.AttemptAnswers)
+		questionStats, questionOptionStats := stats.AttemptIncrements(assessment, finishedAttempt.AttemptAnswers, attemptScore)
+
+		finishedAttempt.FinishedAt = &finishedAt
+		finishedAttempt.Score = &attemptScore.Score
+		finishedAttempt.MaxScore = &attemptScore.MaxScore
+		finishedAttempt.Passed = &attemptScore.Passed
+		_, err = s.attemptRepository.FinishAttempt(txCtx, attempt.Id, &finishedAttempt)
+		if err != nil {
+			return err
+		}
+
+		for i, attemptAnswer := range finishedAttempt.AttemptAnswers {
+			score := attemptScore.AnswerScores[attemptAnswer.AssessmentQuestionId]
+			err = s.attemptAnswerRepository.UpdateAttemptAnswerScore(txCtx, attemptAnswer.Id, score)
+			if err != nil {
+				return err
+			}
+			finishedAttempt.AttemptAnswers[i].Score = &score
+		}
+
+		err = s.questionStatsRepository.IncrementQuestionStats(txCtx, questionStats)
+		if err != nil {
+			return err
+		}
+		return s.questionOptionStatsRepository.IncrementQuestionOptionStats(txCtx, questionOptionStats)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Another request finished the attempt first
+	if !finished {
+		*attempt, err = s.attemptRepository.GetAttempt(ctx, attempt.Id, attemptAnswersPreload)
+		return err
+	}
+
+	*attempt = finishedAttempt
+	return nil
+}
+
+// showCandidatePositions maps the ordering answers of an open attempt to the positions shown to the candidate,
+// so that the attempt doesn't reveal the correct order of the options
+func (s AttemptServer) showCandidatePositions(ctx context.Context, attempt *entity.Attempt) error {
+	if attempt.IsFinished() || len(attempt.AttemptAnswers) == 0 {
+		return nil
+	}
+
+	assessment, err := s.assessmentRepository.GetAssessment(ctx, attempt.AssessmentId, assessmentQuestionsPreload)
+	if err != nil {
+		return err
+	}
+	for position, assessmentQuestion := range assessment.AssessmentQuestions {
+		question := entity.Question(assessmentQuestion.Snapshot)
+		if question.GetType() != entity.QuestionTypeOrdering {
+			continue
+		}
+		order := candidateOptionOrder(*attempt, uint(position), len(question.QuestionOptions))
+		for i, attemptAnswer := range attempt.AttemptAnswers {
+			if attemptAnswer.AssessmentQuestionId == assessmentQuestion.Id {
+				attempt.AttemptAnswers[i].SelectedOptions = toCandidatePositions(order, attemptAnswer.SelectedOptions)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAttemptAnswer checks the answer has the shape expected by the question type
+func validateAttemptAnswer(question entity.Question, req SubmitAttemptAnswerRequest) any {
+	if question.GetType() == entity.QuestionTypeNumeric {
+		if len(req.SelectedOptions) != 0 {
+			return ErrorResponse{Error: "selectedOptions is not accepted by numeric questions"}
+		}
+		return nil
+	}
+	if req.NumericValue != nil {
+		return ErrorResponse{Error: "numericValue is only accepted by numeric questions"}
+	}
+
+	// Check selected options exist in the question
+	seen := map[uint]bool{}
+	for _, optionPosition := range req.SelectedOptions {
+		if optionPosition >= uint(len(question.QuestionOptions)) || seen[optionPosition] {
+			return ErrorResponse{Error: fmt.Sprintf("selected option %d is invalid", optionPosition)}
+		}
+		seen[optionPosition] = true
+	}
+
+	switch question.GetType() {
+	case entity.QuestionTypeSingleChoice:
+		if len(req.SelectedOptions) > 1 {
+			return ErrorResponse{Error: "single choice questions accept one option"}
+		}
+	case entity.QuestionTypeOrdering:
+		if len(req.SelectedOptions) != 0 && len(req.SelectedOptions) != len(question.QuestionOptions) {
+			return ErrorResponse{Error: "ordering questions must order every option"}
+		}
+	}
+
+	return nil
+}
+
+func newCandidateQuestion(attempt entity.Attempt, position uint, assessmentQuestion entity.AssessmentQuestion) CandidateQuestion {
+	question := entity.Question(assessmentQuestion.Snapshot)
+	candidateQuestion := CandidateQuestion{
+		Position:     position,
+		Type:         question.GetType(),
+		Body:         question.Body,
+		BodyFormat:   question.GetBodyFormat(),
+		BodyHTML:     question.BodyHTML,
+		Language:     question.GetLanguage(),
+		AttachmentId: question.AttachmentId,
+		Points:       assessmentQuestion.GetPoints(),
+		Options:      make([]CandidateQuestionOption, len(question.QuestionOptions)),
+	}
+	if question.Translation != "" {
+		candidateQuestion.Language = question.Translation
+	}
+	var order []uint
+	if candidateQuestion.Type == entity.QuestionTypeOrdering {
+		order = candidateOptionOrder(attempt, position, len(question.QuestionOptions))
+	}
+	for i := range question.QuestionOptions {
+		questionOption := question.QuestionOptions[i]
+		if order != nil {
+			questionOption = question.QuestionOptions[order[i]]
+		}
+		candidateQuestion.Options[i] = CandidateQuestionOption{
+			Position:     uint(i),
+			Body:         questionOption.Body,
+			BodyFormat:   questionOption.GetBodyFormat(),
+			BodyHTML:     questionOption.BodyHTML,
+			AttachmentId: questionOption.AttachmentId,
+		}
+	}
+
+	return candidateQuestion
+}
+
+// candidateOptionOrder returns the snapshot positions of the options of an ordering question in the order shown to
+// the candidate. Snapshots store the options in the correct order, so they are shuffled with the secret seed of the
+// attempt, the same way on every request
+func candidateOptionOrder(attempt entity.Attempt, position uint, optionCount int) []uint {
+	order := make([]uint, optionCount)
+	for i := range order {
+		order[i] = uint(i)
+	}
+	rng := rand.New(rand.NewSource(attempt.OptionSeed ^ int64(position)))
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
+
+// toSnapshotPositions maps positions shown to the candidate to snapshot positions
+func toSnapshotPositions(order []uint, positions entity.OptionPositions) entity.OptionPositions {
+	snapshotPositions := make(entity.OptionPositions, len(positions))
+	for i, position := range positions {
+		snapshotPositions[i] = order[position]
+	}
+	return snapshotPositions
+}
+
+// toCandidatePositions maps snapshot positions to the positions shown to the candidate
+func toCandidatePositions(order []uint, positions entity.OptionPositions) entity.OptionPositions {
+	candidatePositions := make([]uint, len(order))
+	for i, position := range order {
+		candidatePositions[position] = uint(i)
+	}
+	mapped := make(entity.OptionPositions, len(positions))
+	for i, position := range positions {
+		mapped[i] = candidatePositions[position]
+	}
+	return mapped
+}
+
+// newOptionSeed returns a random seed the candidate can't predict
+func newOptionSeed() (int64, error) {
+	seedBytes := make([]byte, 8)
+	_, err := cryptorand.Read(seedBytes)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(seedBytes)), nil
+}
+
+func newReviewQuestion(position uint, assessmentQuestion entity.AssessmentQuestion, question entity.Question) ReviewQuestion {
+	reviewQuestion := ReviewQuestion{
+		Position:          position,
+		Type:              question.GetType(),
+		Body:              question.Body,
+		BodyFormat:        question.GetBodyFormat(),
+		BodyHTML:          question.BodyHTML,
+		Explanation:       question.Explanation,
+		ExplanationFormat: question.GetExplanationFormat(),
+		ExplanationHTML:   question.ExplanationHTML,
+		Language:          question.GetLanguage(),
+		AttachmentId:      question.AttachmentId,
+		Points:            assessmentQuestion.GetPoints(),
+		NumericAnswer:     question.NumericAnswer,
+		NumericTolerance:  question.NumericTolerance,
+		Options:           make([]ReviewQuestionOption, len(question.QuestionOptions)),
+	}
+	if question.Translation != "" {
+		reviewQuestion.Language = question.Translation
+	}
+	for i, questionOption := range question.QuestionOptions {
+		reviewQuestion.Options[i] = ReviewQuestionOption{
+			Position:       uint(i),
+			Body:           questionOption.Body,
+			BodyFormat:     questionOption.GetBodyFormat(),
+			BodyHTML:       questionOption.BodyHTML,
+			Correct:        questionOption.Correct != nil && *questionOption.Correct,
+			Feedback:       questionOption.Feedback,
+			FeedbackFormat: questionOption.GetFeedbackFormat(),
+			FeedbackHTML:   questionOption.FeedbackHTML,
+			AttachmentId:   questionOption.AttachmentId,
+		}
+	}
+	return reviewQuestion
+}
@@ -0,0 +1,493 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttemptAssessment(assessmentId uint) entity.Assessment {
+	correct := true
+	incorrect := false
+	return entity.Assessment{
+		Id:    assessmentId,
+		Title: "assessment",
+		AssessmentQuestions: []entity.AssessmentQuestion{
+			{
+				Id:         10,
+				QuestionId: 1,
+				Snapshot: entity.QuestionSnapshot{
+					Id:          1,
+					Body:        "question",
+					Explanation: "because",
+					QuestionOptions: []entity.QuestionOption{
+						{Body: "correct", Feedback: "right", Correct: &correct},
+						{Body: "incorrect", Feedback: "wrong", Correct: &incorrect},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestListAttemptQuestions(t *testing.T) {
+	var candidateId uint = 2
+	var attemptId uint = 1
+	assessment := newAttemptAssessment(1)
+
+	attemptRepository := mocks.NewAttemptRepository(t)
+	attemptRepository.On("GetAttempt", mock.Anything, attemptId, mock.Anything).
+		Return(entity.Attempt{Id: attemptId, CandidateId: candidateId, AssessmentId: assessment.Id}, nil)
+
+	assessmentRepository := mocks.NewAssessmentRepository(t)
+	assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{Assessment: assessmentRepository, Attempt: attemptRepository}, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/attempts/%d/questions", attemptId), nil)
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	resBodyBytes, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(resBodyBytes), "correct\":")
+	assert.NotContains(t, string(resBodyBytes), "because")
+	assert.NotContains(t, string(resBodyBytes), "right")
+	var candidateQuestions []httpserver.CandidateQuestion
+	require.NoError(t, json.Unmarshal(resBodyBytes, &candidateQuestions))
+	require.Len(t, candidateQuestions, 1)
+	assert.Equal(t, "question", candidateQuestions[0].Body)
+	assert.Equal(t, []httpserver.CandidateQuestionOption{
+		{Position: 0, Body: "correct", BodyFormat: "plain"},
+		{Position: 1, Body: "incorrect", BodyFormat: "plain"},
+	}, candidateQuestions[0].Options)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/attempts/%d/questions?render=html", attemptId), nil)
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&candidateQuestions))
+	assert.Equal(t, "<p>question</p>", candidateQuestions[0].BodyHTML)
+	assert.Equal(t, "<p>correct</p>", candidateQuestions[0].Options[0].BodyHTML)
+}
+
+func TestAttemptOrderingQuestion(t *testing.T) {
+	var candidateId uint = 2
+	var attemptId uint = 1
+	correct := true
+	assessment := entity.Assessment{
+		Id: 1,
+		AssessmentQuestions: []entity.AssessmentQuestion{{
+			Id: 10,
+			Snapshot: entity.QuestionSnapshot{
+				Type: entity.QuestionTypeOrdering,
+				Body: "order",
+				QuestionOptions: []entity.QuestionOption{
+					{Body: "a", Correct: &correct},
+					{Body: "b", Correct: &correct},
+					{Body: "c", Correct: &correct},
+					{Body: "d", Correct: &correct},
+					{Body: "e", Correct: &correct},
+				},
+			},
+		}},
+	}
+	attempt := entity.Attempt{Id: attemptId, OptionSeed: 42, CandidateId: candidateId, AssessmentId: assessment.Id}
+
+	attemptRepository := mocks.NewAttemptRepository(t)
+	attemptRepository.On("GetAttempt", mock.Anything, attemptId, mock.Anything).Return(attempt, nil)
+	attemptRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	attemptRepository.On("LockOpenAttempt", mock.Anything, attemptId).Return(true, nil)
+	assessmentRepository := mocks.NewAssessmentRepository(t)
+	assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil)
+	attemptAnswerRepository := mocks.NewAttemptAnswerRepository(t)
+	attemptAnswerRepository.On("SaveAttemptAnswer", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, attemptAnswer *entity.AttemptAnswer) error {
+			// The answer is stored with the snapshot positions
+			assert.Equal(t, entity.OptionPositions{0, 1, 2, 3, 4}, attemptAnswer.SelectedOptions)
+			return nil
+		})
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Assessment:    assessmentRepository,
+		Attempt:       attemptRepository,
+		AttemptAnswer: attemptAnswerRepository,
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/attempts/%d/questions", attemptId), nil)
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var candidateQuestions []httpserver.CandidateQuestion
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&candidateQuestions))
+	require.Len(t, candidateQuestions, 1)
+
+	// Sorting the options by position doesn't give the correct order
+	options := candidateQuestions[0].Options
+	bodies := make([]string, len(options))
+	positions := map[string]uint{}
+	for i, option := range options {
+		assert.Equal(t, uint(i), option.Position)
+		bodies[i] = option.Body
+		positions[option.Body] = option.Position
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, bodies)
+	assert.NotEqual(t, []string{"a", "b", "c", "d", "e"}, bodies)
+
+	// The candidate answers with the positions shown
+	correctOrder := entity.OptionPositions{positions["a"], positions["b"], positions["c"], positions["d"], positions["e"]}
+	reqBodyBytes, err := json.Marshal(httpserver.SubmitAttemptAnswerRequest{SelectedOptions: correctOrder})
+	require.NoError(t, err)
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/attempts/%d/answers/0", attemptId), bytes.NewReader(reqBodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var attemptAnswer entity.AttemptAnswer
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&attemptAnswer))
+	assert.Equal(t, correctOrder, attemptAnswer.SelectedOptions)
+}
+
+func TestReviewAttempt(t *testing.T) {
+	var candidateId uint = 2
+	var attemptId uint = 1
+	assessment := newAttemptAssessment(1)
+	score := 0.0
+	attemptAnswer := entity.AttemptAnswer{SelectedOptions: entity.OptionPositions{1}, Score: &score, AssessmentQuestionId: 10}
+
+	type Test struct {
+		TestName               string
+		UserId                 uint
+		Finished               bool
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Candidate",
+			UserId:                 candidateId,
+			Finished:               true,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "NotFinished",
+			UserId:                 candidateId,
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "OtherCandidate",
+			UserId:                 3,
+			Finished:               true,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			attempt := entity.Attempt{
+				Id:             attemptId,
+				CandidateId:    candidateId,
+				AssessmentId:   assessment.Id,
+				AttemptAnswers: []entity.AttemptAnswer{attemptAnswer},
+			}
+			if test.Finished {
+				finishedAt := time.Now()
+				attempt.FinishedAt = &finishedAt
+			}
+			attemptRepository := mocks.NewAttemptRepository(t)
+			attemptRepository.On("GetAttempt", mock.Anything, attemptId, mock.Anything).Return(attempt, nil)
+			assessmentRepository := mocks.NewAssessmentRepository(t)
+			assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil).Maybe()
+
+			server := httpserver.NewServer(httpserver.Repositories{Assessment: assessmentRepository, Attempt: attemptRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/attempts/%d/review", attemptId), nil)
+			req.Header.Set("Authorization", newAuthHeader(t, test.UserId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				return
+			}
+
+			var reviewQuestions []httpserver.ReviewQuestion
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&reviewQuestions))
+			require.Len(t, reviewQuestions, 1)
+			assert.Equal(t, "because", reviewQuestions[0].Explanation)
+			assert.Equal(t, []httpserver.ReviewQuestionOption{
+				{Position: 0, Body: "correct", BodyFormat: "plain", Correct: true, Feedback: "right", FeedbackFormat: "plain"},
+				{Position: 1, Body: "incorrect", BodyFormat: "plain", Feedback: "wrong", FeedbackFormat: "plain"},
+			}, reviewQuestions[0].Options)
+			require.NotNil(t, reviewQuestions[0].Answer)
+			assert.Equal(t, entity.OptionPositions{1}, reviewQuestions[0].Answer.SelectedOptions)
+		})
+	}
+}
+
+func TestSubmitAttemptAnswer(t *testing.T) {
+	var candidateId uint = 2
+	var attemptId uint = 1
+	assessment := newAttemptAssessment(1)
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	type Test struct {
+		TestName               string
+		Deadline               *time.Time
+		FinishedAt             *time.Time
+		UserId                 uint
+		Position               uint
+		SelectedOptions        entity.OptionPositions
+		FinishedMeanwhile      bool
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Success",
+			Deadline:               &future,
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{0},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			// Another request finished the attempt after it was read
+			TestName:               "FinishedMeanwhile",
+			Deadline:               &future,
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{0},
+			FinishedMeanwhile:      true,
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "InvalidOption",
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{2},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "SingleChoiceWithManyOptions",
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{0, 1},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "UnknownQuestion",
+			UserId:                 candidateId,
+			Position:               1,
+			SelectedOptions:        entity.OptionPositions{0},
+			ExpectedHttpStatusCode: http.StatusNotFound,
+		},
+		{
+			TestName:               "TimeLimitExceeded",
+			Deadline:               &past,
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{0},
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "Finished",
+			FinishedAt:             &past,
+			UserId:                 candidateId,
+			SelectedOptions:        entity.OptionPositions{0},
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "NotTheCandidate",
+			UserId:                 3,
+			SelectedOptions:        entity.OptionPositions{0},
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			attemptRepository := mocks.NewAttemptRepository(t)
+			attemptRepository.On("GetAttempt", mock.Anything, attemptId, mock.Anything).
+				Return(entity.Attempt{
+					Id:           attemptId,
+					Deadline:     test.Deadline,
+					FinishedAt:   test.FinishedAt,
+					CandidateId:  candidateId,
+					AssessmentId: assessment.Id,
+				}, nil)
+
+			assessmentRepository := mocks.NewAssessmentRepository(t)
+			attemptAnswerRepository := mocks.NewAttemptAnswerRepository(t)
+			questionStatsRepository := mocks.NewQuestionStatsRepository(t)
+			questionOptionStatsRepository := mocks.NewQuestionOptionStatsRepository(t)
+			if test.TestName == "TimeLimitExceeded" {
+				// The attempt is finished at its deadline
+				attemptRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				attemptRepository.On("LockOpenAttempt", mock.Anything, attemptId).Return(true, nil)
+				attemptRepository.On("FinishAttempt", mock.Anything, attemptId, mock.Anything).
+					Return(func(_ context.Context, _ uint, attempt *entity.Attempt) bool {
+						assert.Equal(t, test.Deadline, attempt.FinishedAt)
+						return true
+					}, nil)
+				assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil)
+				questionStatsRepository.On("IncrementQuestionStats", mock.Anything, mock.Anything).Return(nil)
+				questionOptionStatsRepository.On("IncrementQuestionOptionStats", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			if test.ExpectedHttpStatusCode == http.StatusOK || test.FinishedMeanwhile {
+				attemptRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				attemptRepository.On("LockOpenAttempt", mock.Anything, attemptId).Return(!test.FinishedMeanwhile, nil)
+			}
+			switch {
+			case test.ExpectedHttpStatusCode == http.StatusOK:
+				attemptAnswerRepository.On("SaveAttemptAnswer", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, attemptAnswer *entity.AttemptAnswer) error {
+						assert.Equal(t, attemptId, attemptAnswer.AttemptId)
+						assert.Equal(t, assessment.AssessmentQuestions[0].Id, attemptAnswer.AssessmentQuestionId)
+						assert.Equal(t, test.SelectedOptions, attemptAnswer.SelectedOptions)
+						return nil
+					})
+				fallthrough
+			case test.FinishedMeanwhile, test.ExpectedHttpStatusCode == http.StatusBadRequest, test.ExpectedHttpStatusCode == http.StatusNotFound:
+				assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil)
+			}
+
+			reqBodyBytes, err := json.Marshal(httpserver.SubmitAttemptAnswerRequest{SelectedOptions: test.SelectedOptions})
+			require.NoError(t, err)
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Assessment:          assessmentRepository,
+				Attempt:             attemptRepository,
+				AttemptAnswer:       attemptAnswerRepository,
+				QuestionStats:       questionStatsRepository,
+				QuestionOptionStats: questionOptionStatsRepository,
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/attempts/%d/answers/%d", attemptId, test.Position), bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, test.UserId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestStartAttempt(t *testing.T) {
+	var candidateId uint = 2
+	assessment := entity.Assessment{Id: 1, TimeLimitSeconds: 600}
+
+	assessmentRepository := mocks.NewAssessmentRepository(t)
+	assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id).Return(assessment, nil)
+
+	attemptRepository := mocks.NewAttemptRepository(t)
+	attemptRepository.On("CreateAttempt", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, attempt *entity.Attempt) error {
+			assert.Equal(t, candidateId, attempt.CandidateId)
+			require.NotNil(t, attempt.Deadline)
+			assert.Equal(t, 10*time.Minute, attempt.Deadline.Sub(attempt.StartedAt))
+			attempt.Id = 1
+			return nil
+		})
+
+	reqBodyBytes, err := json.Marshal(httpserver.StartAttemptRequest{AssessmentId: assessment.Id})
+	require.NoError(t, err)
+
+	server := httpserver.NewServer(httpserver.Repositories{Assessment: assessmentRepository, Attempt: attemptRepository}, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/attempts", bytes.NewReader(reqBodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestFinishAttempt(t *testing.T) {
+	var candidateId uint = 2
+	var attemptId uint = 1
+	assessment := newAttemptAssessment(1)
+	assessment.PassingScore = 50
+	attemptAnswer := entity.AttemptAnswer{Id: 5, SelectedOptions: entity.OptionPositions{0}, AttemptId: attemptId, AssessmentQuestionId: 10}
+
+	attemptRepository := mocks.NewAttemptRepository(t)
+	attemptRepository.On("GetAttempt", mock.Anything, attemptId, mock.Anything).
+		Return(entity.Attempt{
+			Id:             attemptId,
+			CandidateId:    candidateId,
+			AssessmentId:   assessment.Id,
+			AttemptAnswers: []entity.AttemptAnswer{attemptAnswer},
+		}, nil)
+	attemptRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	attemptRepository.On("LockOpenAttempt", mock.Anything, attemptId).Return(true, nil)
+	attemptRepository.On("FinishAttempt", mock.Anything, attemptId, mock.Anything).
+		Return(func(_ context.Context, _ uint, attempt *entity.Attempt) bool {
+			require.NotNil(t, attempt.FinishedAt)
+			require.NotNil(t, attempt.Score)
+			assert.Equal(t, 1.0, *attempt.Score)
+			require.NotNil(t, attempt.Passed)
+			assert.True(t, *attempt.Passed)
+			return true
+		}, nil)
+
+	assessmentRepository := mocks.NewAssessmentRepository(t)
+	assessmentRepository.On("GetAssessment", mock.Anything, assessment.Id, mock.Anything).Return(assessment, nil)
+
+	attemptAnswerRepository := mocks.NewAttemptAnswerRepository(t)
+	attemptAnswerRepository.On("UpdateAttemptAnswerScore", mock.Anything, attemptAnswer.Id, 1.0).Return(nil)
+
+	questionStatsRepository := mocks.NewQuestionStatsRepository(t)
+	questionStatsRepository.On("IncrementQuestionStats", mock.Anything, []entity.QuestionStats{{
+		QuestionId:      1,
+		Responses:       1,
+		ScoreSum:        1,
+		ScoreSquaresSum: 1,
+		TotalSum:        1,
+		TotalSquaresSum: 1,
+		ScoreTotalSum:   1,
+	}}).Return(nil)
+
+	questionOptionStatsRepository := mocks.NewQuestionOptionStatsRepository(t)
+	questionOptionStatsRepository.On("IncrementQuestionOptionStats", mock.Anything, []entity.QuestionOptionStats{
+		{QuestionId: 1, Position: 0, Correct: true, Selections: 1, TotalSum: 1},
+		{QuestionId: 1, Position: 1},
+	}).Return(nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Assessment:          assessmentRepository,
+		Attempt:             attemptRepository,
+		AttemptAnswer:       attemptAnswerRepository,
+		QuestionStats:       questionStatsRepository,
+		QuestionOptionStats: questionOptionStatsRepository,
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/attempts/%d/finish", attemptId), nil)
+	req.Header.Set("Authorization", newAuthHeader(t, candidateId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var attempt entity.Attempt
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&attempt))
+	require.NotNil(t, attempt.MaxScore)
+	assert.Equal(t, 1.0, *attempt.MaxScore)
+	require.Len(t, attempt.AttemptAnswers, 1)
+	require.NotNil(t, attempt.AttemptAnswers[0].Score)
+	assert.Equal(t, 1.0, *attempt.AttemptAnswers[0].Score)
+}
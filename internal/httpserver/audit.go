@@ -0,0 +1,118 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type AuditServer struct {
+	auditEntryRepository repository.AuditEntryRepository
+}
+
+// NewAuditServer lists the audit log, only admins can use it
+func NewAuditServer(auditEntryRepository repository.AuditEntryRepository) *fiber.App {
+	server := &AuditServer{auditEntryRepository}
+	app := fiber.New()
+	app.Use(newJwtAuth(), requireAdmin)
+	app.Get("/", server.ListAuditEntries)
+
+	return app
+}
+
+type ListAuditEntriesRequest struct {
+	LastId     *uint      `json:"lastId"`
+	PageSize   uint       `json:"pageSize" validate:"max=1000"`
+	ActorId    uint       `json:"actorId"`
+	EntityType string     `json:"entityType" validate:"omitempty,oneof=question question_translation question_comment"`
+	EntityId   uint       `json:"entityId"`
+	Since      *time.Time `json:"since"` // Inclusive
+	Until      *time.Time `json:"until"` // Exclusive
+}
+
+func (s AuditServer) ListAuditEntries(c *fiber.Ctx) error {
+	var req ListAuditEntriesRequest
+
+	// Validate and parse request
+	if c.Request().Header.ContentLength() > 0 {
+		errRes, valid := validateRequest(c, &req)
+		if !valid {
+			return c.Status(fiber.StatusBadRequest).JSON(errRes)
+		}
+	}
+
+	auditEntries, err := s.auditEntryRepository.ListAuditEntries(
+		c.UserContext(),
+		req.PageSize,
+		req.LastId,
+		repository.AuditEntryFilter{ActorId: req.ActorId, EntityType: req.EntityType, EntityId: req.EntityId, Since: req.Since, Until: req.Until},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list audit entries")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(auditEntries)
+}
+
+type auditContextKey struct{}
+
+// auditContext is who made the request being served, recorded in the audit entries of its changes
+type auditContext struct {
+	ActorId   uint
+	RequestId string
+	Ip        string
+}
+
+// withAuditContext adds the request id and ip of the request to its context
+func withAuditContext(c *fiber.Ctx) error {
+	requestId, _ := c.Locals("requestid").(string)
+	c.SetUserContext(WithAuditContext(c.UserContext(), 0, requestId, c.IP()))
+	return c.Next()
+}
+
+// WithAuditContext adds who made a request served outside of the fiber app to its context,
+// so the audit entries of its changes record them like the ones of the REST requests
+func WithAuditContext(ctx context.Context, actorId uint, requestId string, ip string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, auditContext{ActorId: actorId, RequestId: requestId, Ip: ip})
+}
+
+// withAuditActor adds the auth user to the audit context of the request, once its JWT is verified
+func withAuditActor(c *fiber.Ctx) error {
+	actorId, _ := getAuthUserId(c)
+	audit, _ := c.UserContext().Value(auditContextKey{}).(auditContext)
+	audit.ActorId = actorId
+	c.SetUserContext(context.WithValue(c.UserContext(), auditContextKey{}, audit))
+	return c.Next()
+}
+
+// recordAudit appends an entry for the change of the entity by the user of the request in the context.
+// It must run in the transaction of the change so the entry is only kept when the change is committed
+func recordAudit(ctx context.Context, auditEntryRepository repository.AuditEntryRepository, action string, entityType string, entityId uint, before any, after any) error {
+	beforeBytes, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterBytes, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	audit, _ := ctx.Value(auditContextKey{}).(auditContext)
+	return auditEntryRepository.CreateAuditEntry(ctx, &entity.AuditEntry{
+		ActorId:    audit.ActorId,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		Before:     beforeBytes,
+		After:      afterBytes,
+		RequestId:  audit.RequestId,
+		Ip:         audit.Ip,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
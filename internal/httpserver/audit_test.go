@@ -0,0 +1,128 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditEntryRepository accepts the audit entries of any test that changes questions
+func newAuditEntryRepository(t *testing.T) *mocks.AuditEntryRepository {
+	auditEntryRepository := mocks.NewAuditEntryRepository(t)
+	auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return auditEntryRepository
+}
+
+func TestListAuditEntries(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type Test struct {
+		TestName               string
+		Role                   string
+		Req                    map[string]any
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "List",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"actorId": 2, "entityType": "question", "entityId": 3, "since": since},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "NotAdmin",
+			Role:                   httpserver.RoleReviewer,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+		{
+			TestName:               "UnknownEntityType",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"entityType": "attempt"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			auditEntryRepository := mocks.NewAuditEntryRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				auditEntryRepository.On("ListAuditEntries", mock.Anything, uint(0), (*uint)(nil), mock.Anything).
+					Return(func(_ context.Context, _ uint, _ *uint, opts ...gormprovider.Option) []entity.AuditEntry {
+						require.Len(t, opts, 1)
+						assert.Equal(t, repository.AuditEntryFilter{ActorId: 2, EntityType: "question", EntityId: 3, Since: &since}, opts[0])
+						return []entity.AuditEntry{}
+					}, nil)
+			}
+
+			var reqBody []byte
+			if test.Req != nil {
+				var err error
+				reqBody, err = json.Marshal(test.Req)
+				require.NoError(t, err)
+			}
+			server := httpserver.NewServer(httpserver.Repositories{AuditEntry: auditEntryRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, "/audit", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, 1, test.Role))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestQuestionAudit(t *testing.T) {
+	question := entity.Question{Id: 1, Body: "Which?", AuthorId: 7, Revision: 1}
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).
+		Return(question, nil)
+	questionRepository.On("DeleteQuestion", mock.Anything, uint(1)).Return(nil)
+
+	auditEntries := []entity.AuditEntry{}
+	auditEntryRepository := mocks.NewAuditEntryRepository(t)
+	auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, auditEntry *entity.AuditEntry) error {
+			auditEntries = append(auditEntries, *auditEntry)
+			return nil
+		})
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:   questionRepository,
+		Outbox:     newOutboxRepository(t),
+		AuditEntry: auditEntryRepository,
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodDelete, "/questions/1", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, 7, ""))
+	req.Header.Set("X-Request-ID", "request-1")
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.Len(t, auditEntries, 1)
+	auditEntry := auditEntries[0]
+	assert.Equal(t, uint(7), auditEntry.ActorId)
+	assert.Equal(t, entity.AuditActionDelete, auditEntry.Action)
+	assert.Equal(t, entity.AuditEntityQuestion, auditEntry.EntityType)
+	assert.Equal(t, uint(1), auditEntry.EntityId)
+	assert.Equal(t, "request-1", auditEntry.RequestId)
+	assert.NotEmpty(t, auditEntry.Ip)
+	var before entity.Question
+	require.NoError(t, json.Unmarshal(auditEntry.Before, &before))
+	assert.Equal(t, "Which?", before.Body)
+	assert.Equal(t, "null", string(auditEntry.After))
+}
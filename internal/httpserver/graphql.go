@@ -0,0 +1,181 @@
+package httpserver
+
+import (
+	"challenge/internal/eventbus"
+	"challenge/internal/repository"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed graphql.graphql
+var graphqlSchema string
+
+// graphqlMaxDepth is deep enough to fetch the options of the questions of the author of a question
+const graphqlMaxDepth = 10
+
+// graphqlMaxQuestions bounds the questions listed by all the connections of a request, since nested connections
+// like author.questions multiply the pages of their parents
+const graphqlMaxQuestions = 2000
+
+type GraphQLServer struct {
+	schema   *graphql.Schema
+	resolver *graphqlResolver
+}
+
+// NewGraphQLServer serves a GraphQL schema over the question library. Reading is public like the
+// question routes, mutations need a JWT and go through the same checks as the REST handlers
+func NewGraphQLServer(
+	questionRepository repository.QuestionRepository,
+	questionOptionRepository repository.QuestionOptionRepository,
+	questionTagRepository repository.QuestionTagRepository,
+	attachmentRepository repository.AttachmentRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+	outboxRepository repository.OutboxRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+	questionStatsRepository repository.QuestionStatsRepository,
+	questionEvents *eventbus.Bus,
+) *fiber.App {
+	writer := NewQuestionWriter(
+		questionRepository,
+		questionOptionRepository,
+		questionTagRepository,
+		attachmentRepository,
+		questionTranslationRepository,
+		outboxRepository,
+		auditEntryRepository,
+		questionEvents,
+	)
+	resolver := &graphqlResolver{
+		questions:                writer.questions,
+		writer:                   writer,
+		questionOptionRepository: questionOptionRepository,
+		questionTagRepository:    questionTagRepository,
+		questionStatsRepository:  questionStatsRepository,
+	}
+	server := &GraphQLServer{
+		schema:   graphql.MustParseSchema(graphqlSchema, resolver, graphql.MaxDepth(graphqlMaxDepth)),
+		resolver: resolver,
+	}
+	app := fiber.New()
+	app.Post("/", newOptionalJwtAuth(), server.ExecuteGraphQL)
+
+	return app
+}
+
+type GraphQLRequest struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ExecuteGraphQL answers with status 200 once the request is parsed, failures are in the errors of the response
+func (s GraphQLServer) ExecuteGraphQL(c *fiber.Ctx) error {
+	var req GraphQLRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Loaders batch the queries of a single request, they are not shared so nothing is cached across requests
+	ctx := context.WithValue(c.UserContext(), graphqlUserContextKey{}, graphqlUser{Id: userId, Role: getAuthUserRole(c)})
+	ctx = context.WithValue(ctx, graphqlLoadersContextKey{}, s.resolver.newLoaders())
+	ctx = context.WithValue(ctx, graphqlBudgetContextKey{}, &graphqlBudget{remaining: graphqlMaxQuestions})
+
+	return c.JSON(s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+type graphqlUserContextKey struct{}
+
+// graphqlUser is who made the request, Id is 0 for anonymous requests
+type graphqlUser struct {
+	Id   uint
+	Role string
+}
+
+func graphqlUserFromContext(ctx context.Context) graphqlUser {
+	user, _ := ctx.Value(graphqlUserContextKey{}).(graphqlUser)
+	return user
+}
+
+type graphqlBudgetContextKey struct{}
+
+// graphqlBudget counts down the questions a request can still list, connections are resolved concurrently
+type graphqlBudget struct {
+	mux       sync.Mutex
+	remaining uint
+}
+
+func graphqlBudgetFromContext(ctx context.Context) *graphqlBudget {
+	return ctx.Value(graphqlBudgetContextKey{}).(*graphqlBudget)
+}
+
+// reserve takes a page of questions from the budget before it is listed, failing once the budget is spent
+func (b *graphqlBudget) reserve(pageSize uint) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if pageSize > b.remaining {
+		b.remaining = 0
+		return newGraphQLError(fiber.StatusBadRequest, ErrorResponse{
+			Error: fmt.Sprintf("query lists more than %d questions", graphqlMaxQuestions),
+		})
+	}
+	b.remaining -= pageSize
+	return nil
+}
+
+// graphqlError is a resolver error with the kind of failure as its code extension
+type graphqlError struct {
+	message    string
+	extensions map[string]any
+}
+
+func (e graphqlError) Error() string {
+	return e.message
+}
+
+func (e graphqlError) Extensions() map[string]any {
+	return e.extensions
+}
+
+// newGraphQLError turns the status and error response of the REST helpers into a resolver error
+func newGraphQLError(status int, errRes any) error {
+	code := "INTERNAL"
+	switch status {
+	case fiber.StatusBadRequest, fiber.StatusUnprocessableEntity:
+		code = "BAD_USER_INPUT"
+	case fiber.StatusUnauthorized:
+		code = "UNAUTHORIZED"
+	case fiber.StatusNotFound:
+		code = "NOT_FOUND"
+	}
+
+	err := graphqlError{message: "Internal error", extensions: map[string]any{"code": code}}
+	switch errRes := errRes.(type) {
+	case ErrorResponse:
+		err.message = errRes.Error
+	case ValidationErrorResponse:
+		err.message = "Validation failed"
+		err.extensions["errors"] = errRes.Errors
+	}
+	return err
+}
+
+// newGraphQLWriteError turns a failed change of the question writer into a resolver error
+func newGraphQLWriteError(err error) error {
+	var writeErr QuestionWriteError
+	if errors.As(err, &writeErr) {
+		return newGraphQLError(writeErr.Status, writeErr.Response)
+	}
+	return newGraphQLError(fiber.StatusInternalServerError, nil)
+}
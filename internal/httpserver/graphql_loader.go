@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"context"
+	"sync"
+)
+
+type graphqlLoadersContextKey struct{}
+
+// graphqlLoaders load the associations of the questions of a request in one query per association
+type graphqlLoaders struct {
+	options *batchLoader[uint, []entity.QuestionOption]
+	tags    *batchLoader[uint, []entity.QuestionTag]
+	stats   *batchLoader[uint, []entity.QuestionStats]
+}
+
+func (r *graphqlResolver) newLoaders() *graphqlLoaders {
+	return &graphqlLoaders{
+		options: newBatchLoader(func(ctx context.Context, questionIds []uint) (map[uint][]entity.QuestionOption, error) {
+			questionOptions, err := r.questionOptionRepository.ListQuestionOptions(ctx, questionIds)
+			return groupByQuestionId(questionOptions, func(questionOption entity.QuestionOption) uint { return questionOption.QuestionId }), err
+		}),
+		tags: newBatchLoader(func(ctx context.Context, questionIds []uint) (map[uint][]entity.QuestionTag, error) {
+			questionTags, err := r.questionTagRepository.ListQuestionTags(ctx, questionIds)
+			return groupByQuestionId(questionTags, func(questionTag entity.QuestionTag) uint { return questionTag.QuestionId }), err
+		}),
+		stats: newBatchLoader(func(ctx context.Context, questionIds []uint) (map[uint][]entity.QuestionStats, error) {
+			questionStats, err := r.questionStatsRepository.ListQuestionStats(ctx, questionIds)
+			return groupByQuestionId(questionStats, func(questionStats entity.QuestionStats) uint { return questionStats.QuestionId }), err
+		}),
+	}
+}
+
+func graphqlLoadersFromContext(ctx context.Context) *graphqlLoaders {
+	return ctx.Value(graphqlLoadersContextKey{}).(*graphqlLoaders)
+}
+
+// register announces the questions about to be resolved, so their associations are loaded together
+func (l *graphqlLoaders) register(questions []entity.Question) {
+	questionIds := make([]uint, len(questions))
+	for i, question := range questions {
+		questionIds[i] = question.Id
+	}
+	l.options.register(questionIds...)
+	l.tags.register(questionIds...)
+	l.stats.register(questionIds...)
+}
+
+// batchLoader loads the values of many keys with one fetch. Keys are registered as their parents are
+// resolved, so the first load fetches every key registered so far instead of one key per parent
+type batchLoader[K comparable, V any] struct {
+	mux     sync.Mutex
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	pending []K
+	values  map[K]V
+}
+
+func newBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{fetch: fetch, values: map[K]V{}}
+}
+
+func (l *batchLoader[K, V]) register(keys ...K) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for _, key := range keys {
+		if _, ok := l.values[key]; !ok && !contains(l.pending, key) {
+			l.pending = append(l.pending, key)
+		}
+	}
+}
+
+// load returns the value of the key, the zero value when the fetch has none
+func (l *batchLoader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if value, ok := l.values[key]; ok {
+		return value, nil
+	}
+
+	keys := l.pending
+	l.pending = nil
+	if !contains(keys, key) {
+		keys = append(keys, key)
+	}
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	for _, key := range keys {
+		l.values[key] = values[key]
+	}
+	return l.values[key], nil
+}
+
+func groupByQuestionId[V any](values []V, questionId func(V) uint) map[uint][]V {
+	groups := map[uint][]V{}
+	for _, value := range values {
+		groups[questionId(value)] = append(groups[questionId(value)], value)
+	}
+	return groups
+}
+
+func contains[K comparable](keys []K, key K) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,571 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/stats"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const graphqlDefaultPageSize = 10
+
+// graphqlResolver resolves the queries and mutations of the schema, reusing the question helpers of the REST handlers
+type graphqlResolver struct {
+	questions                QuestionServer
+	writer                   *QuestionWriter
+	questionOptionRepository repository.QuestionOptionRepository
+	questionTagRepository    repository.QuestionTagRepository
+	questionStatsRepository  repository.QuestionStatsRepository
+}
+
+type questionsArgs struct {
+	First      *int32
+	After      *graphql.ID
+	AuthorId   *graphql.ID
+	Difficulty *string
+	Tags       *[]string
+}
+
+func (r *graphqlResolver) Questions(ctx context.Context, args questionsArgs) (*questionConnectionResolver, error) {
+	req := ListQuestionsRequest{}
+	if args.AuthorId != nil {
+		authorId, err := parseGraphQLID(*args.AuthorId)
+		if err != nil {
+			return nil, err
+		}
+		req.AuthorId = &authorId
+	}
+	return r.listQuestions(ctx, req, args.First, args.After, args.Difficulty, args.Tags)
+}
+
+type questionArgs struct {
+	Id graphql.ID
+}
+
+func (r *graphqlResolver) Question(ctx context.Context, args questionArgs) (*questionResolver, error) {
+	id, err := parseGraphQLID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := r.questions.questionRepository.GetQuestion(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get question")
+		return nil, newGraphQLError(fiber.StatusInternalServerError, nil)
+	}
+
+	graphqlLoadersFromContext(ctx).register([]entity.Question{question})
+	return &questionResolver{r, question, false}, nil
+}
+
+func (r *graphqlResolver) Author(args questionArgs) (*authorResolver, error) {
+	id, err := parseGraphQLID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &authorResolver{r, id}, nil
+}
+
+// listQuestions validates the page like the REST list and fetches one more question to know if there is a next page
+func (r *graphqlResolver) listQuestions(
+	ctx context.Context,
+	req ListQuestionsRequest,
+	first *int32,
+	after *graphql.ID,
+	difficulty *string,
+	tags *[]string,
+) (*questionConnectionResolver, error) {
+	req.PageSize = graphqlDefaultPageSize
+	if first != nil {
+		if *first < 1 {
+			return nil, newGraphQLError(fiber.StatusBadRequest, ErrorResponse{Error: "first must be positive"})
+		}
+		req.PageSize = uint(*first)
+	}
+	if after != nil {
+		lastId, err := parseGraphQLID(*after)
+		if err != nil {
+			return nil, err
+		}
+		req.LastId = &lastId
+	}
+	if difficulty != nil {
+		req.Difficulty = *difficulty
+	}
+	if tags != nil {
+		req.Tags = *tags
+	}
+	if errRes, valid := validateStruct(req); !valid {
+		return nil, newGraphQLError(fiber.StatusBadRequest, errRes)
+	}
+	if err := graphqlBudgetFromContext(ctx).reserve(req.PageSize); err != nil {
+		return nil, err
+	}
+
+	questionFilter := repository.QuestionFilter{Difficulty: req.Difficulty, Tags: req.Tags}
+	if req.AuthorId != nil {
+		questionFilter.AuthorId = *req.AuthorId
+	}
+	questions, err := r.questions.questionRepository.ListQuestions(ctx, req.PageSize+1, req.LastId, questionFilter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list questions")
+		return nil, newGraphQLError(fiber.StatusInternalServerError, nil)
+	}
+
+	connection := &questionConnectionResolver{}
+	if uint(len(questions)) > req.PageSize {
+		questions = questions[:req.PageSize]
+		connection.hasNextPage = true
+	}
+	graphqlLoadersFromContext(ctx).register(questions)
+	for _, question := range questions {
+		connection.edges = append(connection.edges, &questionEdgeResolver{&questionResolver{r, question, false}})
+	}
+	return connection, nil
+}
+
+type questionInput struct {
+	Type              *string
+	Body              string
+	BodyFormat        *string
+	Explanation       *string
+	ExplanationFormat *string
+	Language          *string
+	Difficulty        *string
+	DurationSeconds   *int32
+	NumericAnswer     *float64
+	NumericTolerance  *float64
+	Options           *[]questionOptionInput
+	Tags              *[]string
+	AttachmentId      *graphql.ID
+}
+
+type questionOptionInput struct {
+	Id             *graphql.ID
+	Body           string
+	BodyFormat     *string
+	Feedback       *string
+	FeedbackFormat *string
+	Correct        *bool
+	AttachmentId   *graphql.ID
+}
+
+// question returns the question of the input as the REST handlers parse it, before validating it
+func (i questionInput) question() (entity.Question, error) {
+	question := entity.Question{
+		Type:              valueOrZero(i.Type),
+		Body:              i.Body,
+		BodyFormat:        valueOrZero(i.BodyFormat),
+		Explanation:       valueOrZero(i.Explanation),
+		ExplanationFormat: valueOrZero(i.ExplanationFormat),
+		Language:          valueOrZero(i.Language),
+		Difficulty:        valueOrZero(i.Difficulty),
+		NumericAnswer:     i.NumericAnswer,
+		NumericTolerance:  valueOrZero(i.NumericTolerance),
+	}
+	if i.DurationSeconds != nil {
+		if *i.DurationSeconds < 0 {
+			return entity.Question{}, newGraphQLError(fiber.StatusBadRequest, ErrorResponse{Error: "durationSeconds can't be negative"})
+		}
+		question.DurationSeconds = uint(*i.DurationSeconds)
+	}
+	attachmentId, err := parseOptionalGraphQLID(i.AttachmentId)
+	if err != nil {
+		return entity.Question{}, err
+	}
+	question.AttachmentId = attachmentId
+	if i.Tags != nil {
+		question.Tags = []entity.QuestionTag{}
+		for _, tag := range *i.Tags {
+			question.Tags = append(question.Tags, entity.QuestionTag{Name: tag})
+		}
+	}
+	if i.Options == nil {
+		return question, nil
+	}
+
+	question.QuestionOptions = []entity.QuestionOption{}
+	for _, optionInput := range *i.Options {
+		questionOption := entity.QuestionOption{
+			Body:           optionInput.Body,
+			BodyFormat:     valueOrZero(optionInput.BodyFormat),
+			Feedback:       valueOrZero(optionInput.Feedback),
+			FeedbackFormat: valueOrZero(optionInput.FeedbackFormat),
+			Correct:        optionInput.Correct,
+		}
+		id, err := parseOptionalGraphQLID(optionInput.Id)
+		if err != nil {
+			return entity.Question{}, err
+		}
+		if id != nil {
+			questionOption.Id = *id
+		}
+		questionOption.AttachmentId, err = parseOptionalGraphQLID(optionInput.AttachmentId)
+		if err != nil {
+			return entity.Question{}, err
+		}
+		question.QuestionOptions = append(question.QuestionOptions, questionOption)
+	}
+	return question, nil
+}
+
+type createQuestionArgs struct {
+	Input questionInput
+}
+
+// CreateQuestion checks the question like POST /questions does
+func (r *graphqlResolver) CreateQuestion(ctx context.Context, args createQuestionArgs) (*questionResolver, error) {
+	user := graphqlUserFromContext(ctx)
+	if user.Id == 0 {
+		return nil, newGraphQLError(fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	question, err := args.Input.question()
+	if err != nil {
+		return nil, err
+	}
+	err = r.writer.CreateQuestion(ctx, user.Id, &question)
+	if err != nil {
+		return nil, newGraphQLWriteError(err)
+	}
+
+	return &questionResolver{r, question, true}, nil
+}
+
+type updateQuestionArgs struct {
+	Id    graphql.ID
+	Input questionInput
+}
+
+// UpdateQuestion checks the question and its author like PUT /questions/:id does
+func (r *graphqlResolver) UpdateQuestion(ctx context.Context, args updateQuestionArgs) (*questionResolver, error) {
+	user := graphqlUserFromContext(ctx)
+	if user.Id == 0 {
+		return nil, newGraphQLError(fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+	id, err := parseGraphQLID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	questionUpdate, err := args.Input.question()
+	if err != nil {
+		return nil, err
+	}
+	err = r.writer.UpdateQuestion(ctx, id, user.Id, &questionUpdate)
+	if err != nil {
+		return nil, newGraphQLWriteError(err)
+	}
+
+	return &questionResolver{r, questionUpdate, true}, nil
+}
+
+type deleteQuestionArgs struct {
+	Id graphql.ID
+}
+
+// DeleteQuestion deletes like DELETE /questions/:id does
+func (r *graphqlResolver) DeleteQuestion(ctx context.Context, args deleteQuestionArgs) (bool, error) {
+	if graphqlUserFromContext(ctx).Id == 0 {
+		return false, newGraphQLError(fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+	id, err := parseGraphQLID(args.Id)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.writer.DeleteQuestion(ctx, id, graphqlUserFromContext(ctx).Id)
+	if err != nil {
+		return false, newGraphQLWriteError(err)
+	}
+	return true, nil
+}
+
+type questionConnectionResolver struct {
+	edges       []*questionEdgeResolver
+	hasNextPage bool
+}
+
+func (c *questionConnectionResolver) Edges() []*questionEdgeResolver {
+	return c.edges
+}
+
+func (c *questionConnectionResolver) PageInfo() *pageInfoResolver {
+	pageInfo := &pageInfoResolver{hasNextPage: c.hasNextPage}
+	if len(c.edges) > 0 {
+		endCursor := c.edges[len(c.edges)-1].Cursor()
+		pageInfo.endCursor = &endCursor
+	}
+	return pageInfo
+}
+
+type questionEdgeResolver struct {
+	node *questionResolver
+}
+
+func (e *questionEdgeResolver) Cursor() graphql.ID {
+	return e.node.ID()
+}
+
+func (e *questionEdgeResolver) Node() *questionResolver {
+	return e.node
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *graphql.ID
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *graphql.ID {
+	return p.endCursor
+}
+
+type questionResolver struct {
+	resolver *graphqlResolver
+	question entity.Question
+	loaded   bool // Whether the question already has its options and tags, like the questions returned by mutations
+}
+
+func (q *questionResolver) ID() graphql.ID {
+	return formatGraphQLID(q.question.Id)
+}
+
+func (q *questionResolver) Type() string {
+	return q.question.GetType()
+}
+
+func (q *questionResolver) Body() string {
+	return q.question.Body
+}
+
+func (q *questionResolver) BodyFormat() string {
+	return q.question.GetBodyFormat()
+}
+
+// Explanation is empty for the users who can't review the question, like the REST API returns it
+func (q *questionResolver) Explanation(ctx context.Context) string {
+	user := graphqlUserFromContext(ctx)
+	if !canReviewQuestion(q.question, user.Id, user.Role) {
+		return ""
+	}
+	return q.question.Explanation
+}
+
+func (q *questionResolver) ExplanationFormat() string {
+	return q.question.GetExplanationFormat()
+}
+
+func (q *questionResolver) Language() string {
+	return q.question.GetLanguage()
+}
+
+func (q *questionResolver) Difficulty() string {
+	return q.question.Difficulty
+}
+
+func (q *questionResolver) DurationSeconds() int32 {
+	return int32(q.question.DurationSeconds)
+}
+
+func (q *questionResolver) NumericAnswer() *float64 {
+	return q.question.NumericAnswer
+}
+
+func (q *questionResolver) NumericTolerance() float64 {
+	return q.question.NumericTolerance
+}
+
+func (q *questionResolver) AttachmentId() *graphql.ID {
+	return formatOptionalGraphQLID(q.question.AttachmentId)
+}
+
+func (q *questionResolver) Revision() int32 {
+	return int32(q.question.Revision)
+}
+
+func (q *questionResolver) Author() *authorResolver {
+	return &authorResolver{q.resolver, q.question.AuthorId}
+}
+
+func (q *questionResolver) Tags(ctx context.Context) ([]string, error) {
+	questionTags := q.question.Tags
+	if !q.loaded {
+		var err error
+		questionTags, err = graphqlLoadersFromContext(ctx).tags.load(ctx, q.question.Id)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list question tags")
+			return nil, newGraphQLError(fiber.StatusInternalServerError, nil)
+		}
+	}
+
+	tags := []string{}
+	for _, questionTag := range questionTags {
+		tags = append(tags, questionTag.Name)
+	}
+	return tags, nil
+}
+
+func (q *questionResolver) Options(ctx context.Context) ([]*questionOptionResolver, error) {
+	questionOptions := q.question.QuestionOptions
+	if !q.loaded {
+		var err error
+		questionOptions, err = graphqlLoadersFromContext(ctx).options.load(ctx, q.question.Id)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list question options")
+			return nil, newGraphQLError(fiber.StatusInternalServerError, nil)
+		}
+	}
+
+	user := graphqlUserFromContext(ctx)
+	reviewable := canReviewQuestion(q.question, user.Id, user.Role)
+	options := []*questionOptionResolver{}
+	for _, questionOption := range questionOptions {
+		if !reviewable {
+			questionOption.Feedback = ""
+		}
+		options = append(options, &questionOptionResolver{questionOption})
+	}
+	return options, nil
+}
+
+// Stats is checked like GET /questions/:id/stats, revisions nobody answered yet have empty stats
+func (q *questionResolver) Stats(ctx context.Context) (*questionStatsResolver, error) {
+	user := graphqlUserFromContext(ctx)
+	if !canReviewQuestion(q.question, user.Id, user.Role) {
+		return nil, newGraphQLError(fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	revisionsStats, err := graphqlLoadersFromContext(ctx).stats.load(ctx, q.question.Id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list question stats")
+		return nil, newGraphQLError(fiber.StatusInternalServerError, nil)
+	}
+	questionStats := entity.QuestionStats{QuestionId: q.question.Id, QuestionRevision: q.question.Revision}
+	for _, revisionStats := range revisionsStats {
+		if revisionStats.QuestionRevision == q.question.Revision {
+			questionStats = revisionStats
+		}
+	}
+	return &questionStatsResolver{stats.Analyze(questionStats, nil)}, nil
+}
+
+type questionOptionResolver struct {
+	questionOption entity.QuestionOption
+}
+
+func (o *questionOptionResolver) ID() graphql.ID {
+	return formatGraphQLID(o.questionOption.Id)
+}
+
+func (o *questionOptionResolver) Body() string {
+	return o.questionOption.Body
+}
+
+func (o *questionOptionResolver) BodyFormat() string {
+	return o.questionOption.GetBodyFormat()
+}
+
+func (o *questionOptionResolver) Feedback() string {
+	return o.questionOption.Feedback
+}
+
+func (o *questionOptionResolver) FeedbackFormat() string {
+	return o.questionOption.GetFeedbackFormat()
+}
+
+func (o *questionOptionResolver) Correct() bool {
+	return o.questionOption.Correct != nil && *o.questionOption.Correct
+}
+
+func (o *questionOptionResolver) AttachmentId() *graphql.ID {
+	return formatOptionalGraphQLID(o.questionOption.AttachmentId)
+}
+
+type questionStatsResolver struct {
+	report stats.QuestionReport
+}
+
+func (s *questionStatsResolver) Responses() int32 {
+	return int32(s.report.Responses)
+}
+
+func (s *questionStatsResolver) Difficulty() float64 {
+	return s.report.Difficulty
+}
+
+func (s *questionStatsResolver) Discrimination() *float64 {
+	return s.report.Discrimination
+}
+
+type authorResolver struct {
+	resolver *graphqlResolver
+	id       uint
+}
+
+func (a *authorResolver) ID() graphql.ID {
+	return formatGraphQLID(a.id)
+}
+
+type authorQuestionsArgs struct {
+	First      *int32
+	After      *graphql.ID
+	Difficulty *string
+	Tags       *[]string
+}
+
+func (a *authorResolver) Questions(ctx context.Context, args authorQuestionsArgs) (*questionConnectionResolver, error) {
+	return a.resolver.listQuestions(ctx, ListQuestionsRequest{AuthorId: &a.id}, args.First, args.After, args.Difficulty, args.Tags)
+}
+
+func parseGraphQLID(id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, newGraphQLError(fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"})
+	}
+	return uint(value), nil
+}
+
+func parseOptionalGraphQLID(id *graphql.ID) (*uint, error) {
+	if id == nil {
+		return nil, nil
+	}
+	value, err := parseGraphQLID(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func formatGraphQLID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+func formatOptionalGraphQLID(id *uint) *graphql.ID {
+	if id == nil {
+		return nil
+	}
+	value := formatGraphQLID(*id)
+	return &value
+}
+
+func valueOrZero[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
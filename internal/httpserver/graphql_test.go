@@ -0,0 +1,219 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/internal/repository"
+	"challenge/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlResponse struct {
+	Data   map[string]any
+	Errors []struct {
+		Message    string
+		Path       []any
+		Extensions map[string]any
+	}
+}
+
+// execGraphQL runs the query as the user, anonymously when authHeader is empty
+func execGraphQL(t *testing.T, server *fiber.App, authHeader string, query string, variables map[string]any) graphqlResponse {
+	reqBody, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var graphqlRes graphqlResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&graphqlRes))
+	return graphqlRes
+}
+
+func TestGraphQLQuestions(t *testing.T) {
+	correct := true
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(3), (*uint)(nil), repository.QuestionFilter{Tags: []string{"go"}}).
+		Return([]entity.Question{
+			{Id: 1, Body: "One?", Explanation: "First", AuthorId: 1, Revision: 2},
+			{Id: 2, Body: "Two?", Explanation: "Second", AuthorId: 2},
+			{Id: 3, AuthorId: 2},
+		}, nil)
+
+	// The associations of the whole page are loaded at once
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("ListQuestionOptions", mock.Anything, []uint{1, 2}).
+		Return([]entity.QuestionOption{{Id: 1, Body: "this", Feedback: "yes", Correct: &correct, QuestionId: 1}, {Id: 2, Body: "that", Feedback: "no", QuestionId: 2}}, nil).
+		Once()
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("ListQuestionTags", mock.Anything, []uint{1, 2}).
+		Return([]entity.QuestionTag{{Name: "go", QuestionId: 1}, {Name: "go", QuestionId: 2}}, nil).
+		Once()
+	questionStatsRepository := mocks.NewQuestionStatsRepository(t)
+	questionStatsRepository.On("ListQuestionStats", mock.Anything, []uint{1, 2}).
+		Return([]entity.QuestionStats{{QuestionId: 1, QuestionRevision: 1, Responses: 4}, {QuestionId: 1, QuestionRevision: 2, Responses: 2, ScoreSum: 1}}, nil).
+		Once()
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		QuestionStats:  questionStatsRepository,
+	}, nil, nil)
+	res := execGraphQL(t, server, newAuthHeader(t, 1, ""), `query($tags: [String!]) {
+		questions(first: 2, tags: $tags) {
+			edges { cursor node { id body explanation author { id } tags options { id body feedback correct } stats { responses difficulty } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`, map[string]any{"tags": []string{"go"}})
+
+	// Only the author sees the stats, explanation and feedback of their questions
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, []any{"questions", "edges", float64(1), "node", "stats"}, res.Errors[0].Path)
+	assert.Equal(t, "UNAUTHORIZED", res.Errors[0].Extensions["code"])
+	expected := `{
+		"questions": {
+			"edges": [
+				{"cursor": "1", "node": {"id": "1", "body": "One?", "explanation": "First", "author": {"id": "1"}, "tags": ["go"], "options": [{"id": "1", "body": "this", "feedback": "yes", "correct": true}], "stats": {"responses": 2, "difficulty": 0.5}}},
+				{"cursor": "2", "node": {"id": "2", "body": "Two?", "explanation": "", "author": {"id": "2"}, "tags": ["go"], "options": [{"id": "2", "body": "that", "feedback": "", "correct": false}], "stats": null}}
+			],
+			"pageInfo": {"hasNextPage": true, "endCursor": "2"}
+		}
+	}`
+	data, err := json.Marshal(res.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestGraphQLQuestionsBudget(t *testing.T) {
+	page := make([]entity.Question, 1000)
+	for i := range page {
+		page[i] = entity.Question{Id: uint(i + 1), AuthorId: uint(i + 1)}
+	}
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(1001), (*uint)(nil), repository.QuestionFilter{}).
+		Return(page, nil).
+		Once()
+	// The first nested page spends the rest of the budget, the others are rejected without listing them
+	questionRepository.On("ListQuestions", mock.Anything, uint(1001), (*uint)(nil), mock.Anything).
+		Return([]entity.Question{}, nil).
+		Once()
+
+	server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+	res := execGraphQL(t, server, "", `{
+		questions(first: 1000) { edges { node { author { questions(first: 1000) { edges { node { id } } } } } } }
+	}`, nil)
+
+	require.Len(t, res.Errors, len(page)-1)
+	for _, err := range res.Errors {
+		assert.Equal(t, "BAD_USER_INPUT", err.Extensions["code"])
+		assert.Equal(t, "query lists more than 2000 questions", err.Message)
+	}
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	createQuestion := `mutation {
+		createQuestion(input: {body: "Which?", options: [{body: "this", correct: true}, {body: "that", correct: false}], tags: ["go"]}) {
+			id author { id } tags options { id }
+		}
+	}`
+	type Test struct {
+		TestName     string
+		AuthHeader   string
+		Query        string
+		ExpectedCode string
+		ExpectedData string
+		SetupMocks   func(questionRepository *mocks.QuestionRepository)
+	}
+	tests := []Test{
+		{
+			TestName:     "CreateAnonymous",
+			Query:        createQuestion,
+			ExpectedCode: "UNAUTHORIZED",
+		},
+		{
+			TestName:     "CreateInvalid",
+			AuthHeader:   newAuthHeader(t, 1, ""),
+			Query:        `mutation { createQuestion(input: {body: "Which?", difficulty: "impossible", options: []}) { id } }`,
+			ExpectedCode: "BAD_USER_INPUT",
+		},
+		{
+			TestName:     "Create",
+			AuthHeader:   newAuthHeader(t, 1, ""),
+			Query:        createQuestion,
+			ExpectedData: `{"createQuestion": {"id": "1", "author": {"id": "1"}, "tags": ["go"], "options": [{"id": "0"}, {"id": "0"}]}}`,
+			SetupMocks: func(questionRepository *mocks.QuestionRepository) {
+				questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, question *entity.Question) error {
+						question.Id = 1
+						return nil
+					})
+			},
+		},
+		{
+			TestName:     "UpdateNotAuthor",
+			AuthHeader:   newAuthHeader(t, 2, ""),
+			Query:        `mutation { updateQuestion(id: "1", input: {body: "Which?", options: [{body: "this", correct: true}, {body: "that", correct: false}]}) { id } }`,
+			ExpectedCode: "UNAUTHORIZED",
+			SetupMocks: func(questionRepository *mocks.QuestionRepository) {
+				questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything).Return(entity.Question{Id: 1, AuthorId: 1}, nil)
+			},
+		},
+		{
+			TestName:     "DeleteInvalidId",
+			AuthHeader:   newAuthHeader(t, 1, ""),
+			Query:        `mutation { deleteQuestion(id: "one") }`,
+			ExpectedCode: "BAD_USER_INPUT",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.SetupMocks != nil {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					}).
+					Maybe()
+				questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+				questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+				test.SetupMocks(questionRepository)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			res := execGraphQL(t, server, test.AuthHeader, test.Query, nil)
+			if test.ExpectedCode != "" {
+				require.Len(t, res.Errors, 1)
+				assert.Equal(t, test.ExpectedCode, res.Errors[0].Extensions["code"])
+				return
+			}
+			require.Empty(t, res.Errors)
+			data, err := json.Marshal(res.Data)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.ExpectedData, string(data))
+		})
+	}
+}
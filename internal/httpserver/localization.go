@@ -0,0 +1,127 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/text/language"
+)
+
+// translationsChunkSize keeps the question ids of a translations query under the sqlite variable limit
+const translationsChunkSize = 500
+
+// parseLanguages reads the languages asked by the client, the lang query param first and then
+// the Accept-Language header. No languages means the canonical bodies
+func parseLanguages(c *fiber.Ctx) ([]language.Tag, any) {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	if lang := c.Query("lang"); lang != "" {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, ErrorResponse{Error: "lang is invalid"}
+		}
+		return []language.Tag{tag}, nil
+	}
+
+	// A malformed header is ignored, as if no language was asked
+	tags, _, err := language.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if err != nil {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// normalizeLanguage returns the canonical form of a language tag, so equal tags compare equal
+func normalizeLanguage(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang
+	}
+	return tag.String()
+}
+
+// translateQuestions replaces the bodies of every question with its translation that best matches
+// the languages, keeping the canonical bodies when the canonical language matches better or nothing matches.
+// Options without a translation keep their canonical body, and empty translated explanations and feedbacks
+// keep the canonical ones
+func translateQuestions(ctx context.Context, questionTranslationRepository repository.QuestionTranslationRepository, questions []entity.Question, langs []language.Tag) error {
+	if len(langs) == 0 || len(questions) == 0 {
+		return nil
+	}
+
+	questionIds := make([]uint, len(questions))
+	for i, question := range questions {
+		questionIds[i] = question.Id
+	}
+	questionTranslations, err := listQuestionTranslations(ctx, questionTranslationRepository, questionIds)
+	if err != nil {
+		return err
+	}
+	translationsByQuestion := map[uint][]entity.QuestionTranslation{}
+	for _, questionTranslation := range questionTranslations {
+		translationsByQuestion[questionTranslation.QuestionId] = append(translationsByQuestion[questionTranslation.QuestionId], questionTranslation)
+	}
+
+	for i := range questions {
+		translations := translationsByQuestion[questions[i].Id]
+		if len(translations) == 0 {
+			continue
+		}
+
+		// The canonical language goes first, it is the fallback of the matcher
+		available := []language.Tag{language.Make(questions[i].GetLanguage())}
+		for _, questionTranslation := range translations {
+			available = append(available, language.Make(questionTranslation.Language))
+		}
+		_, index, confidence := language.NewMatcher(available).Match(langs...)
+		if index == 0 || confidence == language.No {
+			continue
+		}
+		applyQuestionTranslation(&questions[i], translations[index-1])
+	}
+	return nil
+}
+
+func applyQuestionTranslation(question *entity.Question, questionTranslation entity.QuestionTranslation) {
+	question.Translation = questionTranslation.Language
+	question.Body = questionTranslation.Body
+	question.BodyFormat = questionTranslation.GetBodyFormat()
+	if questionTranslation.Explanation != "" {
+		question.Explanation = questionTranslation.Explanation
+		question.ExplanationFormat = questionTranslation.GetExplanationFormat()
+	}
+
+	optionTranslations := map[uint]entity.QuestionOptionTranslation{}
+	for _, optionTranslation := range questionTranslation.Options {
+		optionTranslations[optionTranslation.QuestionOptionId] = optionTranslation
+	}
+	for i := range question.QuestionOptions {
+		optionTranslation, found := optionTranslations[question.QuestionOptions[i].Id]
+		if !found {
+			continue
+		}
+		question.QuestionOptions[i].Body = optionTranslation.Body
+		question.QuestionOptions[i].BodyFormat = optionTranslation.GetBodyFormat()
+		if optionTranslation.Feedback != "" {
+			question.QuestionOptions[i].Feedback = optionTranslation.Feedback
+			question.QuestionOptions[i].FeedbackFormat = optionTranslation.GetFeedbackFormat()
+		}
+	}
+}
+
+func listQuestionTranslations(ctx context.Context, questionTranslationRepository repository.QuestionTranslationRepository, questionIds []uint) ([]entity.QuestionTranslation, error) {
+	questionTranslations := []entity.QuestionTranslation{}
+	for start := 0; start < len(questionIds); start += translationsChunkSize {
+		end := start + translationsChunkSize
+		if end > len(questionIds) {
+			end = len(questionIds)
+		}
+		chunk, err := questionTranslationRepository.ListQuestionTranslations(ctx, questionIds[start:end])
+		if err != nil {
+			return nil, err
+		}
+		questionTranslations = append(questionTranslations, chunk...)
+	}
+	return questionTranslations, nil
+}
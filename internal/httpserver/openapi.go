@@ -0,0 +1,476 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const openAPIVersion = "3.0.3"
+
+// openAPIDocsPage renders the document of /openapi.json
+const openAPIDocsPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Question library API</title>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+	<redoc spec-url="/openapi.json"></redoc>
+	<script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// openAPIAuth is how a route authenticates its requests
+type openAPIAuth int
+
+const (
+	openAPIAuthNone     openAPIAuth = iota
+	openAPIAuthJwt                  // A JWT is required
+	openAPIAuthOptional             // A JWT is verified when sent
+	openAPIAuthAdmin                // A JWT of an admin is required
+)
+
+// openAPIRoute documents a route of the app. The schemas of its bodies and query params are generated from the Go
+// types the handler reads and writes, so the document follows the handlers when their types change
+type openAPIRoute struct {
+	Method      string
+	Path        string // In fiber syntax, like /questions/:id
+	OperationId string
+	Summary     string
+	Tag         string
+	Auth        openAPIAuth
+	Query       any // Zero value of the struct the handler parses the query into, with query tags
+	Params      []openAPIParameter
+	Request     *openAPIBody
+	Response    *openAPIBody // nil for an empty response
+	Errors      []int        // Statuses answered with an ErrorResponse besides the ones of the auth, params and body
+	Responses   map[int]*openAPIBody
+}
+
+// openAPIBody is a body of one of the content types, described by Value or by Schema when it isn't JSON
+type openAPIBody struct {
+	ContentTypes []string
+	Value        any
+	Values       map[string]any // Value of each content type, for the bodies whose type depends on it
+	Schema       *openAPISchema
+	Headers      map[string]string // Response headers and their description
+}
+
+func jsonBody(value any) *openAPIBody {
+	return &openAPIBody{ContentTypes: []string{fiber.MIMEApplicationJSON}, Value: value}
+}
+
+func binaryBody(contentTypes ...string) *openAPIBody {
+	return &openAPIBody{ContentTypes: contentTypes, Schema: &openAPISchema{Type: "string", Format: "binary"}}
+}
+
+// OpenAPIServer serves the OpenAPI document of the routes of NewServer and a page rendering it
+type OpenAPIServer struct {
+	document []byte
+}
+
+// NewOpenAPIServer generates the document once, it panics when a route can't be documented
+func NewOpenAPIServer() *OpenAPIServer {
+	document, err := json.Marshal(newOpenAPIDocument(openAPIRoutes))
+	if err != nil {
+		panic(fmt.Sprintf("openapi: %v", err))
+	}
+	return &OpenAPIServer{document: document}
+}
+
+func (s *OpenAPIServer) GetDocument(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(s.document)
+}
+
+func (s *OpenAPIServer) GetDocs(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(openAPIDocsPage)
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+type openAPIOperation struct {
+	OperationId string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Tags        []string                    `json:"tags"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+	Parameters  []openAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *uint64                   `json:"minLength,omitempty"`
+	MaxLength            *uint64                   `json:"maxLength,omitempty"`
+	MinItems             *uint64                   `json:"minItems,omitempty"`
+	MaxItems             *uint64                   `json:"maxItems,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+// openAPISchemaOverrides describes the types that marshal to something else than their fields
+var openAPISchemaOverrides = map[reflect.Type]*openAPISchema{
+	reflect.TypeOf(time.Time{}):          {Type: "string", Format: "date-time"},
+	reflect.TypeOf(json.RawMessage{}):    {Description: "Any JSON value"},
+	reflect.TypeOf(entity.QuestionTag{}): {Type: "string", MaxLength: uint64Pointer(50)},
+}
+
+// openAPIPathParameters are the schemas of the path params that are not ids
+var openAPIPathParameters = map[string]*openAPISchema{
+	"lang": {Type: "string", Description: "BCP 47 language tag"},
+}
+
+// newOpenAPIDocument documents the routes, it panics on types it can't describe so a bad route fails at startup
+func newOpenAPIDocument(routes []openAPIRoute) openAPIDocument {
+	g := &openAPISchemaGenerator{schemas: map[string]*openAPISchema{}, types: map[string]reflect.Type{}}
+	doc := openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "Question library API", Version: "1.0.0"},
+		Paths:   map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	for _, route := range routes {
+		path, operation := g.operation(route)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*openAPIOperation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = operation
+	}
+	return doc
+}
+
+type openAPISchemaGenerator struct {
+	schemas map[string]*openAPISchema
+	types   map[string]reflect.Type
+}
+
+func (g *openAPISchemaGenerator) operation(route openAPIRoute) (string, *openAPIOperation) {
+	operation := &openAPIOperation{
+		OperationId: route.OperationId,
+		Summary:     route.Summary,
+		Tags:        []string{route.Tag},
+		Responses:   map[string]*openAPIResponse{},
+	}
+	errorStatuses := append([]int{fiber.StatusInternalServerError}, route.Errors...)
+	switch route.Auth {
+	case openAPIAuthJwt, openAPIAuthAdmin:
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest, fiber.StatusUnauthorized)
+	case openAPIAuthOptional:
+		operation.Security = []map[string][]string{{}, {"bearerAuth": {}}}
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest, fiber.StatusUnauthorized)
+	}
+
+	// Path params
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = fmt.Sprintf("{%s}", name)
+		schema, ok := openAPIPathParameters[name]
+		if !ok {
+			schema = &openAPISchema{Type: "integer", Minimum: float64Pointer(0)}
+		}
+		operation.Parameters = append(operation.Parameters, openAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+	if route.Query != nil {
+		operation.Parameters = append(operation.Parameters, g.queryParameters(reflect.TypeOf(route.Query))...)
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+	operation.Parameters = append(operation.Parameters, route.Params...)
+
+	if route.Request != nil {
+		operation.RequestBody = &openAPIRequestBody{Required: route.Method != fiber.MethodGet, Content: g.content(route.Request)}
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+
+	operation.Responses["200"] = &openAPIResponse{Description: "OK"}
+	if route.Response != nil {
+		operation.Responses["200"] = g.response("OK", route.Response)
+	}
+	for status, body := range route.Responses {
+		operation.Responses[strconv.Itoa(status)] = g.response(utils.StatusMessage(status), body)
+	}
+	for _, status := range errorStatuses {
+		if operation.Responses[strconv.Itoa(status)] != nil {
+			continue
+		}
+		errorResponse := g.response(utils.StatusMessage(status), jsonBody(ErrorResponse{}))
+		// Invalid bodies are answered with the errors of their fields
+		if status == fiber.StatusBadRequest && route.Request != nil {
+			errorResponse.Content[fiber.MIMEApplicationJSON] = openAPIMediaType{Schema: &openAPISchema{OneOf: []*openAPISchema{
+				g.schema(reflect.TypeOf(ErrorResponse{})),
+				g.schema(reflect.TypeOf(ValidationErrorResponse{})),
+			}}}
+		}
+		operation.Responses[strconv.Itoa(status)] = errorResponse
+	}
+	// The JWT middleware answers a missing, malformed or invalid JWT in plain text
+	if route.Auth != openAPIAuthNone {
+		for _, status := range []int{fiber.StatusBadRequest, fiber.StatusUnauthorized} {
+			operation.Responses[strconv.Itoa(status)].Content[fiber.MIMETextPlain] = openAPIMediaType{Schema: &openAPISchema{Type: "string"}}
+		}
+	}
+
+	return strings.Join(segments, "/"), operation
+}
+
+func (g *openAPISchemaGenerator) response(description string, body *openAPIBody) *openAPIResponse {
+	response := &openAPIResponse{Description: description, Content: g.content(body)}
+	for name, headerDescription := range body.Headers {
+		if response.Headers == nil {
+			response.Headers = map[string]openAPIHeader{}
+		}
+		response.Headers[name] = openAPIHeader{Description: headerDescription, Schema: &openAPISchema{Type: "string"}}
+	}
+	return response
+}
+
+func (g *openAPISchemaGenerator) content(body *openAPIBody) map[string]openAPIMediaType {
+	schema := body.Schema
+	if schema == nil && body.Value != nil {
+		schema = g.schema(reflect.TypeOf(body.Value))
+	}
+	content := map[string]openAPIMediaType{}
+	for _, contentType := range body.ContentTypes {
+		if value, ok := body.Values[contentType]; ok {
+			content[contentType] = openAPIMediaType{Schema: g.schema(reflect.TypeOf(value))}
+			continue
+		}
+		content[contentType] = openAPIMediaType{Schema: schema}
+	}
+	return content
+}
+
+// queryParameters describes the fields of a struct parsed with QueryParser
+func (g *openAPISchemaGenerator) queryParameters(t reflect.Type) []openAPIParameter {
+	var parameters []openAPIParameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := g.schema(field.Type)
+		required := applyValidateTag(schema, field.Tag.Get("validate"))
+		parameters = append(parameters, openAPIParameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return parameters
+}
+
+// schema describes the JSON encoding of the type, named structs are added to the components and referenced
+func (g *openAPISchemaGenerator) schema(t reflect.Type) *openAPISchema {
+	if override, ok := openAPISchemaOverrides[t]; ok {
+		copied := *override
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if schema.Ref != "" {
+			return &openAPISchema{AllOf: []*openAPISchema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if existing, ok := g.types[t.Name()]; ok && existing != t {
+			panic(fmt.Sprintf("openapi: %s and %s have the same name", existing, t))
+		}
+		if _, ok := g.types[t.Name()]; !ok {
+			// Registered before its fields so recursive types reference themselves
+			g.types[t.Name()] = t
+			g.schemas[t.Name()] = &openAPISchema{}
+			*g.schemas[t.Name()] = *g.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Interface:
+		return &openAPISchema{Description: "Any JSON value"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Minimum: float64Pointer(0)}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	}
+	panic(fmt.Sprintf("openapi: can't describe %s", t))
+}
+
+// structSchema describes the fields encoding/json marshals
+func (g *openAPISchemaGenerator) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.schema(field.Type)
+		if applyValidateTag(fieldSchema, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// applyValidateTag adds the constraints of the validator tag to the schema and returns whether the field is required.
+// The rules after dive apply to the items
+func applyValidateTag(schema *openAPISchema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if schema.Items != nil {
+				_, itemsTag, _ := strings.Cut(tag, "dive,")
+				applyValidateTag(schema.Items, itemsTag)
+			}
+			return required
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "url":
+			schema.Format = "uri"
+		case "min", "max":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyLimit(schema, name == "min", limit)
+		}
+	}
+	return required
+}
+
+// applyLimit sets the min or max of validator, a length for strings and arrays and a value for numbers
+func applyLimit(schema *openAPISchema, min bool, limit float64) {
+	switch {
+	case schema.Type == "string" && min:
+		schema.MinLength = uint64Pointer(uint64(limit))
+	case schema.Type == "string":
+		schema.MaxLength = uint64Pointer(uint64(limit))
+	case schema.Type == "array" && min:
+		schema.MinItems = uint64Pointer(uint64(limit))
+	case schema.Type == "array":
+		schema.MaxItems = uint64Pointer(uint64(limit))
+	case min:
+		schema.Minimum = &limit
+	default:
+		schema.Maximum = &limit
+	}
+}
+
+func float64Pointer(value float64) *float64 {
+	return &value
+}
+
+func uint64Pointer(value uint64) *uint64 {
+	return &value
+}
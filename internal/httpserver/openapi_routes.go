@@ -0,0 +1,465 @@
+package httpserver
+
+import (
+	"challenge/internal/attachment"
+	"challenge/internal/entity"
+	"challenge/internal/exporter"
+	"challenge/internal/importer"
+	"challenge/internal/stats"
+	"challenge/pkg/jsonpatch"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPIRenderParam asks for the HTML of the rich text bodies
+var openAPIRenderParam = openAPIParameter{
+	Name:        "render",
+	In:          "query",
+	Description: "Fills the HTML of the bodies, explanations and feedbacks",
+	Schema:      &openAPISchema{Type: "string", Enum: []string{RenderHTML}},
+}
+
+// openAPILanguageParams negotiate the language of the bodies like parseLanguages
+var openAPILanguageParams = []openAPIParameter{
+	{Name: "lang", In: "query", Description: "Language of the translation to serve, takes precedence over Accept-Language", Schema: &openAPISchema{Type: "string"}},
+	{Name: fiber.HeaderAcceptLanguage, In: "header", Schema: &openAPISchema{Type: "string"}},
+}
+
+// openAPIReadParams are the params of the routes that read question bodies
+var openAPIReadParams = append([]openAPIParameter{openAPIRenderParam}, openAPILanguageParams...)
+
+// openAPIRoutes are the routes of NewServer, the contract test fails when they differ from the routes it serves
+var openAPIRoutes = []openAPIRoute{
+	// Documentation
+	{
+		Method: fiber.MethodGet, Path: "/openapi.json", OperationId: "getOpenAPI", Tag: "docs",
+		Summary:  "This document",
+		Response: &openAPIBody{ContentTypes: []string{fiber.MIMEApplicationJSON}, Schema: &openAPISchema{Type: "object"}},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/docs", OperationId: "getDocs", Tag: "docs",
+		Summary:  "Page rendering this document",
+		Response: &openAPIBody{ContentTypes: []string{fiber.MIMETextHTML}, Schema: &openAPISchema{Type: "string"}},
+	},
+
+	// Questions
+	{
+		Method: fiber.MethodGet, Path: "/questions", OperationId: "listQuestions", Tag: "questions",
+		Summary:  "Pages through the questions in id order, with the explanations and feedback the user can review",
+		Auth:     openAPIAuthOptional,
+		Params:   openAPIReadParams,
+		Request:  jsonBody(ListQuestionsRequest{}),
+		Response: jsonBody([]entity.Question{}),
+	},
+	{
+		Method: fiber.MethodGet, Path: "/questions/export", OperationId: "exportQuestions", Tag: "questions",
+		Summary: "Streams every question matching the filter as a file, with the explanations and feedback the user can review",
+		Auth:    openAPIAuthOptional,
+		Query:   ExportQuestionsRequest{},
+		Response: &openAPIBody{
+			ContentTypes: []string{
+				exporter.ContentType(exporter.FormatJSON),
+				exporter.ContentType(exporter.FormatNDJSON),
+				exporter.ContentType(exporter.FormatCSV),
+				exporter.ContentType(exporter.FormatQTI),
+			},
+			Schema:  &openAPISchema{Type: "string", Format: "binary"},
+			Headers: map[string]string{ExportVersionHeader: "Version of the export format, accepted by the import"},
+		},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/questions/events", OperationId: "streamQuestionEvents", Tag: "questions",
+		Summary:  "Server-sent events of the question changes",
+		Query:    StreamQuestionEventsRequest{},
+		Params:   []openAPIParameter{{Name: "Last-Event-ID", In: "header", Description: "Id of the last event received, to resume after it", Schema: &openAPISchema{Type: "string"}}},
+		Response: &openAPIBody{ContentTypes: []string{"text/event-stream"}, Schema: &openAPISchema{Type: "string"}},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/questions/translations/missing", OperationId: "listMissingTranslations", Tag: "translations",
+		Summary: "Reports the questions of the auth user missing or with outdated translations",
+		Auth:    openAPIAuthJwt,
+		Params: []openAPIParameter{{
+			Name: "languages", In: "query", Required: true, Description: "Language tags separated by commas", Schema: &openAPISchema{Type: "string"},
+		}},
+		Response: jsonBody([]MissingTranslation{}),
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions", OperationId: "createQuestion", Tag: "questions",
+		Summary: "Creates a question of the auth user",
+		Auth:    openAPIAuthJwt,
+		Params: []openAPIParameter{{
+			Name: IdempotencyKeyHeader, In: "header", Description: "Replays the response of the first request with the key", Schema: &openAPISchema{Type: "string", MaxLength: uint64Pointer(idempotencyKeyMaxLength)},
+		}},
+		Request: jsonBody(entity.Question{}),
+		Response: &openAPIBody{
+			ContentTypes: []string{fiber.MIMEApplicationJSON},
+			Value:        entity.Question{},
+			Headers:      map[string]string{IdempotentReplayedHeader: "true when the response is replayed"},
+		},
+		Errors: []int{fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/import", OperationId: "importQuestions", Tag: "questions",
+		Summary: "Imports the questions of a file",
+		Auth:    openAPIAuthJwt,
+		Params: []openAPIParameter{
+			{Name: "mode", In: "query", Schema: &openAPISchema{Type: "string", Enum: []string{ImportModeAllOrNothing, ImportModeBestEffort}}},
+			{
+				Name: "format", In: "query", Description: "Defaults to the format of the Content-Type",
+				Schema: &openAPISchema{Type: "string", Enum: []string{importer.FormatJSON, importer.FormatNDJSON, importer.FormatCSV, importer.FormatGIFT, importer.FormatQTI}},
+			},
+			{Name: ExportVersionHeader, In: "header", Description: "Version of the export format of the file", Schema: &openAPISchema{Type: "string"}},
+		},
+		Request:   binaryBody(fiber.MIMEApplicationJSON, "application/x-ndjson", "text/csv", fiber.MIMETextPlain, "application/zip"),
+		Response:  jsonBody(ImportQuestionsResponse{}),
+		Responses: map[int]*openAPIBody{fiber.StatusUnprocessableEntity: jsonBody(ImportQuestionsResponse{})},
+		Errors:    []int{fiber.StatusRequestEntityTooLarge},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/batch", OperationId: "batchQuestions", Tag: "questions",
+		Summary:   "Creates, updates and deletes questions in one request",
+		Auth:      openAPIAuthJwt,
+		Request:   jsonBody(BatchQuestionsRequest{}),
+		Response:  jsonBody(BatchQuestionsResponse{}),
+		Responses: map[int]*openAPIBody{fiber.StatusUnprocessableEntity: jsonBody(BatchQuestionsResponse{})},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/questions/:id", OperationId: "updateQuestion", Tag: "questions",
+		Summary:  "Replaces a question of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(entity.Question{}),
+		Response: jsonBody(entity.Question{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPatch, Path: "/questions/:id", OperationId: "patchQuestion", Tag: "questions",
+		Summary: "Updates a question of the auth user with a JSON Merge Patch or a JSON Patch",
+		Auth:    openAPIAuthJwt,
+		Request: &openAPIBody{ContentTypes: []string{MIMEMergePatch, MIMEJSONPatch}, Values: map[string]any{
+			MIMEMergePatch: map[string]any{},
+			MIMEJSONPatch:  []jsonpatch.Operation{},
+		}},
+		Response: jsonBody(entity.Question{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict, fiber.StatusUnsupportedMediaType, fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/questions/:id", OperationId: "deleteQuestion", Tag: "questions",
+		Summary: "Deletes a question, deleting a missing question succeeds",
+		Auth:    openAPIAuthJwt,
+	},
+
+	// Options
+	{
+		Method: fiber.MethodGet, Path: "/questions/:id/options", OperationId: "listQuestionOptions", Tag: "options",
+		Summary:  "Lists the options of a question in order, with their feedback when the user can review it",
+		Auth:     openAPIAuthOptional,
+		Params:   openAPIReadParams,
+		Response: jsonBody([]entity.QuestionOption{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/:id/options", OperationId: "createQuestionOption", Tag: "options",
+		Summary:  "Adds an option to a question of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(CreateQuestionOptionRequest{}),
+		Response: jsonBody(entity.QuestionOption{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/questions/:id/options/:optionId", OperationId: "updateQuestionOption", Tag: "options",
+		Summary:  "Replaces an option of a question of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(UpdateQuestionOptionRequest{}),
+		Response: jsonBody(entity.QuestionOption{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/questions/:id/options/:optionId", OperationId: "deleteQuestionOption", Tag: "options",
+		Summary: "Deletes an option of a question of the auth user",
+		Auth:    openAPIAuthJwt,
+		Errors:  []int{fiber.StatusNotFound, fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/questions/:id/options/:optionId/position", OperationId: "moveQuestionOption", Tag: "options",
+		Summary:  "Moves an option to a position, shifting the options in between",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(MoveQuestionOptionRequest{}),
+		Response: jsonBody([]entity.QuestionOption{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusUnprocessableEntity},
+	},
+
+	// Translations
+	{
+		Method: fiber.MethodGet, Path: "/questions/:id/translations", OperationId: "listQuestionTranslations", Tag: "translations",
+		Summary:  "Lists the translations of a question",
+		Response: jsonBody([]entity.QuestionTranslation{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/questions/:id/translations/:lang", OperationId: "saveQuestionTranslation", Tag: "translations",
+		Summary:  "Creates or replaces the translation of a question of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(entity.QuestionTranslation{}),
+		Response: jsonBody(entity.QuestionTranslation{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/questions/:id/translations/:lang", OperationId: "deleteQuestionTranslation", Tag: "translations",
+		Summary: "Deletes the translation of a question of the auth user",
+		Auth:    openAPIAuthJwt,
+		Errors:  []int{fiber.StatusNotFound},
+	},
+
+	// Comments
+	{
+		Method: fiber.MethodGet, Path: "/questions/:id/comments", OperationId: "listQuestionComments", Tag: "comments",
+		Summary:  "Lists the comment threads of a question",
+		Auth:     openAPIAuthJwt,
+		Response: jsonBody([]entity.QuestionComment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/:id/comments", OperationId: "createQuestionComment", Tag: "comments",
+		Summary:  "Comments a question or replies to a comment",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(CreateQuestionCommentRequest{}),
+		Response: jsonBody(entity.QuestionComment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/questions/:id/comments/:commentId", OperationId: "updateQuestionComment", Tag: "comments",
+		Summary:  "Edits a comment of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(UpdateQuestionCommentRequest{}),
+		Response: jsonBody(entity.QuestionComment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/questions/:id/comments/:commentId", OperationId: "deleteQuestionComment", Tag: "comments",
+		Summary: "Deletes a comment of the auth user with its replies",
+		Auth:    openAPIAuthJwt,
+		Errors:  []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/:id/comments/:commentId/resolve", OperationId: "resolveQuestionComment", Tag: "comments",
+		Summary:  "Resolves a comment thread",
+		Auth:     openAPIAuthJwt,
+		Response: jsonBody(entity.QuestionComment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/questions/:id/comments/:commentId/unresolve", OperationId: "unresolveQuestionComment", Tag: "comments",
+		Summary:  "Reopens a comment thread",
+		Auth:     openAPIAuthJwt,
+		Response: jsonBody(entity.QuestionComment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+
+	// Stats
+	{
+		Method: fiber.MethodGet, Path: "/questions/:id/stats", OperationId: "getQuestionStats", Tag: "stats",
+		Summary: "Reports the psychometric statistics of a question revision",
+		Auth:    openAPIAuthJwt,
+		Params: []openAPIParameter{{
+			Name: "revision", In: "query", Description: "Defaults to the current revision", Schema: &openAPISchema{Type: "integer", Minimum: float64Pointer(0)},
+		}},
+		Response: jsonBody(stats.QuestionReport{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+
+	// Assessments
+	{
+		Method: fiber.MethodGet, Path: "/assessments", OperationId: "listAssessments", Tag: "assessments",
+		Summary:  "Pages through the assessments of the auth user",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(ListAssessmentsRequest{}),
+		Response: jsonBody([]entity.Assessment{}),
+	},
+	{
+		Method: fiber.MethodPost, Path: "/assessments", OperationId: "createAssessment", Tag: "assessments",
+		Summary:  "Creates an assessment from snapshots of the questions",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(entity.Assessment{}),
+		Response: jsonBody(entity.Assessment{}),
+	},
+	{
+		Method: fiber.MethodPost, Path: "/assessments/generate", OperationId: "generateAssessment", Tag: "assessments",
+		Summary:  "Creates an assessment from random questions matching the rules",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(GenerateAssessmentRequest{}),
+		Response: jsonBody(GenerateAssessmentResponse{}),
+		Errors:   []int{fiber.StatusUnprocessableEntity},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/assessments/:id", OperationId: "getAssessment", Tag: "assessments",
+		Summary:  "Gets an assessment of the auth user",
+		Auth:     openAPIAuthJwt,
+		Params:   []openAPIParameter{openAPIRenderParam},
+		Response: jsonBody(entity.Assessment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/assessments/:id", OperationId: "updateAssessment", Tag: "assessments",
+		Summary:  "Replaces an assessment of the auth user without attempts",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(entity.Assessment{}),
+		Response: jsonBody(entity.Assessment{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/assessments/:id", OperationId: "deleteAssessment", Tag: "assessments",
+		Summary: "Deletes an assessment of the auth user",
+		Auth:    openAPIAuthJwt,
+		Errors:  []int{fiber.StatusNotFound},
+	},
+
+	// Attempts
+	{
+		Method: fiber.MethodPost, Path: "/attempts", OperationId: "startAttempt", Tag: "attempts",
+		Summary:  "Starts an attempt of the auth user at an assessment",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(StartAttemptRequest{}),
+		Response: jsonBody(entity.Attempt{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/attempts/:id", OperationId: "getAttempt", Tag: "attempts",
+		Summary:  "Gets an attempt of its candidate or of the author of its assessment",
+		Auth:     openAPIAuthJwt,
+		Response: jsonBody(entity.Attempt{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/attempts/:id/questions", OperationId: "listAttemptQuestions", Tag: "attempts",
+		Summary:  "Lists the questions of an attempt without their answers",
+		Auth:     openAPIAuthJwt,
+		Params:   openAPIReadParams,
+		Response: jsonBody([]CandidateQuestion{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodPut, Path: "/attempts/:id/answers/:position", OperationId: "submitAttemptAnswer", Tag: "attempts",
+		Summary:  "Answers the question at a position of an attempt",
+		Auth:     openAPIAuthJwt,
+		Request:  jsonBody(SubmitAttemptAnswerRequest{}),
+		Response: jsonBody(entity.AttemptAnswer{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/attempts/:id/finish", OperationId: "finishAttempt", Tag: "attempts",
+		Summary:  "Finishes and scores an attempt",
+		Auth:     openAPIAuthJwt,
+		Response: jsonBody(entity.Attempt{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/attempts/:id/review", OperationId: "reviewAttempt", Tag: "attempts",
+		Summary:  "Reviews the answers of a finished attempt with the correct options",
+		Auth:     openAPIAuthJwt,
+		Params:   openAPIReadParams,
+		Response: jsonBody([]ReviewQuestion{}),
+		Errors:   []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+
+	// Attachments
+	{
+		Method: fiber.MethodPost, Path: "/attachments", OperationId: "uploadAttachment", Tag: "attachments",
+		Summary: "Uploads an image or PDF file",
+		Auth:    openAPIAuthJwt,
+		Request: &openAPIBody{ContentTypes: []string{fiber.MIMEMultipartForm}, Schema: &openAPISchema{
+			Type:       "object",
+			Properties: map[string]*openAPISchema{"file": {Type: "string", Format: "binary"}},
+			Required:   []string{"file"},
+		}},
+		Response: jsonBody(entity.Attachment{}),
+		Errors:   []int{fiber.StatusRequestEntityTooLarge, fiber.StatusUnsupportedMediaType},
+	},
+	{
+		Method: fiber.MethodPost, Path: "/attachments/cleanup", OperationId: "cleanupAttachments", Tag: "attachments",
+		Summary:  "Deletes the orphaned attachments and blobs now, admins only",
+		Auth:     openAPIAuthAdmin,
+		Response: jsonBody(attachment.CleanupResult{}),
+	},
+	{
+		Method: fiber.MethodGet, Path: "/attachments/:id", OperationId: "getAttachment", Tag: "attachments",
+		Summary:  "Gets the metadata of an attachment",
+		Response: jsonBody(entity.Attachment{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/attachments/:id/content", OperationId: "getAttachmentContent", Tag: "attachments",
+		Summary:  "Downloads the file of an attachment",
+		Response: binaryBody("*/*"),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/attachments/:id", OperationId: "deleteAttachment", Tag: "attachments",
+		Summary: "Deletes an unreferenced attachment of the auth user",
+		Auth:    openAPIAuthJwt,
+		Errors:  []int{fiber.StatusNotFound, fiber.StatusConflict},
+	},
+
+	// Webhooks
+	{
+		Method: fiber.MethodGet, Path: "/webhooks", OperationId: "listWebhookSubscriptions", Tag: "webhooks",
+		Summary:  "Lists the webhook subscriptions",
+		Auth:     openAPIAuthAdmin,
+		Response: jsonBody([]entity.WebhookSubscription{}),
+	},
+	{
+		Method: fiber.MethodPost, Path: "/webhooks", OperationId: "createWebhookSubscription", Tag: "webhooks",
+		Summary:  "Subscribes a url to question events",
+		Auth:     openAPIAuthAdmin,
+		Request:  jsonBody(CreateWebhookSubscriptionRequest{}),
+		Response: jsonBody(entity.WebhookSubscription{}),
+	},
+	{
+		Method: fiber.MethodGet, Path: "/webhooks/deliveries", OperationId: "listWebhookDeliveries", Tag: "webhooks",
+		Summary:  "Pages through the webhook deliveries",
+		Auth:     openAPIAuthAdmin,
+		Request:  jsonBody(ListWebhookDeliveriesRequest{}),
+		Response: jsonBody([]entity.WebhookDelivery{}),
+	},
+	{
+		Method: fiber.MethodPost, Path: "/webhooks/deliveries/:id/retry", OperationId: "retryWebhookDelivery", Tag: "webhooks",
+		Summary: "Retries a dead letter",
+		Auth:    openAPIAuthAdmin,
+		Errors:  []int{fiber.StatusConflict},
+	},
+	{
+		Method: fiber.MethodGet, Path: "/webhooks/:id", OperationId: "getWebhookSubscription", Tag: "webhooks",
+		Summary:  "Gets a webhook subscription",
+		Auth:     openAPIAuthAdmin,
+		Response: jsonBody(entity.WebhookSubscription{}),
+		Errors:   []int{fiber.StatusNotFound},
+	},
+	{
+		Method: fiber.MethodDelete, Path: "/webhooks/:id", OperationId: "deleteWebhookSubscription", Tag: "webhooks",
+		Summary: "Deletes a webhook subscription with its deliveries",
+		Auth:    openAPIAuthAdmin,
+	},
+
+	// Audit
+	{
+		Method: fiber.MethodGet, Path: "/audit", OperationId: "listAuditEntries", Tag: "audit",
+		Summary:  "Pages through the audit log, admins only",
+		Auth:     openAPIAuthAdmin,
+		Request:  jsonBody(ListAuditEntriesRequest{}),
+		Response: jsonBody([]entity.AuditEntry{}),
+	},
+
+	// GraphQL
+	{
+		Method: fiber.MethodPost, Path: "/graphql", OperationId: "executeGraphQL", Tag: "graphql",
+		Summary: "Executes a GraphQL query or mutation, failures are in the errors of the response",
+		Auth:    openAPIAuthOptional,
+		Request: jsonBody(GraphQLRequest{}),
+		Response: &openAPIBody{ContentTypes: []string{fiber.MIMEApplicationJSON}, Schema: &openAPISchema{
+			Type: "object",
+			Properties: map[string]*openAPISchema{
+				"data":   {Type: "object", Nullable: true},
+				"errors": {Type: "array", Items: &openAPISchema{Type: "object"}},
+			},
+		}},
+	},
+}
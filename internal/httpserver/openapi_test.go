@@ -0,0 +1,318 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var fiberPathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+func getOpenAPIDocument(t *testing.T, server *fiber.App) map[string]any {
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, res.Header.Get(fiber.HeaderContentType))
+
+	var doc map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&doc))
+	return doc
+}
+
+// TestOpenAPIRoutes fails when a route is served without being documented or documented without being served
+func TestOpenAPIRoutes(t *testing.T) {
+	server := httpserver.NewServer(httpserver.Repositories{}, nil, nil)
+	doc := getOpenAPIDocument(t, server)
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	documented := []string{}
+	for path, operations := range doc["paths"].(map[string]any) {
+		for method := range operations.(map[string]any) {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	// The routes of the mounted apps are listed once the app started, which the request above did
+	served := []string{}
+	seen := map[string]bool{}
+	for _, route := range server.GetRoutes(true) {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := route.Path
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		route := route.Method + " " + fiberPathParamPattern.ReplaceAllString(path, "{$1}")
+		if !seen[route] {
+			seen[route] = true
+			served = append(served, route)
+		}
+	}
+
+	assert.ElementsMatch(t, served, documented)
+}
+
+func TestOpenAPIDocs(t *testing.T) {
+	server := httpserver.NewServer(httpserver.Repositories{}, nil, nil)
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, fiber.MIMETextHTMLCharsetUTF8, res.Header.Get(fiber.HeaderContentType))
+
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(resBody), `spec-url="/openapi.json"`)
+}
+
+// TestOpenAPIResponses fails when a handler answers with a status or a body its schema doesn't describe
+func TestOpenAPIResponses(t *testing.T) {
+	correct := true
+	incorrect := false
+	attachmentId := uint(3)
+	question := entity.Question{
+		Id:           1,
+		Body:         "Which?",
+		Difficulty:   entity.DifficultyEasy,
+		AttachmentId: &attachmentId,
+		AuthorId:     1,
+		Revision:     2,
+		QuestionOptions: []entity.QuestionOption{
+			{Id: 1, QuestionId: 1, Body: "this", Correct: &correct},
+			{Id: 2, QuestionId: 1, Body: "that", Correct: &incorrect},
+		},
+		Tags: []entity.QuestionTag{{Name: "go"}},
+	}
+
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(0), (*uint)(nil), mock.Anything, mock.Anything, mock.Anything).
+		Return([]entity.Question{question}, nil).Maybe()
+	questionRepository.On("GetQuestion", mock.Anything, uint(2), mock.Anything).
+		Return(entity.Question{}, gorm.ErrRecordNotFound).Maybe()
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).Maybe()
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			question.Id = 1
+			return nil
+		}).Maybe()
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil).Maybe()
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		Outbox:         newOutboxRepository(t),
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+	doc := getOpenAPIDocument(t, server)
+
+	type Test struct {
+		TestName               string
+		Method                 string
+		Path                   string // Path of the operation in the document
+		Url                    string
+		Auth                   bool
+		Req                    string
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "ListQuestions",
+			Method:                 http.MethodGet,
+			Path:                   "/questions",
+			Url:                    "/questions?render=html",
+			ExpectedHttpStatusCode: fiber.StatusOK,
+		},
+		{
+			TestName:               "CreateQuestion",
+			Method:                 http.MethodPost,
+			Path:                   "/questions",
+			Url:                    "/questions",
+			Auth:                   true,
+			Req:                    `{"body": "Which?", "options": [{"body": "this", "correct": true}, {"body": "that", "correct": false}], "tags": ["go"]}`,
+			ExpectedHttpStatusCode: fiber.StatusOK,
+		},
+		{
+			TestName:               "CreateQuestionInvalid",
+			Method:                 http.MethodPost,
+			Path:                   "/questions",
+			Url:                    "/questions",
+			Auth:                   true,
+			Req:                    `{"difficulty": "impossible"}`,
+			ExpectedHttpStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			TestName:               "CreateQuestionAnonymous",
+			Method:                 http.MethodPost,
+			Path:                   "/questions",
+			Url:                    "/questions",
+			Req:                    `{}`,
+			ExpectedHttpStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			TestName:               "ListQuestionOptionsNotFound",
+			Method:                 http.MethodGet,
+			Path:                   "/questions/{id}/options",
+			Url:                    "/questions/2/options",
+			ExpectedHttpStatusCode: fiber.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			req := httptest.NewRequest(test.Method, test.Url, bytes.NewReader([]byte(test.Req)))
+			req.Header.Set("Content-Type", "application/json")
+			if test.Auth {
+				req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+			}
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+
+			operation, ok := doc["paths"].(map[string]any)[test.Path].(map[string]any)[strings.ToLower(test.Method)].(map[string]any)
+			require.True(t, ok, "operation isn't documented")
+			response, ok := operation["responses"].(map[string]any)[strconv.Itoa(res.StatusCode)].(map[string]any)
+			require.True(t, ok, "status isn't documented")
+			contentType, _, _ := strings.Cut(res.Header.Get(fiber.HeaderContentType), ";")
+			mediaType, ok := response["content"].(map[string]any)[contentType].(map[string]any)
+			require.True(t, ok, "content type %s isn't documented", contentType)
+			schema := mediaType["schema"].(map[string]any)
+
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			var value any = string(resBody)
+			if contentType == fiber.MIMEApplicationJSON {
+				require.NoError(t, json.Unmarshal(resBody, &value))
+			}
+			assert.NoError(t, validateOpenAPISchema(doc, schema, value, "$"))
+		})
+	}
+}
+
+// validateOpenAPISchema checks the decoded JSON value against the subset of the schema keywords the document uses
+func validateOpenAPISchema(doc map[string]any, schema map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		refSchema, ok := doc["components"].(map[string]any)["schemas"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, ref)
+		}
+		return validateOpenAPISchema(doc, refSchema, value, path)
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || len(schema) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: null isn't nullable", path)
+	}
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, subschema := range allOf {
+			if err := validateOpenAPISchema(doc, subschema.(map[string]any), value, path); err != nil {
+				return err
+			}
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, subschema := range oneOf {
+			if validateOpenAPISchema(doc, subschema.(map[string]any), value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: matches %d schemas of oneOf", path, matches)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: %v isn't an object", path, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additionalProperties, _ := schema["additionalProperties"].(map[string]any)
+		for key, propertyValue := range object {
+			propertySchema, ok := properties[key].(map[string]any)
+			if !ok {
+				propertySchema = additionalProperties
+			}
+			if propertySchema == nil {
+				if properties == nil {
+					continue
+				}
+				return fmt.Errorf("%s: unknown property %s", path, key)
+			}
+			if err := validateOpenAPISchema(doc, propertySchema, propertyValue, path+"."+key); err != nil {
+				return err
+			}
+		}
+		required, _ := schema["required"].([]any)
+		for _, key := range required {
+			if _, ok := object[key.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %s", path, key)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: %v isn't an array", path, value)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range array {
+			if err := validateOpenAPISchema(doc, items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: %v isn't a string", path, value)
+		}
+		if enum, ok := schema["enum"].([]any); ok && s != "" {
+			for _, allowed := range enum {
+				if s == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: %s isn't one of %v", path, s, enum)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: %v isn't an integer", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: %v isn't a number", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: %v isn't a boolean", path, value)
+		}
+	}
+	return nil
+}
@@ -2,41 +2,111 @@ package httpserver
 
 import (
 	"challenge/internal/entity"
+	"challenge/internal/eventbus"
+	"challenge/internal/outbox"
 	"challenge/internal/repository"
-	"challenge/pkg/env"
+	"challenge/internal/webhook"
 	"challenge/pkg/gormprovider"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	jwtware "github.com/gofiber/jwt/v3"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
+var questionOptionsPreload = gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}
+
+// questionPreloads loads every association returned with a question
+var questionPreloads = []gormprovider.Option{
+	questionOptionsPreload,
+	gormprovider.PreloadOption("Tags"),
+}
+
+func withQuestionPreloads(opts ...gormprovider.Option) []gormprovider.Option {
+	return append(append([]gormprovider.Option{}, questionPreloads...), opts...)
+}
+
 type QuestionServer struct {
-	questionRepository       repository.QuestionRepository
-	questionOptionRepository repository.QuestionOptionRepository
+	questionRepository            repository.QuestionRepository
+	questionOptionRepository      repository.QuestionOptionRepository
+	questionTagRepository         repository.QuestionTagRepository
+	idempotencyKeyRepository      repository.IdempotencyKeyRepository
+	idempotencyKeyTTL             time.Duration
+	attachmentRepository          repository.AttachmentRepository
+	questionTranslationRepository repository.QuestionTranslationRepository
+	outboxRepository              repository.OutboxRepository
+	auditEntryRepository          repository.AuditEntryRepository
+	questionEvents                *eventbus.Bus
+	questionEventsHeartbeat       time.Duration
 }
 
-func NewQuestionServer(questionRepository repository.QuestionRepository, questionOptionRepository repository.QuestionOptionRepository) *fiber.App {
-	jwtAuth := jwtware.New(jwtware.Config{SigningKey: []byte(env.GetOrDefault("JWT_SIGNING_KEY", "secret"))})
-	server := &QuestionServer{questionRepository, questionOptionRepository}
+func NewQuestionServer(
+	questionRepository repository.QuestionRepository,
+	questionOptionRepository repository.QuestionOptionRepository,
+	questionTagRepository repository.QuestionTagRepository,
+	idempotencyKeyRepository repository.IdempotencyKeyRepository,
+	attachmentRepository repository.AttachmentRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+	outboxRepository repository.OutboxRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+	questionEvents *eventbus.Bus,
+) *fiber.App {
+	jwtAuth := newJwtAuth()
+	server := &QuestionServer{
+		questionRepository:            questionRepository,
+		questionOptionRepository:      questionOptionRepository,
+		questionTagRepository:         questionTagRepository,
+		idempotencyKeyRepository:      idempotencyKeyRepository,
+		idempotencyKeyTTL:             newIdempotencyKeyTTL(),
+		attachmentRepository:          attachmentRepository,
+		questionTranslationRepository: questionTranslationRepository,
+		outboxRepository:              outboxRepository,
+		auditEntryRepository:          auditEntryRepository,
+		questionEvents:                questionEvents,
+		questionEventsHeartbeat:       newQuestionEventsHeartbeat(),
+	}
+	optionalJwtAuth := newOptionalJwtAuth()
 	app := fiber.New()
-	app.Get("/", server.ListQuestions)
+	app.Get("/", optionalJwtAuth, server.ListQuestions)
+	app.Get("/export", optionalJwtAuth, server.ExportQuestions)
+	app.Get("/events", server.StreamQuestionEvents)
+	app.Get("/translations/missing", jwtAuth, server.ListMissingTranslations)
 	app.Post("/", jwtAuth, server.CreateQuestion)
+	app.Post("/import", jwtAuth, server.ImportQuestions)
+	app.Post("/batch", jwtAuth, server.BatchQuestions)
 	app.Put("/:id", jwtAuth, server.UpdateQuestion)
+	app.Patch("/:id", jwtAuth, server.PatchQuestion)
 	app.Delete("/:id", jwtAuth, server.DeleteQuestion)
 
 	return app
 }
 
 type ListQuestionsRequest struct {
-	LastId   *uint `json:"lastId"`
-	PageSize uint  `json:"pageSize" validate:"max=1000"`
-	AuthorId *uint `json:"authorId"`
+	LastId     *uint    `json:"lastId"`
+	PageSize   uint     `json:"pageSize" validate:"max=1000"`
+	AuthorId   *uint    `json:"authorId"`
+	Difficulty string   `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Tags       []string `json:"tags"`
 }
 
 func (s QuestionServer) ListQuestions(c *fiber.Ctx) error {
 	var req ListQuestionsRequest
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+	render, errRes := parseRender(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	langs, errRes := parseLanguages(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
 
 	// Validate and parse request
 	if c.Request().Header.ContentLength() > 0 {
@@ -47,7 +117,7 @@ func (s QuestionServer) ListQuestions(c *fiber.Ctx) error {
 	}
 
 	// Build question filter
-	questionFilter := repository.QuestionFilter{}
+	questionFilter := repository.QuestionFilter{Difficulty: req.Difficulty, Tags: req.Tags}
 	if req.AuthorId != nil {
 		questionFilter.AuthorId = *req.AuthorId
 	}
@@ -57,13 +127,29 @@ func (s QuestionServer) ListQuestions(c *fiber.Ctx) error {
 		c.UserContext(),
 		req.PageSize,
 		req.LastId,
-		gormprovider.PreloadOption("QuestionOptions"),
-		questionFilter,
+		withQuestionPreloads(questionFilter)...,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list questions")
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
 	}
+	err = translateQuestions(c.UserContext(), s.questionTranslationRepository, questions, langs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to translate questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if render {
+		for i := range questions {
+			if err := renderQuestionHTML(&questions[i]); err != nil {
+				log.Error().Err(err).Msg("Failed to render questions")
+				return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+			}
+		}
+	}
+	role := getAuthUserRole(c)
+	for i := range questions {
+		HideExplanations(&questions[i], userId, role)
+	}
 
 	return c.JSON(questions)
 }
@@ -82,23 +168,89 @@ func (s QuestionServer) CreateQuestion(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(errRes)
 	}
 	question.AuthorId = authorId
+	setOrderingOptionsCorrect(&question)
+	errStatus, errRes := checkQuestionAttachments(c.UserContext(), s.attachmentRepository, question, authorId)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	idempotencyKey := c.Get(IdempotencyKeyHeader)
+	if idempotencyKey == "" {
+		err = s.createQuestion(c.UserContext(), &question)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to create questions")
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+		}
+
+		return c.JSON(question)
+	}
+	if len(idempotencyKey) > idempotencyKeyMaxLength {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Idempotency-Key is too long"})
+	}
+	return s.createQuestionIdempotent(c, authorId, idempotencyKey, &question)
+}
 
+// createQuestionIdempotent creates the question and stores the response under the key in the same
+// transaction, retries with the key replay the stored response
+func (s QuestionServer) createQuestionIdempotent(c *fiber.Ctx, authorId uint, key string, question *entity.Question) error {
+	requestHash, err := hashIdempotentRequest(question)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to hash request")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	now := time.Now().UTC()
+	storedKey, err := s.idempotencyKeyRepository.GetIdempotencyKey(c.UserContext(), authorId, key, now)
+	if err == nil {
+		return replayIdempotencyKey(c, storedKey, requestHash)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error().Err(err).Msg("Failed to get idempotency key")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	var responseBody []byte
 	err = s.questionRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
-		// Create question
-		err = s.questionRepository.CreateQuestion(c.UserContext(), &question)
+		err := s.createQuestion(txCtx, question)
 		if err != nil {
 			return err
 		}
 
-		// Create question options
-		return s.questionOptionRepository.BulkCreateQuestionOptions(c.UserContext(), question.Id, question.QuestionOptions)
+		responseBody, err = json.Marshal(question)
+		if err != nil {
+			return err
+		}
+		saved, err := s.idempotencyKeyRepository.SaveIdempotencyKey(txCtx, &entity.IdempotencyKey{
+			UserId:       authorId,
+			Key:          key,
+			RequestHash:  requestHash,
+			StatusCode:   fiber.StatusOK,
+			ResponseBody: responseBody,
+			CreatedAt:    now,
+			ExpiresAt:    now.Add(s.idempotencyKeyTTL),
+		})
+		if err != nil {
+			return err
+		}
+		if !saved {
+			return errIdempotencyKeyTaken
+		}
+		return nil
 	})
+	if errors.Is(err, errIdempotencyKeyTaken) {
+		// A concurrent request with the same key won, its question is the one to return
+		storedKey, err = s.idempotencyKeyRepository.GetIdempotencyKey(c.UserContext(), authorId, key, now)
+		if err == nil {
+			return replayIdempotencyKey(c, storedKey, requestHash)
+		}
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create questions")
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
 	}
 
-	return c.JSON(question)
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(responseBody)
 }
 
 func (s QuestionServer) UpdateQuestion(c *fiber.Ctx) error {
@@ -120,45 +272,247 @@ func (s QuestionServer) UpdateQuestion(c *fiber.Ctx) error {
 	if !valid {
 		return c.Status(fiber.StatusBadRequest).JSON(errRes)
 	}
-	questionUpdate.Id = uint(id)
+
+	errStatus, errRes := s.updateQuestion(c.UserContext(), uint(id), authorId, &questionUpdate)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(questionUpdate)
+}
+
+func (s QuestionServer) DeleteQuestion(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	errStatus, errRes := s.deleteQuestion(c.UserContext(), uint(id), authorId)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return nil
+}
+
+// deleteQuestion deletes the question when the author is the question author, emitting, publishing and auditing
+// its deletion. Deleting a missing question does nothing
+func (s QuestionServer) deleteQuestion(ctx context.Context, id uint, authorId uint) (int, any) {
+	err := s.questionRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		question, err := s.questionRepository.GetQuestion(txCtx, id, questionPreloads...)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !canEditQuestion(question, authorId) {
+			return errNotQuestionAuthor
+		}
+
+		err = s.questionRepository.DeleteQuestion(txCtx, id)
+		if err != nil {
+			return err
+		}
+
+		err = outbox.Emit(txCtx, s.outboxRepository, entity.WebhookEventQuestionDeleted, webhook.QuestionDeleted{Id: id})
+		if err != nil {
+			return err
+		}
+
+		err = publishQuestionEvent(txCtx, s.questionEvents, entity.WebhookEventQuestionDeleted, question, webhook.QuestionDeleted{Id: id})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionDelete, entity.AuditEntityQuestion, id, question, nil)
+	})
+	if errors.Is(err, errNotQuestionAuthor) {
+		return fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete question")
+		return fiber.StatusInternalServerError, ErrorResponse{Error: "Internal error"}
+	}
+
+	return 0, nil
+}
+
+// updateQuestion replaces the question, its options and tags when the author is the question author.
+// Options are matched by id so the ones kept by the update keep their identity
+func (s QuestionServer) updateQuestion(ctx context.Context, id uint, authorId uint, questionUpdate *entity.Question) (int, any) {
+	questionUpdate.Id = id
+	setOrderingOptionsCorrect(questionUpdate)
+	sanitizeQuestion(questionUpdate)
 
 	// Check if question author is the auth user
-	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id))
+	question, err := s.questionRepository.GetQuestion(ctx, id, questionOptionsPreload)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+		return fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
 	}
-	if question.AuthorId != authorId {
-		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	if !canEditQuestion(question, authorId) {
+		return fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+	errStatus, errRes := checkQuestionAttachments(ctx, s.attachmentRepository, *questionUpdate, authorId)
+	if errRes != nil {
+		return errStatus, errRes
 	}
 
-	err = s.questionRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+	// Options with an id keep the identity of that option of the question
+	optionIds := map[uint]bool{}
+	for _, questionOption := range question.QuestionOptions {
+		optionIds[questionOption.Id] = true
+	}
+	for _, questionOption := range questionUpdate.QuestionOptions {
+		if questionOption.Id == 0 {
+			continue
+		}
+		if !optionIds[questionOption.Id] {
+			return fiber.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Option %d is not an option of the question or is repeated", questionOption.Id)}
+		}
+		delete(optionIds, questionOption.Id)
+	}
+	questionUpdate.Revision = question.Revision + 1
+
+	err = s.questionRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
 		// Update question
-		err = s.questionRepository.UpdateQuestion(c.UserContext(), uint(id), &questionUpdate)
+		err := s.questionRepository.UpdateQuestion(txCtx, id, questionUpdate)
+		if err != nil {
+			return err
+		}
+
+		err = s.questionOptionRepository.BulkReplaceQuestionOptions(txCtx, id, questionUpdate.QuestionOptions)
+		if err != nil {
+			return err
+		}
+
+		err = s.questionTagRepository.BulkReplaceQuestionTags(txCtx, id, questionUpdate.Tags)
 		if err != nil {
 			return err
 		}
 
-		return s.questionOptionRepository.BulkReplaceQuestionOptions(c.UserContext(), uint(id), questionUpdate.QuestionOptions)
+		err = outbox.Emit(txCtx, s.outboxRepository, entity.WebhookEventQuestionUpdated, questionUpdate)
+		if err != nil {
+			return err
+		}
+
+		published := *questionUpdate
+		published.AuthorId = question.AuthorId
+		err = publishQuestionEvent(txCtx, s.questionEvents, entity.WebhookEventQuestionUpdated, published, questionUpdate)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionUpdate, entity.AuditEntityQuestion, id, question, questionUpdate)
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to update question")
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+		return fiber.StatusInternalServerError, ErrorResponse{Error: "Internal error"}
 	}
 
-	return c.JSON(questionUpdate)
+	return 0, nil
 }
 
-func (s QuestionServer) DeleteQuestion(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+// createQuestion creates the question with its options and tags, emitting, publishing and auditing its creation
+func (s QuestionServer) createQuestion(ctx context.Context, question *entity.Question) error {
+	resetQuestionOptionIds(question)
+	sanitizeQuestion(question)
+	return s.questionRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		// Create question
+		err := s.questionRepository.CreateQuestion(txCtx, question)
+		if err != nil {
+			return err
+		}
+
+		// Create question options
+		err = s.questionOptionRepository.BulkCreateQuestionOptions(txCtx, question.Id, question.QuestionOptions)
+		if err != nil {
+			return err
+		}
+
+		// Create question tags
+		err = s.questionTagRepository.BulkCreateQuestionTags(txCtx, question.Id, question.Tags)
+		if err != nil {
+			return err
+		}
+
+		err = outbox.Emit(txCtx, s.outboxRepository, entity.WebhookEventQuestionCreated, question)
+		if err != nil {
+			return err
+		}
+
+		err = publishQuestionEvent(txCtx, s.questionEvents, entity.WebhookEventQuestionCreated, *question, question)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionCreate, entity.AuditEntityQuestion, question.Id, nil, question)
+	})
+}
+
+// listAllQuestions pages through every question matching the options
+func listAllQuestions(ctx context.Context, questionRepository repository.QuestionRepository, opts ...gormprovider.Option) ([]entity.Question, error) {
+	const pageSize = 1000
+
+	questions := []entity.Question{}
+	var lastId *uint
+	for {
+		page, err := questionRepository.ListQuestions(ctx, pageSize, lastId, opts...)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, page...)
+		if len(page) < pageSize {
+			return questions, nil
+		}
+		lastId = &page[len(page)-1].Id
 	}
+}
 
-	err = s.questionRepository.DeleteQuestion(c.UserContext(), uint(id))
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to delete question")
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+// setOrderingOptionsCorrect marks every option of ordering questions as correct,
+// since they are answered by their order instead of being selected
+func setOrderingOptionsCorrect(question *entity.Question) {
+	if question.GetType() != entity.QuestionTypeOrdering {
+		return
+	}
+	for i := range question.QuestionOptions {
+		correct := true
+		question.QuestionOptions[i].Correct = &correct
 	}
+}
 
-	return nil
+// errNotQuestionAuthor rolls back a transaction that loaded a question of another user
+var errNotQuestionAuthor = errors.New("user is not the question author")
+
+// canEditQuestion allows only the author to change a question
+func canEditQuestion(question entity.Question, userId uint) bool {
+	return question.AuthorId == userId
+}
+
+// HideExplanations clears the explanation and option feedback of a question the user can't review,
+// candidates only read them in the results of their finished attempts
+func HideExplanations(question *entity.Question, userId uint, role string) {
+	if canReviewQuestion(*question, userId, role) {
+		return
+	}
+	*question = copyQuestionOptions(*question)
+	question.Explanation = ""
+	question.ExplanationHTML = ""
+	for i := range question.QuestionOptions {
+		question.QuestionOptions[i].Feedback = ""
+		question.QuestionOptions[i].FeedbackHTML = ""
+	}
+}
+
+// resetQuestionOptionIds clears the option ids sent by clients, ids are assigned when options are created
+func resetQuestionOptionIds(question *entity.Question) {
+	for i := range question.QuestionOptions {
+		question.QuestionOptions[i].Id = 0
+	}
 }
@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+const (
+	// BatchModeAtomic runs every operation in one transaction, the first failure rolls back the others
+	BatchModeAtomic = "atomic"
+	// BatchModeIndependent runs every operation on its own
+	BatchModeIndependent = "independent"
+)
+
+var errBatchRolledBack = errors.New("batch rolled back")
+
+type BatchQuestionsRequest struct {
+	Mode       string           `json:"mode" validate:"omitempty,oneof=atomic independent"` // Defaults to atomic
+	Operations []BatchOperation `json:"operations" validate:"required,max=1000,dive"`
+}
+
+type BatchOperation struct {
+	Op       string           `json:"op" validate:"required,oneof=create update delete"`
+	Id       uint             `json:"id" validate:"required_unless=Op create"`
+	Question *entity.Question `json:"question" validate:"-"` // Validated per operation
+}
+
+type BatchQuestionsResponse struct {
+	Results []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult has the status the operation would have had as a single request
+type BatchOperationResult struct {
+	Status   int              `json:"status"`
+	Question *entity.Question `json:"question,omitempty"`
+	Error    any              `json:"error,omitempty"`
+}
+
+// BatchQuestions runs create, update and delete operations with the same validation
+// and authorization as their single endpoints
+func (s QuestionServer) BatchQuestions(c *fiber.Ctx) error {
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Validate and parse request
+	var req BatchQuestionsRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	res := BatchQuestionsResponse{Results: make([]BatchOperationResult, len(req.Operations))}
+	if req.Mode == BatchModeIndependent {
+		for i, operation := range req.Operations {
+			res.Results[i] = s.runBatchOperation(c.UserContext(), authorId, operation)
+		}
+		return c.JSON(res)
+	}
+
+	failed := false
+	err = s.questionRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		for i, operation := range req.Operations {
+			res.Results[i] = s.runBatchOperation(txCtx, authorId, operation)
+			if res.Results[i].Error != nil {
+				failed = true
+				return errBatchRolledBack
+			}
+		}
+		return nil
+	})
+	if failed {
+		// Operations that succeeded were rolled back and the ones after the failure did not run
+		for i := range res.Results {
+			if res.Results[i].Error == nil {
+				res.Results[i] = BatchOperationResult{Status: fiber.StatusFailedDependency, Error: ErrorResponse{Error: "Rolled back"}}
+			}
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(res)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to run question batch")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(res)
+}
+
+func (s QuestionServer) runBatchOperation(ctx context.Context, authorId uint, operation BatchOperation) BatchOperationResult {
+	if operation.Op == BatchOpDelete {
+		errStatus, errRes := s.deleteQuestion(ctx, operation.Id, authorId)
+		if errRes != nil {
+			return BatchOperationResult{Status: errStatus, Error: errRes}
+		}
+		return BatchOperationResult{Status: fiber.StatusOK}
+	}
+
+	if operation.Question == nil {
+		return BatchOperationResult{Status: fiber.StatusBadRequest, Error: ErrorResponse{Error: "question is required"}}
+	}
+	question := *operation.Question
+	if errRes, valid := validateStruct(&question); !valid {
+		return BatchOperationResult{Status: fiber.StatusBadRequest, Error: errRes}
+	}
+
+	if operation.Op == BatchOpUpdate {
+		errStatus, errRes := s.updateQuestion(ctx, operation.Id, authorId, &question)
+		if errRes != nil {
+			return BatchOperationResult{Status: errStatus, Error: errRes}
+		}
+		return BatchOperationResult{Status: fiber.StatusOK, Question: &question}
+	}
+
+	question.Id = 0
+	question.AuthorId = authorId
+	setOrderingOptionsCorrect(&question)
+	errStatus, errRes := checkQuestionAttachments(ctx, s.attachmentRepository, question, authorId)
+	if errRes != nil {
+		return BatchOperationResult{Status: errStatus, Error: errRes}
+	}
+	err := s.createQuestion(ctx, &question)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create questions")
+		return BatchOperationResult{Status: fiber.StatusInternalServerError, Error: ErrorResponse{Error: "Internal error"}}
+	}
+	return BatchOperationResult{Status: fiber.StatusOK, Question: &question}
+}
@@ -0,0 +1,137 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchQuestions(t *testing.T) {
+	var authorId uint = 1
+	correct := true
+	incorrect := false
+	newQuestion := func(body string) *entity.Question {
+		return &entity.Question{Body: body, QuestionOptions: []entity.QuestionOption{{Body: "a", Correct: &correct}, {Body: "b", Correct: &incorrect}}}
+	}
+	ownQuestion := entity.Question{Id: 10, AuthorId: authorId, Revision: 1}
+	otherQuestion := entity.Question{Id: 20, AuthorId: 2, Revision: 1}
+
+	type Test struct {
+		TestName               string
+		Req                    httpserver.BatchQuestionsRequest
+		ExpectedHttpStatusCode int
+		ExpectedStatuses       []int
+	}
+	tests := []Test{
+		{
+			TestName: "AtomicSuccess",
+			Req: httpserver.BatchQuestionsRequest{Operations: []httpserver.BatchOperation{
+				{Op: httpserver.BatchOpCreate, Question: newQuestion("created")},
+				{Op: httpserver.BatchOpUpdate, Id: ownQuestion.Id, Question: newQuestion("updated")},
+				{Op: httpserver.BatchOpDelete, Id: ownQuestion.Id},
+			}},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedStatuses:       []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			TestName: "AtomicRolledBack",
+			Req: httpserver.BatchQuestionsRequest{Operations: []httpserver.BatchOperation{
+				{Op: httpserver.BatchOpCreate, Question: newQuestion("created")},
+				{Op: httpserver.BatchOpCreate, Question: newQuestion("")},
+				{Op: httpserver.BatchOpDelete, Id: ownQuestion.Id},
+			}},
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+			ExpectedStatuses:       []int{http.StatusFailedDependency, http.StatusBadRequest, http.StatusFailedDependency},
+		},
+		{
+			TestName: "Independent",
+			Req: httpserver.BatchQuestionsRequest{Mode: httpserver.BatchModeIndependent, Operations: []httpserver.BatchOperation{
+				{Op: httpserver.BatchOpCreate, Question: newQuestion("created")},
+				{Op: httpserver.BatchOpUpdate, Id: otherQuestion.Id, Question: newQuestion("updated")},
+				{Op: httpserver.BatchOpUpdate, Id: ownQuestion.Id},
+				{Op: httpserver.BatchOpDelete, Id: otherQuestion.Id},
+			}},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedStatuses:       []int{http.StatusOK, http.StatusUnauthorized, http.StatusBadRequest, http.StatusUnauthorized},
+		},
+		{
+			TestName: "InvalidOperation",
+			Req: httpserver.BatchQuestionsRequest{Operations: []httpserver.BatchOperation{
+				{Op: "upsert", Question: newQuestion("created")},
+			}},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.ExpectedHttpStatusCode != http.StatusBadRequest {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, question *entity.Question) error {
+						assert.Equal(t, authorId, question.AuthorId)
+						question.Id = 1
+						return nil
+					})
+				questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+			switch test.TestName {
+			case "AtomicSuccess":
+				questionRepository.On("GetQuestion", mock.Anything, ownQuestion.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).Return(ownQuestion, nil)
+				questionRepository.On("UpdateQuestion", mock.Anything, ownQuestion.Id, mock.Anything).Return(nil)
+				questionOptionRepository.On("BulkReplaceQuestionOptions", mock.Anything, ownQuestion.Id, mock.Anything).Return(nil)
+				questionTagRepository.On("BulkReplaceQuestionTags", mock.Anything, ownQuestion.Id, mock.Anything).Return(nil)
+				questionRepository.On("GetQuestion", mock.Anything, ownQuestion.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).Return(ownQuestion, nil)
+				questionRepository.On("DeleteQuestion", mock.Anything, ownQuestion.Id).Return(nil)
+			case "Independent":
+				questionRepository.On("GetQuestion", mock.Anything, otherQuestion.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).Return(otherQuestion, nil)
+				questionRepository.On("GetQuestion", mock.Anything, otherQuestion.Id, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).Return(otherQuestion, nil)
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/questions/batch", bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode == http.StatusBadRequest {
+				return
+			}
+
+			var resBody httpserver.BatchQuestionsResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resBody))
+			statuses := make([]int, len(resBody.Results))
+			for i, result := range resBody.Results {
+				statuses[i] = result.Status
+			}
+			assert.Equal(t, test.ExpectedStatuses, statuses)
+		})
+	}
+}
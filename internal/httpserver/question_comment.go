@@ -0,0 +1,300 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type QuestionCommentServer struct {
+	questionRepository        repository.QuestionRepository
+	questionCommentRepository repository.QuestionCommentRepository
+	auditEntryRepository      repository.AuditEntryRepository
+}
+
+// NewQuestionCommentServer returns the routes to register under /questions/:id/comments.
+// Fiber does not resolve params in mount prefixes, so these routes are not a mounted app.
+func NewQuestionCommentServer(
+	questionRepository repository.QuestionRepository,
+	questionCommentRepository repository.QuestionCommentRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+) func(router fiber.Router) {
+	server := &QuestionCommentServer{questionRepository, questionCommentRepository, auditEntryRepository}
+	return func(router fiber.Router) {
+		router.Use(newJwtAuth())
+		router.Get("/", server.ListQuestionComments)
+		router.Post("/", server.CreateQuestionComment)
+		router.Put("/:commentId", server.UpdateQuestionComment)
+		router.Delete("/:commentId", server.DeleteQuestionComment)
+		router.Post("/:commentId/resolve", server.ResolveQuestionComment)
+		router.Post("/:commentId/unresolve", server.UnresolveQuestionComment)
+	}
+}
+
+type CreateQuestionCommentRequest struct {
+	Body     string `json:"body" validate:"required"`
+	OptionId *uint  `json:"optionId"`
+	ParentId *uint  `json:"parentId"`
+}
+
+type UpdateQuestionCommentRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+func (s QuestionCommentServer) ListQuestionComments(c *fiber.Ctx) error {
+	question, errStatus, errRes := s.getReviewableQuestion(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	questionComments, err := s.questionCommentRepository.ListQuestionComments(c.UserContext(), question.Id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list question comments")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(buildQuestionCommentThreads(questionComments))
+}
+
+func (s QuestionCommentServer) CreateQuestionComment(c *fiber.Ctx) error {
+	question, errStatus, errRes := s.getReviewableQuestion(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	// Validate and parse request
+	var req CreateQuestionCommentRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Check if referenced option belongs to the question
+	if req.OptionId != nil {
+		found := false
+		for _, questionOption := range question.QuestionOptions {
+			if questionOption.Id == *req.OptionId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "optionId is invalid"})
+		}
+	}
+
+	// Check if parent comment belongs to the question
+	if req.ParentId != nil {
+		_, err := s.questionCommentRepository.GetQuestionComment(c.UserContext(), question.Id, *req.ParentId)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "parentId is invalid"})
+		}
+	}
+
+	authorId, _ := getAuthUserId(c)
+	questionComment := entity.QuestionComment{
+		Body:             req.Body,
+		QuestionId:       question.Id,
+		QuestionOptionId: req.OptionId,
+		ParentId:         req.ParentId,
+		AuthorId:         authorId,
+	}
+	err := s.questionCommentRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		err := s.questionCommentRepository.CreateQuestionComment(txCtx, &questionComment)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionCreate, entity.AuditEntityQuestionComment, questionComment.Id, nil, questionComment)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create question comment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(questionComment)
+}
+
+func (s QuestionCommentServer) UpdateQuestionComment(c *fiber.Ctx) error {
+	questionComment, errStatus, errRes := s.getOwnQuestionComment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	// Validate and parse request
+	var req UpdateQuestionCommentRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	before := questionComment
+	questionComment.Body = req.Body
+
+	err := s.updateQuestionComment(c.UserContext(), entity.AuditActionUpdate, before, &questionComment)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update question comment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(questionComment)
+}
+
+func (s QuestionCommentServer) DeleteQuestionComment(c *fiber.Ctx) error {
+	questionComment, errStatus, errRes := s.getOwnQuestionComment(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.questionCommentRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		err := s.questionCommentRepository.DeleteQuestionComment(txCtx, questionComment.Id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionDelete, entity.AuditEntityQuestionComment, questionComment.Id, questionComment, nil)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete question comment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return nil
+}
+
+func (s QuestionCommentServer) ResolveQuestionComment(c *fiber.Ctx) error {
+	return s.setQuestionCommentResolved(c, true)
+}
+
+func (s QuestionCommentServer) UnresolveQuestionComment(c *fiber.Ctx) error {
+	return s.setQuestionCommentResolved(c, false)
+}
+
+func (s QuestionCommentServer) setQuestionCommentResolved(c *fiber.Ctx, resolved bool) error {
+	question, errStatus, errRes := s.getReviewableQuestion(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	commentId, err := c.ParamsInt("commentId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "commentId is invalid"})
+	}
+	questionComment, err := s.questionCommentRepository.GetQuestionComment(c.UserContext(), question.Id, uint(commentId))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+
+	// Only threads are resolved, replies follow their thread
+	if questionComment.ParentId != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Only top level comments can be resolved"})
+	}
+
+	before := questionComment
+	questionComment.Resolved = resolved
+	action := entity.AuditActionResolve
+	if !resolved {
+		action = entity.AuditActionUnresolve
+	}
+	err = s.updateQuestionComment(c.UserContext(), action, before, &questionComment)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update question comment")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(questionComment)
+}
+
+// updateQuestionComment saves the comment and audits the change from before
+func (s QuestionCommentServer) updateQuestionComment(ctx context.Context, action string, before entity.QuestionComment, questionComment *entity.QuestionComment) error {
+	return s.questionCommentRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := s.questionCommentRepository.UpdateQuestionComment(txCtx, questionComment.Id, questionComment)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, action, entity.AuditEntityQuestionComment, questionComment.Id, before, questionComment)
+	})
+}
+
+// getReviewableQuestion loads the question in the url and checks that the auth user
+// is either its author or a reviewer
+func (s QuestionCommentServer) getReviewableQuestion(c *fiber.Ctx) (entity.Question, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Question{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Question{}, fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id), questionOptionsPreload)
+	if err != nil {
+		return entity.Question{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	if !canReviewQuestion(question, userId, getAuthUserRole(c)) {
+		return entity.Question{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return question, 0, nil
+}
+
+// getOwnQuestionComment loads the comment in the url and checks that the auth user wrote it
+func (s QuestionCommentServer) getOwnQuestionComment(c *fiber.Ctx) (entity.QuestionComment, int, any) {
+	question, errStatus, errRes := s.getReviewableQuestion(c)
+	if errRes != nil {
+		return entity.QuestionComment{}, errStatus, errRes
+	}
+
+	commentId, err := c.ParamsInt("commentId")
+	if err != nil {
+		return entity.QuestionComment{}, fiber.StatusBadRequest, ErrorResponse{Error: "commentId is invalid"}
+	}
+
+	questionComment, err := s.questionCommentRepository.GetQuestionComment(c.UserContext(), question.Id, uint(commentId))
+	if err != nil {
+		return entity.QuestionComment{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+
+	userId, _ := getAuthUserId(c)
+	if questionComment.AuthorId != userId && getAuthUserRole(c) != RoleAdmin {
+		return entity.QuestionComment{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return questionComment, 0, nil
+}
+
+func canReviewQuestion(question entity.Question, userId uint, role string) bool {
+	return question.AuthorId == userId || role == RoleReviewer || role == RoleAdmin
+}
+
+// buildQuestionCommentThreads nests replies under their parent comment, keeping the id order
+func buildQuestionCommentThreads(questionComments []entity.QuestionComment) []entity.QuestionComment {
+	children := map[uint][]entity.QuestionComment{}
+	threads := []entity.QuestionComment{}
+	for _, questionComment := range questionComments {
+		if questionComment.ParentId == nil {
+			threads = append(threads, questionComment)
+		} else {
+			children[*questionComment.ParentId] = append(children[*questionComment.ParentId], questionComment)
+		}
+	}
+
+	var attachReplies func(questionComment *entity.QuestionComment)
+	attachReplies = func(questionComment *entity.QuestionComment) {
+		questionComment.Replies = children[questionComment.Id]
+		for i := range questionComment.Replies {
+			attachReplies(&questionComment.Replies[i])
+		}
+	}
+	for i := range threads {
+		attachReplies(&threads[i])
+	}
+
+	return threads
+}
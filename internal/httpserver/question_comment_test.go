@@ -0,0 +1,208 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListQuestionComments(t *testing.T) {
+	var questionId uint = 1
+	var authorId uint = 1
+	var parentId uint = 1
+	question := entity.Question{Id: questionId, Body: "question", AuthorId: authorId}
+	questionComments := []entity.QuestionComment{
+		{Id: 1, Body: "thread", QuestionId: questionId, AuthorId: 2},
+		{Id: 2, Body: "reply", QuestionId: questionId, ParentId: &parentId, AuthorId: authorId},
+	}
+	threads := []entity.QuestionComment{questionComments[0]}
+	threads[0].Replies = []entity.QuestionComment{questionComments[1]}
+	expectedResponseBytes, err := json.Marshal(threads)
+	require.NoError(t, err)
+
+	type Test struct {
+		TestName               string
+		UserId                 uint
+		Role                   string
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Author",
+			UserId:                 authorId,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Reviewer",
+			UserId:                 2,
+			Role:                   httpserver.RoleReviewer,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Unauthorized",
+			UserId:                 2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+				Return(question, nil)
+
+			questionCommentRepository := mocks.NewQuestionCommentRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionCommentRepository.On("ListQuestionComments", mock.Anything, questionId).
+					Return(questionComments, nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository, QuestionComment: questionCommentRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/questions/%d/comments", questionId), nil)
+			req.Header.Set("Authorization", newAuthHeader(t, test.UserId, test.Role))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				resBodyBytes, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, expectedResponseBytes, resBodyBytes)
+			}
+		})
+	}
+}
+
+func TestCreateQuestionComment(t *testing.T) {
+	var questionId uint = 1
+	var authorId uint = 1
+	var reviewerId uint = 2
+	var optionId uint = 3
+	var unknownOptionId uint = 4
+	question := entity.Question{
+		Id:              questionId,
+		Body:            "question",
+		QuestionOptions: []entity.QuestionOption{{Id: optionId, Body: "option", QuestionId: questionId}},
+		AuthorId:        authorId,
+	}
+
+	type Test struct {
+		TestName               string
+		Req                    httpserver.CreateQuestionCommentRequest
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Success",
+			Req:                    httpserver.CreateQuestionCommentRequest{Body: "option is also correct", OptionId: &optionId},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "MissingBody",
+			Req:                    httpserver.CreateQuestionCommentRequest{},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "UnknownOption",
+			Req:                    httpserver.CreateQuestionCommentRequest{Body: "comment", OptionId: &unknownOptionId},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+				Return(question, nil)
+
+			questionCommentRepository := mocks.NewQuestionCommentRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionCommentRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionCommentRepository.On("CreateQuestionComment", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, questionComment *entity.QuestionComment) error {
+						assert.Equal(t, questionId, questionComment.QuestionId)
+						assert.Equal(t, reviewerId, questionComment.AuthorId)
+						assert.Equal(t, test.Req.OptionId, questionComment.QuestionOptionId)
+						questionComment.Id = 1
+						return nil
+					})
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:        questionRepository,
+				QuestionComment: questionCommentRepository,
+				AuditEntry:      newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/questions/%d/comments", questionId), bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, reviewerId, httpserver.RoleReviewer))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestResolveQuestionComment(t *testing.T) {
+	var questionId uint = 1
+	var authorId uint = 1
+	var commentId uint = 5
+	question := entity.Question{Id: questionId, Body: "question", AuthorId: authorId}
+
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+		Return(question, nil)
+
+	questionCommentRepository := mocks.NewQuestionCommentRepository(t)
+	questionCommentRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionCommentRepository.On("GetQuestionComment", mock.Anything, questionId, commentId).
+		Return(entity.QuestionComment{Id: commentId, Body: "comment", QuestionId: questionId, AuthorId: 2}, nil)
+	questionCommentRepository.On("UpdateQuestionComment", mock.Anything, commentId, mock.Anything).
+		Return(func(_ context.Context, _ uint, questionComment *entity.QuestionComment) error {
+			assert.True(t, questionComment.Resolved)
+			return nil
+		})
+
+	auditEntryRepository := mocks.NewAuditEntryRepository(t)
+	auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, auditEntry *entity.AuditEntry) error {
+			assert.Equal(t, authorId, auditEntry.ActorId)
+			assert.Equal(t, entity.AuditActionResolve, auditEntry.Action)
+			assert.Equal(t, entity.AuditEntityQuestionComment, auditEntry.EntityType)
+			assert.Equal(t, commentId, auditEntry.EntityId)
+			assert.Contains(t, string(auditEntry.Before), `"resolved":false`)
+			assert.Contains(t, string(auditEntry.After), `"resolved":true`)
+			return nil
+		})
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:        questionRepository,
+		QuestionComment: questionCommentRepository,
+		AuditEntry:      auditEntryRepository,
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/questions/%d/comments/%d/resolve", questionId, commentId), nil)
+	req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"bufio"
+	"challenge/internal/entity"
+	"challenge/internal/eventbus"
+	"challenge/pkg/env"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newQuestionEventsHeartbeat reads how often idle event streams send a comment to keep them open, 15 seconds by default
+func newQuestionEventsHeartbeat() time.Duration {
+	return env.GetDurationOrDefault("QUESTION_EVENTS_HEARTBEAT", 15*time.Second)
+}
+
+type StreamQuestionEventsRequest struct {
+	AuthorId    uint   `query:"authorId"`
+	Tags        string `query:"tags"`        // Separated by commas
+	LastEventId string `query:"lastEventId"` // The Last-Event-ID header takes precedence
+}
+
+// StreamQuestionEvents sends the question changes as server-sent events, with the webhook event type and payload.
+// Clients resume from the Last-Event-ID header browsers send when reconnecting, as long as the event is still kept
+func (s QuestionServer) StreamQuestionEvents(c *fiber.Ctx) error {
+	var req StreamQuestionEventsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	filter := eventbus.Filter{AuthorId: req.AuthorId}
+	if req.Tags != "" {
+		filter.Tags = strings.Split(req.Tags, ",")
+	}
+	subscription := s.questionEvents.Subscribe(filter, c.Get("Last-Event-ID", req.LastEventId))
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The stream ends when a write fails because the client is gone, or when it is too slow to keep up
+	heartbeat := s.questionEventsHeartbeat
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		fmt.Fprint(w, ": connected\n\n")
+		for {
+			if err := w.Flush(); err != nil {
+				return
+			}
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Data)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+		}
+	})
+
+	return nil
+}
+
+// publishQuestionEvent publishes the change of the question to the event streams once the transaction in ctx commits
+func publishQuestionEvent(ctx context.Context, questionEvents *eventbus.Bus, eventType string, question entity.Question, data any) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tags := []string{}
+	for _, tag := range question.Tags {
+		tags = append(tags, tag.Name)
+	}
+	gormprovider.AfterCommit(ctx, func() {
+		questionEvents.Publish(eventbus.Event{
+			Type:       eventType,
+			QuestionId: question.Id,
+			AuthorId:   question.AuthorId,
+			Tags:       tags,
+			Data:       dataBytes,
+		})
+	})
+	return nil
+}
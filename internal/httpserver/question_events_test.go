@@ -0,0 +1,202 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is an event or comment read from a stream of server-sent events
+type sseEvent struct {
+	Id      string
+	Type    string
+	Data    string
+	Comment string
+}
+
+// openEventStream connects to the question events of the server listening at addr and
+// returns the events and comments it reads, the stream is closed with the test
+func openEventStream(t *testing.T, addr string, query string, lastEventId string) <-chan sseEvent {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/questions/events%s", addr, query), nil)
+	require.NoError(t, err)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		res.Body.Close()
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(res.Body)
+		event := sseEvent{}
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			case strings.HasPrefix(line, ": "):
+				event.Comment = strings.TrimPrefix(line, ": ")
+			case strings.HasPrefix(line, "id: "):
+				event.Id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "stream closed")
+		return event
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no event")
+		return sseEvent{}
+	}
+}
+
+func TestStreamQuestionEvents(t *testing.T) {
+	t.Setenv("QUESTION_EVENTS_HEARTBEAT", "50ms")
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			question.Id = 1
+			return nil
+		})
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil)
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		Outbox:         newOutboxRepository(t),
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Listener(listener)
+	t.Cleanup(func() {
+		server.Shutdown()
+	})
+	addr := listener.Addr().String()
+
+	all := openEventStream(t, addr, "", "")
+	tagged := openEventStream(t, addr, "?tags=sql,go", "")
+	otherAuthor := openEventStream(t, addr, "?authorId=2", "")
+	for _, events := range []<-chan sseEvent{all, tagged, otherAuthor} {
+		assert.Equal(t, "connected", nextEvent(t, events).Comment)
+	}
+
+	reqBody := `{"body": "Which?", "options": [{"body": "this", "correct": true}, {"body": "that", "correct": false}], "tags": ["go"]}`
+	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader([]byte(reqBody)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	created := nextEvent(t, all)
+	assert.NotEmpty(t, created.Id)
+	assert.Equal(t, entity.WebhookEventQuestionCreated, created.Type)
+	assert.Contains(t, created.Data, `"body":"Which?"`)
+	assert.Equal(t, created, nextEvent(t, tagged))
+
+	// Idle streams get heartbeats, and events of other authors are not sent
+	assert.Equal(t, "heartbeat", nextEvent(t, otherAuthor).Comment)
+	assert.Equal(t, "heartbeat", nextEvent(t, all).Comment)
+
+	// Reconnecting clients get the events published after the last one they received
+	assert.Equal(t, "connected", nextEvent(t, openEventStream(t, addr, "", created.Id)).Comment)
+	resumed := openEventStream(t, addr, "", "0")
+	assert.Equal(t, "connected", nextEvent(t, resumed).Comment)
+	assert.Equal(t, created, nextEvent(t, resumed))
+	resumed = openEventStream(t, addr, "?lastEventId=0&tags=sql", "")
+	assert.Equal(t, "connected", nextEvent(t, resumed).Comment)
+	assert.Equal(t, "heartbeat", nextEvent(t, resumed).Comment)
+}
+
+func TestStreamQuestionOptionEvents(t *testing.T) {
+	t.Setenv("QUESTION_EVENTS_HEARTBEAT", "50ms")
+	correct := true
+	incorrect := false
+	question := entity.Question{
+		Id:   1,
+		Body: "Which?",
+		QuestionOptions: []entity.QuestionOption{
+			{Id: 11, Body: "this", Correct: &correct},
+			{Id: 12, Body: "that", Correct: &incorrect},
+		},
+		Tags:     []entity.QuestionTag{{Name: "go"}},
+		AuthorId: 1,
+		Revision: 1,
+	}
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, question.Id, mock.Anything, mock.Anything).Return(question, nil)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("UpdateQuestion", mock.Anything, question.Id, mock.Anything).Return(nil)
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkReplaceQuestionOptions", mock.Anything, question.Id, mock.Anything).Return(nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		Outbox:         newOutboxRepository(t),
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Listener(listener)
+	t.Cleanup(func() {
+		server.Shutdown()
+	})
+
+	tagged := openEventStream(t, listener.Addr().String(), "?tags=go&authorId=1", "")
+	assert.Equal(t, "connected", nextEvent(t, tagged).Comment)
+
+	reqBody := `{"body": "these", "correct": true}`
+	req := httptest.NewRequest(http.MethodPut, "/questions/1/options/11", bytes.NewReader([]byte(reqBody)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	updated := nextEvent(t, tagged)
+	assert.Equal(t, entity.WebhookEventQuestionUpdated, updated.Type)
+	assert.Contains(t, updated.Data, `"body":"these"`)
+}
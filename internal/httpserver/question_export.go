@@ -0,0 +1,85 @@
+package httpserver
+
+import (
+	"bufio"
+	"challenge/internal/exporter"
+	"challenge/internal/repository"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportVersionHeader carries the exporter.FormatVersion of exports, imports check it when present
+const ExportVersionHeader = "X-Export-Version"
+
+// exportPageSize is the number of questions loaded at a time while streaming an export
+const exportPageSize = 100
+
+type ExportQuestionsRequest struct {
+	Format     string `query:"format" validate:"oneof=json ndjson csv qti"`
+	AuthorId   uint   `query:"authorId"`
+	Difficulty string `query:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Tags       string `query:"tags"` // Separated by commas
+}
+
+// ExportQuestions streams every question matching the filter in ?format=json|ndjson|csv|qti
+func (s QuestionServer) ExportQuestions(c *fiber.Ctx) error {
+	req := ExportQuestionsRequest{Format: exporter.FormatJSON}
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+	role := getAuthUserRole(c)
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if errRes, valid := validateStruct(req); !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	questionFilter := repository.QuestionFilter{AuthorId: req.AuthorId, Difficulty: req.Difficulty}
+	if req.Tags != "" {
+		questionFilter.Tags = strings.Split(req.Tags, ",")
+	}
+
+	c.Set(fiber.HeaderContentType, exporter.ContentType(req.Format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="questions.%s"`, exporter.FileExtension(req.Format)))
+	c.Set(ExportVersionHeader, exporter.FormatVersion)
+
+	// The status is sent before the questions are read, so failures can only end the stream early
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, _ := exporter.NewWriter(req.Format, w)
+		var lastId *uint
+		for {
+			questions, err := s.questionRepository.ListQuestions(ctx, exportPageSize, lastId, withQuestionPreloads(questionFilter)...)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to list questions to export")
+				return
+			}
+			for _, question := range questions {
+				HideExplanations(&question, userId, role)
+				if err := writer.WriteQuestion(question); err != nil {
+					log.Error().Err(err).Msg("Failed to export question")
+					return
+				}
+			}
+			if err := w.Flush(); err != nil {
+				log.Error().Err(err).Msg("Failed to export questions")
+				return
+			}
+			if len(questions) < exportPageSize {
+				break
+			}
+			lastId = &questions[len(questions)-1].Id
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to export questions")
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package httpserver_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/exporter"
+	"challenge/internal/httpserver"
+	"challenge/internal/qti"
+	"challenge/internal/repository"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportQuestions(t *testing.T) {
+	correct := true
+	questions := []entity.Question{
+		{Id: 1, Body: "first", QuestionOptions: []entity.QuestionOption{{Body: "a", Correct: &correct}}},
+		{Id: 2, Body: "second", QuestionOptions: []entity.QuestionOption{{Body: "b", Correct: &correct}}},
+	}
+
+	type Test struct {
+		TestName               string
+		Query                  string
+		ExpectedFilter         repository.QuestionFilter
+		ExpectedContentType    string
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "DefaultJSON",
+			ExpectedContentType:    "application/json",
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "FilteredNDJSON",
+			Query:                  "?format=ndjson&authorId=2&difficulty=hard&tags=go,sql",
+			ExpectedFilter:         repository.QuestionFilter{AuthorId: 2, Difficulty: entity.DifficultyHard, Tags: []string{"go", "sql"}},
+			ExpectedContentType:    "application/x-ndjson",
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "QTI",
+			Query:                  "?format=qti",
+			ExpectedContentType:    "application/zip",
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "UnknownFormat",
+			Query:                  "?format=xml",
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionRepository.On(
+					"ListQuestions", mock.Anything, uint(100), (*uint)(nil),
+					gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags"), test.ExpectedFilter,
+				).Return(questions, nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, "/questions/export"+test.Query, nil)
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, test.ExpectedContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, exporter.FormatVersion, res.Header.Get(httpserver.ExportVersionHeader))
+			resBodyBytes, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			if test.ExpectedContentType == "application/zip" {
+				items, err := qti.ReadPackage(resBodyBytes, 1<<20)
+				require.NoError(t, err)
+				assert.Len(t, items, len(questions))
+				return
+			}
+			assert.Contains(t, string(resBodyBytes), "first")
+			assert.Contains(t, string(resBodyBytes), "second")
+			if test.ExpectedContentType == "application/json" {
+				var exported []entity.Question
+				require.NoError(t, json.Unmarshal(resBodyBytes, &exported))
+				assert.Equal(t, questions, exported)
+			}
+		})
+	}
+}
+
+func TestExportQuestionsHidesExplanations(t *testing.T) {
+	correct := true
+	questions := []entity.Question{{
+		Id:              1,
+		Body:            "first",
+		Explanation:     "because",
+		QuestionOptions: []entity.QuestionOption{{Body: "a", Feedback: "right", Correct: &correct}},
+		AuthorId:        1,
+	}}
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(100), (*uint)(nil), mock.Anything, mock.Anything, mock.Anything).
+		Return(questions, nil)
+	server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/questions/export", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBodyBytes, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(resBodyBytes), "first")
+	assert.NotContains(t, string(resBodyBytes), "because")
+	assert.NotContains(t, string(resBodyBytes), "right")
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/export", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, 2, httpserver.RoleAdmin))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	resBodyBytes, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(resBodyBytes), "because")
+	assert.Contains(t, string(resBodyBytes), "right")
+}
@@ -0,0 +1,49 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/env"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyKeyMaxLength = 255
+)
+
+var errIdempotencyKeyTaken = errors.New("idempotency key taken by a concurrent request")
+
+// newIdempotencyKeyTTL reads how long stored responses are replayed, 24 hours by default
+func newIdempotencyKeyTTL() time.Duration {
+	return env.GetDurationOrDefault("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+}
+
+// hashIdempotentRequest hashes the parsed request, so formatting differences in the body
+// do not count as a different payload
+func hashIdempotentRequest(req any) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// replayIdempotencyKey sends the stored response, or 422 when the key was used for another payload
+func replayIdempotencyKey(c *fiber.Ctx, idempotencyKey entity.IdempotencyKey, requestHash string) error {
+	if idempotencyKey.RequestHash != requestHash {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(ErrorResponse{Error: "Idempotency-Key was already used with a different payload"})
+	}
+	c.Set(IdempotentReplayedHeader, "true")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(idempotencyKey.StatusCode).Send(idempotencyKey.ResponseBody)
+}
@@ -0,0 +1,231 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateQuestionIdempotency(t *testing.T) {
+	var authorId uint = 1
+	correct := true
+	incorrect := false
+	newQuestionBody := func(body string) []byte {
+		question := entity.Question{Body: body, QuestionOptions: []entity.QuestionOption{{Body: "a", Correct: &correct}, {Body: "b", Correct: &incorrect}}}
+		data, err := json.Marshal(question)
+		require.NoError(t, err)
+		return data
+	}
+
+	// The key repository keeps the saved keys like the database would
+	storedKeys := map[string]entity.IdempotencyKey{}
+	idempotencyKeyRepository := mocks.NewIdempotencyKeyRepository(t)
+	idempotencyKeyRepository.On("GetIdempotencyKey", mock.Anything, authorId, mock.Anything, mock.Anything).
+		Return(
+			func(_ context.Context, _ uint, key string, _ time.Time) entity.IdempotencyKey {
+				return storedKeys[key]
+			},
+			func(_ context.Context, _ uint, key string, _ time.Time) error {
+				if _, found := storedKeys[key]; !found {
+					return gorm.ErrRecordNotFound
+				}
+				return nil
+			},
+		)
+	idempotencyKeyRepository.On("SaveIdempotencyKey", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, idempotencyKey *entity.IdempotencyKey) bool {
+			assert.Equal(t, authorId, idempotencyKey.UserId)
+			assert.True(t, idempotencyKey.ExpiresAt.After(idempotencyKey.CreatedAt))
+			storedKeys[idempotencyKey.Key] = *idempotencyKey
+			return true
+		}, nil)
+
+	var createdQuestions uint
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			createdQuestions++
+			question.Id = createdQuestions
+			return nil
+		})
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		IdempotencyKey: idempotencyKeyRepository,
+		Outbox:         newOutboxRepository(t),
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+
+	type Test struct {
+		TestName               string
+		Key                    string
+		Body                   []byte
+		ExpectedHttpStatusCode int
+		ExpectedQuestionId     uint
+		ExpectedReplayed       bool
+	}
+	tests := []Test{
+		{
+			TestName:               "FirstRequest",
+			Key:                    "first",
+			Body:                   newQuestionBody("question"),
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedQuestionId:     1,
+		},
+		{
+			TestName:               "RetryIsReplayed",
+			Key:                    "first",
+			Body:                   newQuestionBody("question"),
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedQuestionId:     1,
+			ExpectedReplayed:       true,
+		},
+		{
+			TestName:               "RetryWithDifferentFormattingIsReplayed",
+			Key:                    "first",
+			Body:                   append([]byte(" "), newQuestionBody("question")...),
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedQuestionId:     1,
+			ExpectedReplayed:       true,
+		},
+		{
+			TestName:               "KeyReusedWithDifferentPayload",
+			Key:                    "first",
+			Body:                   newQuestionBody("other question"),
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			TestName:               "OtherKey",
+			Key:                    "second",
+			Body:                   newQuestionBody("question"),
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedQuestionId:     2,
+		},
+		{
+			TestName:               "WithoutKey",
+			Body:                   newQuestionBody("question"),
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedQuestionId:     3,
+		},
+		{
+			TestName:               "KeyTooLong",
+			Key:                    strings.Repeat("k", 256),
+			Body:                   newQuestionBody("question"),
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(test.Body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			if test.Key != "" {
+				req.Header.Set(httpserver.IdempotencyKeyHeader, test.Key)
+			}
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				return
+			}
+
+			var question entity.Question
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&question))
+			assert.Equal(t, test.ExpectedQuestionId, question.Id)
+			assert.Equal(t, test.ExpectedReplayed, res.Header.Get(httpserver.IdempotentReplayedHeader) == "true")
+		})
+	}
+	assert.Equal(t, uint(3), createdQuestions)
+}
+
+func TestCreateQuestionIdempotencyConcurrentRequest(t *testing.T) {
+	var authorId uint = 1
+	correct := true
+	incorrect := false
+	question := entity.Question{Body: "question", QuestionOptions: []entity.QuestionOption{{Body: "a", Correct: &correct}, {Body: "b", Correct: &incorrect}}}
+	reqBodyBytes, err := json.Marshal(question)
+	require.NoError(t, err)
+
+	// The concurrent request stores its key between the lookup and the save of this one
+	var storedKey *entity.IdempotencyKey
+	idempotencyKeyRepository := mocks.NewIdempotencyKeyRepository(t)
+	idempotencyKeyRepository.On("GetIdempotencyKey", mock.Anything, authorId, "key", mock.Anything).
+		Return(
+			func(context.Context, uint, string, time.Time) entity.IdempotencyKey {
+				if storedKey == nil {
+					return entity.IdempotencyKey{}
+				}
+				return *storedKey
+			},
+			func(context.Context, uint, string, time.Time) error {
+				if storedKey == nil {
+					return gorm.ErrRecordNotFound
+				}
+				return nil
+			},
+		)
+	idempotencyKeyRepository.On("SaveIdempotencyKey", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, idempotencyKey *entity.IdempotencyKey) bool {
+			storedKey = &entity.IdempotencyKey{
+				RequestHash:  idempotencyKey.RequestHash,
+				StatusCode:   http.StatusOK,
+				ResponseBody: []byte(`{"id":7}`),
+			}
+			return false
+		}, nil)
+
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).Return(nil)
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		IdempotencyKey: idempotencyKeyRepository,
+		Outbox:         newOutboxRepository(t),
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(reqBodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+	req.Header.Set(httpserver.IdempotencyKeyHeader, "key")
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var resQuestion entity.Question
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resQuestion))
+	assert.Equal(t, uint(7), resQuestion.Id)
+	assert.Equal(t, "true", res.Header.Get(httpserver.IdempotentReplayedHeader))
+}
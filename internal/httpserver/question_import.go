@@ -0,0 +1,180 @@
+package httpserver
+
+import (
+	"bytes"
+	"challenge/internal/exporter"
+	"challenge/internal/importer"
+	"challenge/internal/qti"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ImportModeAllOrNothing imports nothing unless every question is valid
+	ImportModeAllOrNothing = "all_or_nothing"
+	// ImportModeBestEffort imports the valid questions and reports the others
+	ImportModeBestEffort = "best_effort"
+)
+
+var errImportRolledBack = errors.New("import rolled back")
+
+type ImportQuestionsResponse struct {
+	Imported uint              `json:"imported"`
+	Failed   uint              `json:"failed"`
+	Rows     []ImportRowReport `json:"rows"`
+}
+
+// ImportRowReport is the outcome of a question in the import file
+type ImportRowReport struct {
+	Row        int      `json:"row"` // Element or line number where the question starts
+	QuestionId *uint    `json:"questionId,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ImportQuestions creates every question in the body, in ?format=json|ndjson|csv|gift|qti and ?mode=all_or_nothing|best_effort.
+// The format defaults to the content type
+func (s QuestionServer) ImportQuestions(c *fiber.Ctx) error {
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Files exported by a newer version may not be read correctly
+	if version := c.Get(ExportVersionHeader); version != "" && version != exporter.FormatVersion {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "export version is not supported"})
+	}
+
+	mode := c.Query("mode", ImportModeAllOrNothing)
+	if mode != ImportModeAllOrNothing && mode != ImportModeBestEffort {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "mode is invalid"})
+	}
+
+	format := c.Query("format", importFormatFromContentType(string(c.Request().Header.ContentType())))
+	// Packages unpack to the body limit at most, like uncompressed files
+	items, err := importer.Parse(format, bytes.NewReader(c.Body()), int64(c.App().Config().BodyLimit))
+	if errors.Is(err, importer.ErrUnknownFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "format is invalid"})
+	}
+	if errors.Is(err, qti.ErrPackageTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	// Validate with the same rules as CreateQuestion
+	res := ImportQuestionsResponse{Rows: make([]ImportRowReport, len(items))}
+	for i := range items {
+		res.Rows[i].Row = items[i].Row
+		if items[i].Err != nil {
+			res.Rows[i].Errors = []string{items[i].Err.Error()}
+			continue
+		}
+		if errRes, valid := validateStruct(&items[i].Question); !valid {
+			res.Rows[i].Errors = validationErrorMessages(errRes.(ValidationErrorResponse))
+			continue
+		}
+		// Exported questions are created again, their ids are not kept
+		items[i].Question.Id = 0
+		items[i].Question.AuthorId = authorId
+		setOrderingOptionsCorrect(&items[i].Question)
+		errStatus, errRes := checkQuestionAttachments(c.UserContext(), s.attachmentRepository, items[i].Question, authorId)
+		if errStatus == fiber.StatusInternalServerError {
+			return c.Status(errStatus).JSON(errRes)
+		}
+		if errRes != nil {
+			res.Rows[i].Errors = []string{errRes.(ErrorResponse).Error}
+		}
+	}
+	if mode == ImportModeAllOrNothing && importHasErrors(res.Rows) {
+		res.Failed = uint(len(items))
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(res)
+	}
+
+	err = s.questionRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		for i := range items {
+			if res.Rows[i].Errors != nil {
+				continue
+			}
+			// Every question has its own savepoint so that best effort imports keep the others
+			err := s.createQuestion(txCtx, &items[i].Question)
+			if err != nil {
+				log.Error().Err(err).Int("row", items[i].Row).Msg("Failed to import question")
+				res.Rows[i].Errors = []string{"Internal error"}
+				continue
+			}
+			res.Rows[i].QuestionId = &items[i].Question.Id
+		}
+
+		if mode == ImportModeAllOrNothing && importHasErrors(res.Rows) {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRolledBack) {
+		for i := range res.Rows {
+			res.Rows[i].QuestionId = nil
+		}
+		res.Failed = uint(len(items))
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(res)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to import questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	for _, row := range res.Rows {
+		if row.Errors != nil {
+			res.Failed++
+		} else {
+			res.Imported++
+		}
+	}
+	return c.JSON(res)
+}
+
+func importFormatFromContentType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case fiber.MIMEApplicationJSON:
+		return importer.FormatJSON
+	case "application/x-ndjson":
+		return importer.FormatNDJSON
+	case "text/csv":
+		return importer.FormatCSV
+	case fiber.MIMETextPlain:
+		return importer.FormatGIFT
+	case "application/zip":
+		return importer.FormatQTI
+	default:
+		return ""
+	}
+}
+
+func importHasErrors(rows []ImportRowReport) bool {
+	for _, row := range rows {
+		if row.Errors != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validationErrorMessages flattens validation errors into "field: tag" messages
+func validationErrorMessages(errRes ValidationErrorResponse) []string {
+	messages := []string{}
+	for field, tags := range errRes.Errors {
+		for _, tag := range tags {
+			messages = append(messages, fmt.Sprintf("%s: %s", field, tag))
+		}
+	}
+	sort.Strings(messages)
+	return messages
+}
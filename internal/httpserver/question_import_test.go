@@ -0,0 +1,187 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/exporter"
+	"challenge/internal/httpserver"
+	"challenge/internal/qti"
+	"challenge/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportQuestions(t *testing.T) {
+	var authorId uint = 1
+	validCSV := "body,option,correct\nfirst,a,true\nfirst,b,false\nsecond,a,true\nsecond,b,false\n"
+	invalidCSV := "body,option,correct\nfirst,a,true\nfirst,b,false\n,b,false\n"
+
+	correct := true
+	incorrect := false
+	var qtiPackage bytes.Buffer
+	packageWriter := qti.NewPackageWriter(&qtiPackage)
+	require.NoError(t, packageWriter.WriteQuestion(entity.Question{
+		Id:              1,
+		Body:            "first",
+		QuestionOptions: []entity.QuestionOption{{Body: "a", Correct: &correct}, {Body: "b", Correct: &incorrect}},
+	}))
+	require.NoError(t, packageWriter.Close())
+
+	type Test struct {
+		TestName               string
+		Query                  string
+		ContentType            string
+		ExportVersion          string
+		Body                   string
+		ExpectedCreates        int
+		ExpectedHttpStatusCode int
+		ExpectedImported       uint
+		ExpectedFailed         uint
+	}
+	tests := []Test{
+		{
+			TestName:               "AllOrNothingSuccess",
+			ContentType:            "text/csv",
+			Body:                   validCSV,
+			ExpectedCreates:        2,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       2,
+		},
+		{
+			TestName:               "AllOrNothingInvalidRow",
+			ContentType:            "text/csv",
+			Body:                   invalidCSV,
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+			ExpectedFailed:         2,
+		},
+		{
+			TestName:               "BestEffortInvalidRow",
+			Query:                  "?mode=best_effort&format=csv",
+			ContentType:            "application/octet-stream",
+			Body:                   invalidCSV,
+			ExpectedCreates:        1,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       1,
+			ExpectedFailed:         1,
+		},
+		{
+			// The row with a stray quote is reported, the rows after it are still read
+			TestName:               "MalformedCSVRow",
+			Query:                  "?mode=best_effort",
+			ContentType:            "text/csv",
+			Body:                   "body,option,correct\n\"a\"b,c,true\nfirst,a,true\nfirst,b,false\n",
+			ExpectedCreates:        1,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       1,
+			ExpectedFailed:         1,
+		},
+		{
+			TestName:               "GIFT",
+			ContentType:            "text/plain",
+			Body:                   "Who wrote Go? {=Google ~Oracle}",
+			ExpectedCreates:        1,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       1,
+		},
+		{
+			TestName:               "QTI",
+			ContentType:            "application/zip",
+			Body:                   qtiPackage.String(),
+			ExpectedCreates:        1,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       1,
+		},
+		{
+			TestName:               "UnknownFormat",
+			ContentType:            "application/octet-stream",
+			Body:                   validCSV,
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "ExportedNDJSON",
+			ContentType:            "application/x-ndjson",
+			ExportVersion:          exporter.FormatVersion,
+			Body:                   `{"id":7,"body":"first","options":[{"body":"a","correct":true},{"body":"b","correct":false}]}` + "\n",
+			ExpectedCreates:        1,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedImported:       1,
+		},
+		{
+			TestName:               "UnsupportedExportVersion",
+			ContentType:            "application/x-ndjson",
+			ExportVersion:          "999",
+			Body:                   `{"body":"first","options":[{"body":"a","correct":true},{"body":"b","correct":false}]}`,
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "InvalidMode",
+			Query:                  "?mode=sometimes",
+			ContentType:            "text/csv",
+			Body:                   validCSV,
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.ExpectedCreates > 0 {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				var nextId uint = 1
+				questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, question *entity.Question) error {
+						assert.Equal(t, authorId, question.AuthorId)
+						assert.Zero(t, question.Id)
+						question.Id = nextId
+						nextId++
+						return nil
+					}).
+					Times(test.ExpectedCreates)
+				questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/questions/import"+test.Query, strings.NewReader(test.Body))
+			req.Header.Set("Content-Type", test.ContentType)
+			if test.ExportVersion != "" {
+				req.Header.Set(httpserver.ExportVersionHeader, test.ExportVersion)
+			}
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode == http.StatusBadRequest {
+				return
+			}
+
+			var resBody httpserver.ImportQuestionsResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resBody))
+			assert.Equal(t, test.ExpectedImported, resBody.Imported)
+			assert.Equal(t, test.ExpectedFailed, resBody.Failed)
+			for _, row := range resBody.Rows {
+				// Nothing is imported when all or nothing imports fail
+				imported := row.Errors == nil && test.ExpectedHttpStatusCode == http.StatusOK
+				assert.Equal(t, imported, row.QuestionId != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/eventbus"
+	"challenge/internal/outbox"
+	"challenge/internal/repository"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const minQuestionOptions = 2
+
+type QuestionOptionServer struct {
+	questionRepository            repository.QuestionRepository
+	questionOptionRepository      repository.QuestionOptionRepository
+	attachmentRepository          repository.AttachmentRepository
+	questionTranslationRepository repository.QuestionTranslationRepository
+	outboxRepository              repository.OutboxRepository
+	auditEntryRepository          repository.AuditEntryRepository
+	questionEvents                *eventbus.Bus
+}
+
+// NewQuestionOptionServer returns the routes to register under /questions/:id/options.
+// Fiber does not resolve params in mount prefixes, so these routes are not a mounted app.
+func NewQuestionOptionServer(
+	questionRepository repository.QuestionRepository,
+	questionOptionRepository repository.QuestionOptionRepository,
+	attachmentRepository repository.AttachmentRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+	outboxRepository repository.OutboxRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+	questionEvents *eventbus.Bus,
+) func(router fiber.Router) {
+	server := &QuestionOptionServer{
+		questionRepository,
+		questionOptionRepository,
+		attachmentRepository,
+		questionTranslationRepository,
+		outboxRepository,
+		auditEntryRepository,
+		questionEvents,
+	}
+	return func(router fiber.Router) {
+		jwtAuth := newJwtAuth()
+		router.Get("/", newOptionalJwtAuth(), server.ListQuestionOptions)
+		router.Post("/", jwtAuth, server.CreateQuestionOption)
+		router.Put("/:optionId", jwtAuth, server.UpdateQuestionOption)
+		router.Delete("/:optionId", jwtAuth, server.DeleteQuestionOption)
+		router.Put("/:optionId/position", jwtAuth, server.MoveQuestionOption)
+	}
+}
+
+type CreateQuestionOptionRequest struct {
+	Body           string `json:"body" validate:"required"`
+	BodyFormat     string `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	Feedback       string `json:"feedback"`
+	FeedbackFormat string `json:"feedbackFormat" validate:"omitempty,oneof=plain markdown"`
+	Correct        *bool  `json:"correct"`  // Required except for ordering questions
+	Position       *uint  `json:"position"` // Defaults to the end
+	AttachmentId   *uint  `json:"attachmentId"`
+}
+
+type UpdateQuestionOptionRequest struct {
+	Body           string `json:"body" validate:"required"`
+	BodyFormat     string `json:"bodyFormat" validate:"omitempty,oneof=plain markdown"`
+	Feedback       string `json:"feedback"`
+	FeedbackFormat string `json:"feedbackFormat" validate:"omitempty,oneof=plain markdown"`
+	Correct        *bool  `json:"correct"` // Required except for ordering questions
+	AttachmentId   *uint  `json:"attachmentId"`
+}
+
+type MoveQuestionOptionRequest struct {
+	Position *uint `json:"position" validate:"required"`
+}
+
+func (s QuestionOptionServer) ListQuestionOptions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+	render, errRes := parseRender(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	langs, errRes := parseLanguages(c)
+	if errRes != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id), questionOptionsPreload)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+	questions := []entity.Question{question}
+	err = translateQuestions(c.UserContext(), s.questionTranslationRepository, questions, langs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to translate question options")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	question = questions[0]
+	if render {
+		if err := renderQuestionOptionsHTML(question.QuestionOptions); err != nil {
+			log.Error().Err(err).Msg("Failed to render question options")
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+		}
+	}
+	HideExplanations(&question, userId, getAuthUserRole(c))
+
+	return c.JSON(question.QuestionOptions)
+}
+
+func (s QuestionOptionServer) CreateQuestionOption(c *fiber.Ctx) error {
+	before, errStatus, errRes := s.getOwnQuestion(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	question := copyQuestionOptions(before)
+
+	// Validate and parse request
+	var req CreateQuestionOptionRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	if req.Correct == nil && question.GetType() != entity.QuestionTypeOrdering {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "correct is required"})
+	}
+	position := uint(len(question.QuestionOptions))
+	if req.Position != nil {
+		if *req.Position > position {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "position is invalid"})
+		}
+		position = *req.Position
+	}
+
+	questionOptions := append([]entity.QuestionOption{}, question.QuestionOptions[:position]...)
+	questionOptions = append(questionOptions, entity.QuestionOption{
+		Body:           req.Body,
+		BodyFormat:     req.BodyFormat,
+		Feedback:       req.Feedback,
+		FeedbackFormat: req.FeedbackFormat,
+		Correct:        req.Correct,
+		AttachmentId:   req.AttachmentId,
+	})
+	question.QuestionOptions = append(questionOptions, question.QuestionOptions[position:]...)
+
+	errStatus, errRes = s.replaceQuestionOptions(c.UserContext(), before, &question)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(question.QuestionOptions[position])
+}
+
+func (s QuestionOptionServer) UpdateQuestionOption(c *fiber.Ctx) error {
+	before, position, errStatus, errRes := s.getOwnQuestionOption(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	question := copyQuestionOptions(before)
+
+	// Validate and parse request
+	var req UpdateQuestionOptionRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	if req.Correct == nil && question.GetType() != entity.QuestionTypeOrdering {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "correct is required"})
+	}
+	question.QuestionOptions[position].Body = req.Body
+	question.QuestionOptions[position].BodyFormat = req.BodyFormat
+	question.QuestionOptions[position].Feedback = req.Feedback
+	question.QuestionOptions[position].FeedbackFormat = req.FeedbackFormat
+	question.QuestionOptions[position].Correct = req.Correct
+	question.QuestionOptions[position].AttachmentId = req.AttachmentId
+
+	errStatus, errRes = s.replaceQuestionOptions(c.UserContext(), before, &question)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(question.QuestionOptions[position])
+}
+
+func (s QuestionOptionServer) DeleteQuestionOption(c *fiber.Ctx) error {
+	before, position, errStatus, errRes := s.getOwnQuestionOption(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	question := copyQuestionOptions(before)
+
+	question.QuestionOptions = append(question.QuestionOptions[:position], question.QuestionOptions[position+1:]...)
+
+	errStatus, errRes = s.replaceQuestionOptions(c.UserContext(), before, &question)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return nil
+}
+
+// MoveQuestionOption moves the option to the position, shifting the options in between
+func (s QuestionOptionServer) MoveQuestionOption(c *fiber.Ctx) error {
+	before, position, errStatus, errRes := s.getOwnQuestionOption(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	question := copyQuestionOptions(before)
+
+	// Validate and parse request
+	var req MoveQuestionOptionRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	if *req.Position >= uint(len(question.QuestionOptions)) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "position is invalid"})
+	}
+
+	questionOption := question.QuestionOptions[position]
+	questionOptions := append([]entity.QuestionOption{}, question.QuestionOptions[:position]...)
+	questionOptions = append(questionOptions, question.QuestionOptions[position+1:]...)
+	question.QuestionOptions = append(questionOptions[:*req.Position], append([]entity.QuestionOption{questionOption}, questionOptions[*req.Position:]...)...)
+
+	errStatus, errRes = s.replaceQuestionOptions(c.UserContext(), before, &question)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(question.QuestionOptions)
+}
+
+// replaceQuestionOptions checks the question invariants and stores its options,
+// bumping the question revision and emitting, publishing and auditing it like any other question update
+func (s QuestionOptionServer) replaceQuestionOptions(ctx context.Context, before entity.Question, question *entity.Question) (int, any) {
+	setOrderingOptionsCorrect(question)
+	sanitizeQuestion(question)
+	if errRes := checkQuestionOptions(*question); errRes != nil {
+		return fiber.StatusUnprocessableEntity, errRes
+	}
+	errStatus, errRes := checkQuestionAttachments(ctx, s.attachmentRepository, *question, question.AuthorId)
+	if errRes != nil {
+		return errStatus, errRes
+	}
+
+	err := s.questionRepository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := s.questionRepository.UpdateQuestion(txCtx, question.Id, question)
+		if err != nil {
+			return err
+		}
+
+		err = s.questionOptionRepository.BulkReplaceQuestionOptions(txCtx, question.Id, question.QuestionOptions)
+		if err != nil {
+			return err
+		}
+
+		err = outbox.Emit(txCtx, s.outboxRepository, entity.WebhookEventQuestionUpdated, question)
+		if err != nil {
+			return err
+		}
+
+		err = publishQuestionEvent(txCtx, s.questionEvents, entity.WebhookEventQuestionUpdated, *question, question)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionUpdate, entity.AuditEntityQuestion, question.Id, before, question)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update question options")
+		return fiber.StatusInternalServerError, ErrorResponse{Error: "Internal error"}
+	}
+
+	return 0, nil
+}
+
+// Validation tags of the option invariants
+const (
+	questionOptionsExcludedTag = "excluded_if"
+	questionOptionsMinTag      = "min"
+	questionOptionsCorrectTag  = "correct"
+)
+
+var questionOptionsErrors = map[string]string{
+	questionOptionsExcludedTag: "Numeric questions have no options",
+	questionOptionsMinTag:      "Questions need at least 2 options",
+	questionOptionsCorrectTag:  "Questions need at least 1 correct option",
+}
+
+// questionOptionsViolation returns the tag of the option invariant the question breaks, if any. Questions answered
+// with options have at least two options with at least one correct, except ordering questions which are answered by
+// the order of their options. Numeric questions have no options
+func questionOptionsViolation(question entity.Question) string {
+	if question.GetType() == entity.QuestionTypeNumeric {
+		if len(question.QuestionOptions) > 0 {
+			return questionOptionsExcludedTag
+		}
+		return ""
+	}
+	if len(question.QuestionOptions) < minQuestionOptions {
+		return questionOptionsMinTag
+	}
+	if question.GetType() == entity.QuestionTypeOrdering {
+		return ""
+	}
+
+	for _, questionOption := range question.QuestionOptions {
+		if questionOption.Correct != nil && *questionOption.Correct {
+			return ""
+		}
+	}
+	return questionOptionsCorrectTag
+}
+
+// checkQuestionOptions enforces the option invariants on the questions changed by the options routes,
+// the validator enforces them on the questions of the other routes
+func checkQuestionOptions(question entity.Question) any {
+	if tag := questionOptionsViolation(question); tag != "" {
+		return ErrorResponse{Error: questionOptionsErrors[tag]}
+	}
+	return nil
+}
+
+// getOwnQuestion loads the question in the url with its options and tags and checks that the auth user
+// can edit it, like UpdateQuestion does
+func (s QuestionOptionServer) getOwnQuestion(c *fiber.Ctx) (entity.Question, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Question{}, fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Question{}, fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id), questionPreloads...)
+	if err != nil {
+		return entity.Question{}, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+	if !canEditQuestion(question, authorId) {
+		return entity.Question{}, fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return question, 0, nil
+}
+
+// copyQuestionOptions returns the question with a copy of its options, so changing them doesn't change the question
+func copyQuestionOptions(question entity.Question) entity.Question {
+	question.QuestionOptions = append([]entity.QuestionOption{}, question.QuestionOptions...)
+	return question
+}
+
+// getOwnQuestionOption loads the own question in the url and finds the position of the option in the url
+func (s QuestionOptionServer) getOwnQuestionOption(c *fiber.Ctx) (entity.Question, int, int, any) {
+	question, errStatus, errRes := s.getOwnQuestion(c)
+	if errRes != nil {
+		return entity.Question{}, 0, errStatus, errRes
+	}
+
+	optionId, err := c.ParamsInt("optionId")
+	if err != nil {
+		return entity.Question{}, 0, fiber.StatusBadRequest, ErrorResponse{Error: "optionId is invalid"}
+	}
+	for position, questionOption := range question.QuestionOptions {
+		if questionOption.Id == uint(optionId) {
+			return question, position, 0, nil
+		}
+	}
+
+	return entity.Question{}, 0, fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+}
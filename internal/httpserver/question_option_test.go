@@ -0,0 +1,216 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionOptions(t *testing.T) {
+	var authorId uint = 1
+	var questionId uint = 1
+	correct := true
+	incorrect := false
+	newQuestion := func() entity.Question {
+		return entity.Question{
+			Id:   questionId,
+			Body: "question",
+			QuestionOptions: []entity.QuestionOption{
+				{Id: 11, Body: "correct", Correct: &correct},
+				{Id: 12, Body: "incorrect", Correct: &incorrect},
+				{Id: 13, Body: "other incorrect", Correct: &incorrect},
+			},
+			AuthorId: authorId,
+			Revision: 1,
+		}
+	}
+
+	type Test struct {
+		TestName               string
+		Method                 string
+		Url                    string
+		Req                    any
+		AuthorId               uint
+		ExpectedHttpStatusCode int
+		ExpectedOptionIds      []uint
+	}
+	tests := []Test{
+		{
+			TestName:               "List",
+			Method:                 http.MethodGet,
+			Url:                    "/questions/1/options",
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Create",
+			Method:                 http.MethodPost,
+			Url:                    "/questions/1/options",
+			Req:                    map[string]any{"body": "new", "correct": false, "position": 1},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedOptionIds:      []uint{11, 0, 12, 13},
+		},
+		{
+			TestName:               "CreateWithoutCorrect",
+			Method:                 http.MethodPost,
+			Url:                    "/questions/1/options",
+			Req:                    map[string]any{"body": "new"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "CreateAfterEnd",
+			Method:                 http.MethodPost,
+			Url:                    "/questions/1/options",
+			Req:                    map[string]any{"body": "new", "correct": false, "position": 4},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "Update",
+			Method:                 http.MethodPut,
+			Url:                    "/questions/1/options/12",
+			Req:                    map[string]any{"body": "also correct", "correct": true},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedOptionIds:      []uint{11, 12, 13},
+		},
+		{
+			TestName:               "UpdateLastCorrect",
+			Method:                 http.MethodPut,
+			Url:                    "/questions/1/options/11",
+			Req:                    map[string]any{"body": "now incorrect", "correct": false},
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			TestName:               "Delete",
+			Method:                 http.MethodDelete,
+			Url:                    "/questions/1/options/12",
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedOptionIds:      []uint{11, 13},
+		},
+		{
+			TestName:               "DeleteLastCorrect",
+			Method:                 http.MethodDelete,
+			Url:                    "/questions/1/options/11",
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			TestName:               "Move",
+			Method:                 http.MethodPut,
+			Url:                    "/questions/1/options/13/position",
+			Req:                    map[string]any{"position": 0},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedOptionIds:      []uint{13, 11, 12},
+		},
+		{
+			TestName:               "MoveOutOfRange",
+			Method:                 http.MethodPut,
+			Url:                    "/questions/1/options/13/position",
+			Req:                    map[string]any{"position": 3},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "UnknownOption",
+			Method:                 http.MethodDelete,
+			Url:                    "/questions/1/options/21",
+			ExpectedHttpStatusCode: http.StatusNotFound,
+		},
+		{
+			TestName:               "NotAuthor",
+			Method:                 http.MethodDelete,
+			Url:                    "/questions/1/options/12",
+			AuthorId:               2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			// Listed with the options, changed with every association
+			questionRepository.
+				On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+				Return(newQuestion(), nil).Maybe()
+			questionRepository.
+				On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).
+				Return(newQuestion(), nil).Maybe()
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			if test.ExpectedOptionIds != nil {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionRepository.On("UpdateQuestion", mock.Anything, questionId, mock.Anything).Return(nil)
+				questionOptionRepository.On("BulkReplaceQuestionOptions", mock.Anything, questionId, mock.Anything).
+					Return(func(_ context.Context, _ uint, questionOptions []entity.QuestionOption) error {
+						ids := make([]uint, len(questionOptions))
+						for i, questionOption := range questionOptions {
+							ids[i] = questionOption.Id
+						}
+						assert.Equal(t, test.ExpectedOptionIds, ids)
+						return nil
+					})
+			}
+
+			var reqBodyBytes []byte
+			if test.Req != nil {
+				var err error
+				reqBodyBytes, err = json.Marshal(test.Req)
+				require.NoError(t, err)
+			}
+
+			authorId := authorId
+			if test.AuthorId != 0 {
+				authorId = test.AuthorId
+			}
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(test.Method, test.Url, bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestListQuestionOptionsHidesFeedback(t *testing.T) {
+	correct := true
+	question := entity.Question{
+		Id:              1,
+		QuestionOptions: []entity.QuestionOption{{Id: 11, Body: "a", Feedback: "right", Correct: &correct}},
+		AuthorId:        1,
+	}
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, question.Id, mock.Anything).Return(question, nil)
+	server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+
+	var questionOptions []entity.QuestionOption
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/questions/1/options?render=html", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&questionOptions))
+	require.Len(t, questionOptions, 1)
+	assert.Empty(t, questionOptions[0].Feedback)
+	assert.Empty(t, questionOptions[0].FeedbackHTML)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/1/options", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, question.AuthorId, ""))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&questionOptions))
+	assert.Equal(t, "right", questionOptions[0].Feedback)
+}
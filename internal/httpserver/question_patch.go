@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/jsonpatch"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// PatchQuestion applies a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) to the question.
+// In merge patches, options can also be given as an object keyed by option id, where each member
+// is merged into that option and null removes it.
+// The patched question is validated like a whole question sent to UpdateQuestion
+func (s QuestionServer) PatchQuestion(c *fiber.Ctx) error {
+	// Get question id
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	// Get authenticated user id - author
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id), questionPreloads...)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+	if !canEditQuestion(question, authorId) {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	}
+	doc, err := json.Marshal(question)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode question")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	// Apply patch
+	var patched []byte
+	switch strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]) {
+	case MIMEMergePatch:
+		patched, err = mergePatchQuestion(doc, c.Body())
+	case MIMEJSONPatch:
+		var operations []jsonpatch.Operation
+		if err := json.Unmarshal(c.Body(), &operations); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JSON Patch document"})
+		}
+		patched, err = jsonpatch.Apply(doc, operations)
+	default:
+		return c.Status(fiber.StatusUnsupportedMediaType).
+			JSON(ErrorResponse{Error: fmt.Sprintf("Content-Type must be %s or %s", MIMEMergePatch, MIMEJSONPatch)})
+	}
+	if err != nil {
+		return c.Status(patchErrorStatus(err)).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	// Validate the patched question
+	var questionUpdate entity.Question
+	if err := json.Unmarshal(patched, &questionUpdate); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	errRes, valid := validateStruct(&questionUpdate)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	errStatus, errRes := s.updateQuestion(c.UserContext(), uint(id), authorId, &questionUpdate)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	return c.JSON(questionUpdate)
+}
+
+// patchErrorStatus follows RFC 5789: malformed patches are bad requests, failed tests
+// are conflicts and patches that cannot be applied to the question are unprocessable
+func patchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return fiber.StatusConflict
+	case errors.Is(err, jsonpatch.ErrPathNotFound):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// mergePatchQuestion applies a merge patch where options may be addressed by id
+func mergePatchQuestion(doc []byte, patch []byte) ([]byte, error) {
+	var patchValue map[string]any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: a question merge patch must be an object", jsonpatch.ErrInvalidPatch)
+	}
+	optionsPatch, byId := patchValue["options"].(map[string]any)
+	if !byId {
+		return jsonpatch.MergePatch(doc, patch)
+	}
+	delete(patchValue, "options")
+
+	var docValue map[string]any
+	if err := json.Unmarshal(doc, &docValue); err != nil {
+		return nil, err
+	}
+	options, _ := docValue["options"].([]any)
+	patchedOptions := []any{}
+	for _, option := range options {
+		optionValue, _ := option.(map[string]any)
+		optionId, _ := optionValue["id"].(float64)
+		key := strconv.FormatUint(uint64(optionId), 10)
+		optionPatch, found := optionsPatch[key]
+		if !found {
+			patchedOptions = append(patchedOptions, option)
+			continue
+		}
+		delete(optionsPatch, key)
+		if optionPatch != nil {
+			patchedOptions = append(patchedOptions, jsonpatch.MergeValue(option, optionPatch))
+		}
+	}
+	for key := range optionsPatch {
+		return nil, fmt.Errorf("%w: option %s", jsonpatch.ErrPathNotFound, key)
+	}
+	docValue["options"] = patchedOptions
+
+	return json.Marshal(jsonpatch.MergeValue(docValue, patchValue))
+}
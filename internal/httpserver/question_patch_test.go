@@ -0,0 +1,166 @@
+package httpserver_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchQuestion(t *testing.T) {
+	var authorId uint = 1
+	var questionId uint = 1
+	correct := true
+	incorrect := false
+	question := entity.Question{
+		Id:   questionId,
+		Body: "question with a tpyo",
+		QuestionOptions: []entity.QuestionOption{
+			{Id: 11, Body: "correct", Correct: &correct},
+			{Id: 12, Body: "incorrect", Correct: &incorrect},
+			{Id: 13, Body: "other incorrect", Correct: &incorrect},
+		},
+		Tags:     []entity.QuestionTag{{Name: "tag"}},
+		AuthorId: authorId,
+		Revision: 1,
+	}
+
+	type Test struct {
+		TestName               string
+		ContentType            string
+		Patch                  string
+		AuthorId               uint
+		ExpectedHttpStatusCode int
+		ExpectedBody           string
+		ExpectedOptions        []string
+	}
+	tests := []Test{
+		{
+			TestName:               "MergePatchBody",
+			ContentType:            httpserver.MIMEMergePatch,
+			Patch:                  `{"body":"question without a typo"}`,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedBody:           "question without a typo",
+			ExpectedOptions:        []string{"correct", "incorrect", "other incorrect"},
+		},
+		{
+			TestName:               "MergePatchOptionsById",
+			ContentType:            httpserver.MIMEMergePatch + "; charset=utf-8",
+			Patch:                  `{"options":{"12":{"body":"fixed incorrect"},"13":null}}`,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedBody:           question.Body,
+			ExpectedOptions:        []string{"correct", "fixed incorrect"},
+		},
+		{
+			TestName:               "MergePatchUnknownOption",
+			ContentType:            httpserver.MIMEMergePatch,
+			Patch:                  `{"options":{"99":{"body":"missing"}}}`,
+			ExpectedHttpStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			TestName:               "MergePatchInvalidQuestion",
+			ContentType:            httpserver.MIMEMergePatch,
+			Patch:                  `{"body":null}`,
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "MergePatchInvalidDocument",
+			ContentType:            httpserver.MIMEMergePatch,
+			Patch:                  `["body"]`,
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "JSONPatch",
+			ContentType:            httpserver.MIMEJSONPatch,
+			Patch:                  `[{"op":"test","path":"/revision","value":1},{"op":"move","from":"/options/2","path":"/options/0"}]`,
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedBody:           question.Body,
+			ExpectedOptions:        []string{"other incorrect", "correct", "incorrect"},
+		},
+		{
+			TestName:               "JSONPatchTestFailed",
+			ContentType:            httpserver.MIMEJSONPatch,
+			Patch:                  `[{"op":"test","path":"/revision","value":2},{"op":"remove","path":"/options/0"}]`,
+			ExpectedHttpStatusCode: http.StatusConflict,
+		},
+		{
+			TestName:               "UnsupportedContentType",
+			ContentType:            "application/json",
+			Patch:                  `{"body":"question without a typo"}`,
+			ExpectedHttpStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			TestName:               "NotAuthor",
+			ContentType:            httpserver.MIMEMergePatch,
+			Patch:                  `{"body":"question without a typo"}`,
+			AuthorId:               2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.
+				On(
+					"GetQuestion",
+					mock.Anything,
+					questionId,
+					gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+					gormprovider.PreloadOption("Tags"),
+				).
+				Return(question, nil)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionRepository.On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).Return(question, nil)
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionRepository.On("UpdateQuestion", mock.Anything, questionId, mock.Anything).
+					Return(func(_ context.Context, _ uint, questionUpdate *entity.Question) error {
+						assert.Equal(t, test.ExpectedBody, questionUpdate.Body)
+						assert.Equal(t, question.Revision+1, questionUpdate.Revision)
+						return nil
+					})
+				questionOptionRepository.On("BulkReplaceQuestionOptions", mock.Anything, questionId, mock.Anything).
+					Return(func(_ context.Context, _ uint, questionOptions []entity.QuestionOption) error {
+						bodies := make([]string, len(questionOptions))
+						for i, questionOption := range questionOptions {
+							bodies[i] = questionOption.Body
+						}
+						assert.Equal(t, test.ExpectedOptions, bodies)
+						return nil
+					})
+				questionTagRepository.On("BulkReplaceQuestionTags", mock.Anything, questionId, question.Tags).Return(nil)
+			}
+
+			authorId := authorId
+			if test.AuthorId != 0 {
+				authorId = test.AuthorId
+			}
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPatch, "/questions/1", strings.NewReader(test.Patch))
+			req.Header.Set("Content-Type", test.ContentType)
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
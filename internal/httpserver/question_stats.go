@@ -0,0 +1,80 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/stats"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type QuestionStatsServer struct {
+	questionRepository            repository.QuestionRepository
+	questionStatsRepository       repository.QuestionStatsRepository
+	questionOptionStatsRepository repository.QuestionOptionStatsRepository
+}
+
+// NewQuestionStatsServer returns the routes to register under /questions/:id/stats
+func NewQuestionStatsServer(
+	questionRepository repository.QuestionRepository,
+	questionStatsRepository repository.QuestionStatsRepository,
+	questionOptionStatsRepository repository.QuestionOptionStatsRepository,
+) func(router fiber.Router) {
+	server := &QuestionStatsServer{questionRepository, questionStatsRepository, questionOptionStatsRepository}
+	return func(router fiber.Router) {
+		router.Use(newJwtAuth())
+		router.Get("/", server.GetQuestionStats)
+	}
+}
+
+func (s QuestionStatsServer) GetQuestionStats(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Stats are kept per revision, defaulting to the current one
+	revision := c.QueryInt("revision", 0)
+	if revision < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "revision is invalid"})
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+
+	if !canReviewQuestion(question, userId, getAuthUserRole(c)) {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	}
+
+	questionRevision := uint(revision)
+	if questionRevision == 0 {
+		questionRevision = question.Revision
+	}
+
+	// Revisions nobody answered yet have no stats
+	questionStats, err := s.questionStatsRepository.GetQuestionStats(c.UserContext(), question.Id, questionRevision)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		questionStats = entity.QuestionStats{QuestionId: question.Id, QuestionRevision: questionRevision}
+	} else if err != nil {
+		log.Error().Err(err).Msg("Failed to get question stats")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	questionOptionStats, err := s.questionOptionStatsRepository.ListQuestionOptionStats(c.UserContext(), question.Id, questionRevision)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list question option stats")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(stats.Analyze(questionStats, questionOptionStats))
+}
@@ -0,0 +1,95 @@
+package httpserver_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/internal/stats"
+	"challenge/mocks"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetQuestionStats(t *testing.T) {
+	var questionId uint = 1
+	var authorId uint = 1
+	question := entity.Question{Id: questionId, Body: "question", AuthorId: authorId, Revision: 2}
+
+	type Test struct {
+		TestName               string
+		Query                  string
+		UserId                 uint
+		Role                   string
+		ExpectedRevision       uint
+		ExpectedResponses      uint
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "CurrentRevision",
+			UserId:                 authorId,
+			ExpectedRevision:       2,
+			ExpectedResponses:      4,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "UnansweredRevision",
+			Query:                  "?revision=1",
+			UserId:                 2,
+			Role:                   httpserver.RoleReviewer,
+			ExpectedRevision:       1,
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "Unauthorized",
+			UserId:                 2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.On("GetQuestion", mock.Anything, questionId).Return(question, nil)
+
+			questionStatsRepository := mocks.NewQuestionStatsRepository(t)
+			questionOptionStatsRepository := mocks.NewQuestionOptionStatsRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				if test.ExpectedResponses == 0 {
+					questionStatsRepository.On("GetQuestionStats", mock.Anything, questionId, test.ExpectedRevision).
+						Return(entity.QuestionStats{}, gorm.ErrRecordNotFound)
+				} else {
+					questionStatsRepository.On("GetQuestionStats", mock.Anything, questionId, test.ExpectedRevision).
+						Return(entity.QuestionStats{QuestionId: questionId, QuestionRevision: test.ExpectedRevision, Responses: test.ExpectedResponses}, nil)
+				}
+				questionOptionStatsRepository.On("ListQuestionOptionStats", mock.Anything, questionId, test.ExpectedRevision).
+					Return([]entity.QuestionOptionStats{}, nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:            questionRepository,
+				QuestionStats:       questionStatsRepository,
+				QuestionOptionStats: questionOptionStatsRepository,
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/questions/%d/stats%s", questionId, test.Query), nil)
+			req.Header.Set("Authorization", newAuthHeader(t, test.UserId, test.Role))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				var report stats.QuestionReport
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+				assert.Equal(t, questionId, report.QuestionId)
+				assert.Equal(t, test.ExpectedRevision, report.QuestionRevision)
+				assert.Equal(t, test.ExpectedResponses, report.Responses)
+			}
+		})
+	}
+}
@@ -81,7 +81,8 @@ func TestListQuestions(t *testing.T) {
 					mock.Anything,
 					test.Req.PageSize,
 					test.Req.LastId,
-					gormprovider.PreloadOption("QuestionOptions"),
+					gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+					gormprovider.PreloadOption("Tags"),
 					test.ExpectedQuestionFilter,
 				).
 				Return([]entity.Question{question}, nil)
@@ -89,7 +90,7 @@ func TestListQuestions(t *testing.T) {
 			reqBodyBytes, err := json.Marshal(test.Req)
 			require.NoError(t, err)
 
-			server := httpserver.NewServer(questionRepository, nil)
+			server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
 			req := httptest.NewRequest(http.MethodGet, "/questions", bytes.NewReader(reqBodyBytes))
 			req.Header.Set("Content-Type", "application/json")
 			res, err := server.Test(req)
@@ -103,6 +104,102 @@ func TestListQuestions(t *testing.T) {
 	}
 }
 
+func TestListQuestionsRenderHTML(t *testing.T) {
+	correct := true
+	question := entity.Question{
+		Id:              1,
+		Body:            "**Which** <script>alert(1)</script>?",
+		BodyFormat:      "markdown",
+		QuestionOptions: []entity.QuestionOption{{Id: 1, Body: "a <b>", Correct: &correct}},
+	}
+
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(0), (*uint)(nil), mock.Anything, mock.Anything, mock.Anything).
+		Return([]entity.Question{question}, nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+	res, err := server.Test(httptest.NewRequest(http.MethodGet, "/questions?render=html", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var questions []entity.Question
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&questions))
+	require.Len(t, questions, 1)
+	assert.Equal(t, "<p><strong>Which</strong> &lt;script&gt;alert(1)&lt;/script&gt;?</p>\n", questions[0].BodyHTML)
+	assert.Equal(t, "<p>a &lt;b&gt;</p>", questions[0].QuestionOptions[0].BodyHTML)
+
+	res, err = server.Test(httptest.NewRequest(http.MethodGet, "/questions?render=pdf", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestListQuestionsHidesExplanations(t *testing.T) {
+	var authorId uint = 1
+	correct := true
+	question := entity.Question{
+		Id:              1,
+		Body:            "question",
+		Explanation:     "because",
+		QuestionOptions: []entity.QuestionOption{{Id: 1, Body: "a", Feedback: "right", Correct: &correct}},
+		AuthorId:        authorId,
+	}
+
+	type Test struct {
+		TestName        string
+		Authorization   string
+		ExpectedVisible bool
+	}
+	tests := []Test{
+		{
+			TestName: "Anonymous",
+		},
+		{
+			TestName:      "OtherUser",
+			Authorization: newAuthHeader(t, 2, ""),
+		},
+		{
+			TestName:        "Author",
+			Authorization:   newAuthHeader(t, authorId, ""),
+			ExpectedVisible: true,
+		},
+		{
+			TestName:        "Reviewer",
+			Authorization:   newAuthHeader(t, 2, httpserver.RoleReviewer),
+			ExpectedVisible: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.On("ListQuestions", mock.Anything, uint(0), (*uint)(nil), mock.Anything, mock.Anything, mock.Anything).
+				Return([]entity.Question{question}, nil)
+
+			server := httpserver.NewServer(httpserver.Repositories{Question: questionRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, "/questions?render=html", nil)
+			if test.Authorization != "" {
+				req.Header.Set("Authorization", test.Authorization)
+			}
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+			var questions []entity.Question
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&questions))
+			require.Len(t, questions, 1)
+			if test.ExpectedVisible {
+				assert.Equal(t, "because", questions[0].Explanation)
+				assert.Equal(t, "<p>because</p>", questions[0].ExplanationHTML)
+				assert.Equal(t, "right", questions[0].QuestionOptions[0].Feedback)
+				return
+			}
+			assert.Empty(t, questions[0].Explanation)
+			assert.Empty(t, questions[0].ExplanationHTML)
+			assert.Empty(t, questions[0].QuestionOptions[0].Feedback)
+			assert.Empty(t, questions[0].QuestionOptions[0].FeedbackHTML)
+		})
+	}
+}
+
 func TestCreateQuestion(t *testing.T) {
 	var questionId uint = 1
 	var authorId uint = 1
@@ -122,7 +219,12 @@ func TestCreateQuestion(t *testing.T) {
 			},
 		},
 	}
-	successExpectedResponseBytes, err := json.Marshal(question)
+	// Options get their ids when they are stored
+	createdQuestion := question
+	createdQuestion.QuestionOptions = []entity.QuestionOption{question.QuestionOptions[0], question.QuestionOptions[1]}
+	createdQuestion.QuestionOptions[0].Id = 1
+	createdQuestion.QuestionOptions[1].Id = 2
+	successExpectedResponseBytes, err := json.Marshal(createdQuestion)
 	require.NoError(t, err)
 
 	validJwt, err := jwt.NewWithClaims(
@@ -160,6 +262,42 @@ func TestCreateQuestion(t *testing.T) {
 			ExpectedHttpStatusCode:    http.StatusOK,
 			ExpectedResponseBodyBytes: successExpectedResponseBytes,
 		},
+		{
+			TestName: "SingleOption",
+			Req: map[string]any{
+				"body":    question.Body,
+				"options": []map[string]any{{"body": "question option", "correct": true}},
+			},
+			ReqAuthHeader:             validAuthHeader,
+			ExpectedHttpStatusCode:    http.StatusBadRequest,
+			ExpectedResponseBodyBytes: []byte(`{"Errors":{"Question.QuestionOptions":["min"]}}`),
+		},
+		{
+			TestName: "NoCorrectOption",
+			Req: map[string]any{
+				"body": question.Body,
+				"options": []map[string]any{
+					{"body": "question option", "correct": false},
+					{"body": "other question option", "correct": false},
+				},
+			},
+			ReqAuthHeader:             validAuthHeader,
+			ExpectedHttpStatusCode:    http.StatusBadRequest,
+			ExpectedResponseBodyBytes: []byte(`{"Errors":{"Question.QuestionOptions":["correct"]}}`),
+		},
+		{
+			TestName: "EmptyOptionBody",
+			Req: map[string]any{
+				"body": question.Body,
+				"options": []map[string]any{
+					{"body": "", "correct": true},
+					{"body": "question option", "correct": false},
+				},
+			},
+			ReqAuthHeader:             validAuthHeader,
+			ExpectedHttpStatusCode:    http.StatusBadRequest,
+			ExpectedResponseBodyBytes: []byte(`{"Errors":{"Question.QuestionOptions[0].Body":["required"]}}`),
+		},
 		{
 			TestName:                  "Unauthorized",
 			ReqAuthHeader:             invalidAuthHeader,
@@ -189,17 +327,28 @@ func TestCreateQuestion(t *testing.T) {
 				questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, questionId, question.QuestionOptions).
 					Return(func(_ context.Context, _ uint, questionOptions []entity.QuestionOption) error {
 						for i := range questionOptions {
-							questionOptions[i].Id = uint(i)
+							questionOptions[i].Id = uint(i) + 1
 							questionOptions[i].QuestionId = questionId
 						}
 						return nil
 					})
 			}
 
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, questionId, []entity.QuestionTag(nil)).Return(nil)
+			}
+
 			reqBodyBytes, err := json.Marshal(test.Req)
 			require.NoError(t, err)
 
-			server := httpserver.NewServer(questionRepository, questionOptionRepository)
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
 			req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(reqBodyBytes))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", test.ReqAuthHeader)
@@ -215,5 +364,122 @@ func TestCreateQuestion(t *testing.T) {
 }
 
 func TestUpdateQuestion(t *testing.T) {
-	// TODO
+	var questionId uint = 1
+	var authorId uint = 1
+	correct := true
+	incorrect := false
+	question := entity.Question{
+		Id:   questionId,
+		Body: "question",
+		QuestionOptions: []entity.QuestionOption{
+			{Id: 11, Body: "question option correct", Correct: &correct},
+			{Id: 12, Body: "question option incorrect", Correct: &incorrect},
+		},
+		AuthorId: authorId,
+		Revision: 1,
+	}
+
+	type Test struct {
+		TestName               string
+		Req                    map[string]any
+		AuthorId               uint
+		ExpectedHttpStatusCode int
+		ExpectedOptionIds      []uint
+	}
+	tests := []Test{
+		{
+			TestName: "KeepsOptionIds",
+			Req: map[string]any{
+				"body": "updated question",
+				"options": []map[string]any{
+					{"id": 12, "body": "question option incorrect", "correct": false},
+					{"body": "new question option", "correct": true},
+				},
+			},
+			ExpectedHttpStatusCode: http.StatusOK,
+			ExpectedOptionIds:      []uint{12, 0},
+		},
+		{
+			TestName: "OptionOfOtherQuestion",
+			Req: map[string]any{
+				"body": "updated question",
+				"options": []map[string]any{
+					{"id": 21, "body": "question option", "correct": true},
+					{"body": "other question option", "correct": false},
+				},
+			},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName: "RepeatedOption",
+			Req: map[string]any{
+				"body": "updated question",
+				"options": []map[string]any{
+					{"id": 11, "body": "question option", "correct": true},
+					{"id": 11, "body": "question option", "correct": true},
+				},
+			},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName: "NotAuthor",
+			Req: map[string]any{
+				"body": "updated question",
+				"options": []map[string]any{
+					{"body": "question option", "correct": true},
+					{"body": "other question option", "correct": false},
+				},
+			},
+			AuthorId:               2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.
+				On("GetQuestion", mock.Anything, questionId, gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+				Return(question, nil)
+			questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+			questionTagRepository := mocks.NewQuestionTagRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionRepository.On("UpdateQuestion", mock.Anything, questionId, mock.Anything).Return(nil)
+				questionOptionRepository.On("BulkReplaceQuestionOptions", mock.Anything, questionId, mock.Anything).
+					Return(func(_ context.Context, _ uint, questionOptions []entity.QuestionOption) error {
+						ids := make([]uint, len(questionOptions))
+						for i, questionOption := range questionOptions {
+							ids[i] = questionOption.Id
+						}
+						assert.Equal(t, test.ExpectedOptionIds, ids)
+						return nil
+					})
+				questionTagRepository.On("BulkReplaceQuestionTags", mock.Anything, questionId, []entity.QuestionTag(nil)).Return(nil)
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+
+			authorId := authorId
+			if test.AuthorId != 0 {
+				authorId = test.AuthorId
+			}
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:       questionRepository,
+				QuestionOption: questionOptionRepository,
+				QuestionTag:    questionTagRepository,
+				Outbox:         newOutboxRepository(t),
+				AuditEntry:     newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/questions/%d", questionId), bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
 }
@@ -0,0 +1,278 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/richtext"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+type QuestionTranslationServer struct {
+	questionRepository            repository.QuestionRepository
+	questionTranslationRepository repository.QuestionTranslationRepository
+	auditEntryRepository          repository.AuditEntryRepository
+}
+
+// NewQuestionTranslationServer returns the routes to register under /questions/:id/translations.
+// Fiber does not resolve params in mount prefixes, so these routes are not a mounted app.
+func NewQuestionTranslationServer(
+	questionRepository repository.QuestionRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+) func(router fiber.Router) {
+	server := &QuestionTranslationServer{questionRepository, questionTranslationRepository, auditEntryRepository}
+	return func(router fiber.Router) {
+		jwtAuth := newJwtAuth()
+		router.Get("/", server.ListQuestionTranslations)
+		router.Put("/:lang", jwtAuth, server.SaveQuestionTranslation)
+		router.Delete("/:lang", jwtAuth, server.DeleteQuestionTranslation)
+	}
+}
+
+// MissingTranslation reports what is left to translate of a question into a language
+type MissingTranslation struct {
+	QuestionId uint   `json:"questionId"`
+	Language   string `json:"language"`
+	Missing    bool   `json:"missing"`   // The question has no translation into the language
+	Outdated   bool   `json:"outdated"`  // The question changed after it was translated
+	OptionIds  []uint `json:"optionIds"` // Options without translation
+}
+
+func (s QuestionTranslationServer) ListQuestionTranslations(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+
+	questionTranslations, err := s.questionTranslationRepository.ListQuestionTranslations(c.UserContext(), []uint{question.Id})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list question translations")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(questionTranslations)
+}
+
+// SaveQuestionTranslation creates or replaces the translation of the question into the language in the url
+func (s QuestionTranslationServer) SaveQuestionTranslation(c *fiber.Ctx) error {
+	question, lang, errStatus, errRes := s.getOwnQuestionLanguage(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+	if lang == normalizeLanguage(question.GetLanguage()) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "lang is the canonical language of the question"})
+	}
+
+	// Validate and parse request
+	var questionTranslation entity.QuestionTranslation
+	errRes, valid := validateRequest(c, &questionTranslation)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Option translations reference options of the question, once each
+	optionIds := map[uint]bool{}
+	for _, questionOption := range question.QuestionOptions {
+		optionIds[questionOption.Id] = true
+	}
+	for i := range questionTranslation.Options {
+		optionTranslation := &questionTranslation.Options[i]
+		if !optionIds[optionTranslation.QuestionOptionId] {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: fmt.Sprintf("Option %d is not an option of the question or is repeated", optionTranslation.QuestionOptionId),
+			})
+		}
+		delete(optionIds, optionTranslation.QuestionOptionId)
+		optionTranslation.Body = richtext.Sanitize(optionTranslation.GetBodyFormat(), optionTranslation.Body)
+		optionTranslation.Feedback = richtext.Sanitize(optionTranslation.GetFeedbackFormat(), optionTranslation.Feedback)
+	}
+	questionTranslation.Body = richtext.Sanitize(questionTranslation.GetBodyFormat(), questionTranslation.Body)
+	questionTranslation.Explanation = richtext.Sanitize(questionTranslation.GetExplanationFormat(), questionTranslation.Explanation)
+	questionTranslation.QuestionId = question.Id
+	questionTranslation.QuestionRevision = question.Revision
+	questionTranslation.Language = lang
+
+	err := s.questionTranslationRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		before, err := getQuestionTranslation(txCtx, s.questionTranslationRepository, question.Id, lang)
+		if err != nil {
+			return err
+		}
+
+		err = s.questionTranslationRepository.SaveQuestionTranslation(txCtx, &questionTranslation)
+		if err != nil {
+			return err
+		}
+
+		action := entity.AuditActionCreate
+		if before != nil {
+			action = entity.AuditActionUpdate
+		}
+		return recordAudit(txCtx, s.auditEntryRepository, action, entity.AuditEntityQuestionTranslation, question.Id, before, questionTranslation)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save question translation")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(questionTranslation)
+}
+
+func (s QuestionTranslationServer) DeleteQuestionTranslation(c *fiber.Ctx) error {
+	question, lang, errStatus, errRes := s.getOwnQuestionLanguage(c)
+	if errRes != nil {
+		return c.Status(errStatus).JSON(errRes)
+	}
+
+	err := s.questionTranslationRepository.RunInTransaction(c.UserContext(), func(txCtx context.Context) error {
+		before, err := getQuestionTranslation(txCtx, s.questionTranslationRepository, question.Id, lang)
+		if err != nil {
+			return err
+		}
+
+		err = s.questionTranslationRepository.DeleteQuestionTranslation(txCtx, question.Id, lang)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, s.auditEntryRepository, entity.AuditActionDelete, entity.AuditEntityQuestionTranslation, question.Id, before, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete question translation")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return nil
+}
+
+// getQuestionTranslation returns the translation of the question into the language, nil if there is none
+func getQuestionTranslation(ctx context.Context, questionTranslationRepository repository.QuestionTranslationRepository, questionId uint, lang string) (*entity.QuestionTranslation, error) {
+	questionTranslations, err := questionTranslationRepository.ListQuestionTranslations(ctx, []uint{questionId})
+	if err != nil {
+		return nil, err
+	}
+	for _, questionTranslation := range questionTranslations {
+		if questionTranslation.Language == lang {
+			return &questionTranslation, nil
+		}
+	}
+	return nil, nil
+}
+
+// getOwnQuestionLanguage loads the question in the url, checks that the auth user can edit it
+// and normalizes the language in the url
+func (s QuestionTranslationServer) getOwnQuestionLanguage(c *fiber.Ctx) (entity.Question, string, int, any) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return entity.Question{}, "", fiber.StatusBadRequest, ErrorResponse{Error: "id is invalid"}
+	}
+	tag, err := language.Parse(c.Params("lang"))
+	if err != nil {
+		return entity.Question{}, "", fiber.StatusBadRequest, ErrorResponse{Error: "lang is invalid"}
+	}
+
+	userId, err := getAuthUserId(c)
+	if err != nil {
+		return entity.Question{}, "", fiber.StatusBadRequest, ErrorResponse{Error: "Invalid JWT claims"}
+	}
+
+	question, err := s.questionRepository.GetQuestion(c.UserContext(), uint(id), questionOptionsPreload)
+	if err != nil {
+		return entity.Question{}, "", fiber.StatusNotFound, ErrorResponse{Error: "Not found"}
+	}
+	if !canEditQuestion(question, userId) {
+		return entity.Question{}, "", fiber.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"}
+	}
+
+	return question, tag.String(), 0, nil
+}
+
+// ListMissingTranslations reports, for every question and asked language, the translations that are
+// missing, outdated or without some options. Questions already in one of the languages are skipped for it
+func (s QuestionServer) ListMissingTranslations(c *fiber.Ctx) error {
+	if c.Query("languages") == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "languages is required"})
+	}
+	langs := []string{}
+	for _, lang := range strings.Split(c.Query("languages"), ",") {
+		tag, err := language.Parse(strings.TrimSpace(lang))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "languages is invalid"})
+		}
+		langs = append(langs, tag.String())
+	}
+
+	questionFilter := repository.QuestionFilter{}
+	if authorId := c.QueryInt("authorId", 0); authorId > 0 {
+		questionFilter.AuthorId = uint(authorId)
+	}
+	questions, err := listAllQuestions(c.UserContext(), s.questionRepository, questionOptionsPreload, questionFilter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list questions")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	questionIds := make([]uint, len(questions))
+	for i, question := range questions {
+		questionIds[i] = question.Id
+	}
+	questionTranslations, err := listQuestionTranslations(c.UserContext(), s.questionTranslationRepository, questionIds)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list question translations")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	translations := map[uint]map[string]entity.QuestionTranslation{}
+	for _, questionTranslation := range questionTranslations {
+		if translations[questionTranslation.QuestionId] == nil {
+			translations[questionTranslation.QuestionId] = map[string]entity.QuestionTranslation{}
+		}
+		translations[questionTranslation.QuestionId][questionTranslation.Language] = questionTranslation
+	}
+
+	missingTranslations := []MissingTranslation{}
+	for _, question := range questions {
+		for _, lang := range langs {
+			if lang == normalizeLanguage(question.GetLanguage()) {
+				continue
+			}
+			missingTranslation := findMissingTranslation(question, lang, translations[question.Id])
+			if missingTranslation.Missing || missingTranslation.Outdated || len(missingTranslation.OptionIds) > 0 {
+				missingTranslations = append(missingTranslations, missingTranslation)
+			}
+		}
+	}
+
+	return c.JSON(missingTranslations)
+}
+
+func findMissingTranslation(question entity.Question, lang string, translations map[string]entity.QuestionTranslation) MissingTranslation {
+	missingTranslation := MissingTranslation{QuestionId: question.Id, Language: lang, OptionIds: []uint{}}
+	questionTranslation, found := translations[lang]
+	missingTranslation.Missing = !found
+	missingTranslation.Outdated = found && questionTranslation.QuestionRevision < question.Revision
+
+	translatedOptionIds := map[uint]bool{}
+	for _, optionTranslation := range questionTranslation.Options {
+		translatedOptionIds[optionTranslation.QuestionOptionId] = true
+	}
+	for _, questionOption := range question.QuestionOptions {
+		if !translatedOptionIds[questionOption.Id] {
+			missingTranslation.OptionIds = append(missingTranslation.OptionIds, questionOption.Id)
+		}
+	}
+	return missingTranslation
+}
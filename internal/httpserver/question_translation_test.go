@@ -0,0 +1,269 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTranslatedQuestion() entity.Question {
+	correct := true
+	incorrect := false
+	return entity.Question{
+		Id:   1,
+		Body: "Which one?",
+		QuestionOptions: []entity.QuestionOption{
+			{Id: 11, Body: "this", Correct: &correct},
+			{Id: 12, Body: "that", Correct: &incorrect},
+		},
+		AuthorId: 1,
+		Revision: 2,
+	}
+}
+
+func newQuestionTranslations() []entity.QuestionTranslation {
+	return []entity.QuestionTranslation{
+		{
+			QuestionId:       1,
+			QuestionRevision: 2,
+			Language:         "de",
+			Body:             "Welche?",
+			Options:          []entity.QuestionOptionTranslation{{QuestionOptionId: 11, Body: "diese"}},
+		},
+		{
+			QuestionId:       1,
+			QuestionRevision: 1,
+			Language:         "pt",
+			Body:             "Qual?",
+			Options: []entity.QuestionOptionTranslation{
+				{QuestionOptionId: 11, Body: "esta"},
+				{QuestionOptionId: 12, Body: "aquela"},
+			},
+		},
+	}
+}
+
+func TestListQuestionsTranslated(t *testing.T) {
+	type Test struct {
+		TestName            string
+		Url                 string
+		AcceptLanguage      string
+		ExpectedHttpStatus  int
+		ExpectedTranslation string
+		ExpectedBodies      []string
+	}
+	tests := []Test{
+		{
+			TestName:            "AcceptLanguage",
+			Url:                 "/questions",
+			AcceptLanguage:      "fr;q=1, de-AT;q=0.8, en;q=0.5",
+			ExpectedHttpStatus:  http.StatusOK,
+			ExpectedTranslation: "de",
+			ExpectedBodies:      []string{"Welche?", "diese", "that"},
+		},
+		{
+			TestName:            "LangOverridesAcceptLanguage",
+			Url:                 "/questions?lang=pt-BR",
+			AcceptLanguage:      "de",
+			ExpectedHttpStatus:  http.StatusOK,
+			ExpectedTranslation: "pt",
+			ExpectedBodies:      []string{"Qual?", "esta", "aquela"},
+		},
+		{
+			TestName:           "Canonical",
+			Url:                "/questions?lang=en-GB",
+			ExpectedHttpStatus: http.StatusOK,
+			ExpectedBodies:     []string{"Which one?", "this", "that"},
+		},
+		{
+			TestName:           "NoMatch",
+			Url:                "/questions?lang=ja",
+			ExpectedHttpStatus: http.StatusOK,
+			ExpectedBodies:     []string{"Which one?", "this", "that"},
+		},
+		{
+			TestName:           "InvalidLang",
+			Url:                "/questions?lang=toolonglanguage",
+			ExpectedHttpStatus: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionTranslationRepository := mocks.NewQuestionTranslationRepository(t)
+			if test.ExpectedHttpStatus == http.StatusOK {
+				questionRepository.On("ListQuestions", mock.Anything, uint(0), (*uint)(nil), mock.Anything, mock.Anything, mock.Anything).
+					Return([]entity.Question{newTranslatedQuestion()}, nil)
+				questionTranslationRepository.On("ListQuestionTranslations", mock.Anything, []uint{1}).
+					Return(newQuestionTranslations(), nil)
+			}
+
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:            questionRepository,
+				QuestionTranslation: questionTranslationRepository,
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, test.Url, nil)
+			if test.AcceptLanguage != "" {
+				req.Header.Set("Accept-Language", test.AcceptLanguage)
+			}
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatus, res.StatusCode)
+			if test.ExpectedHttpStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "Accept-Language", res.Header.Get("Vary"))
+
+			var questions []entity.Question
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&questions))
+			require.Len(t, questions, 1)
+			assert.Equal(t, test.ExpectedTranslation, questions[0].Translation)
+			bodies := []string{questions[0].Body}
+			for _, questionOption := range questions[0].QuestionOptions {
+				bodies = append(bodies, questionOption.Body)
+			}
+			assert.Equal(t, test.ExpectedBodies, bodies)
+		})
+	}
+}
+
+func TestSaveQuestionTranslation(t *testing.T) {
+	type Test struct {
+		TestName               string
+		Url                    string
+		Req                    any
+		AuthorId               uint
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Save",
+			Url:                    "/questions/1/translations/pt-br",
+			Req:                    map[string]any{"body": "Qual?", "options": []any{map[string]any{"optionId": 12, "body": "aquela"}}},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "CanonicalLanguage",
+			Url:                    "/questions/1/translations/en",
+			Req:                    map[string]any{"body": "Which?"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "InvalidLanguage",
+			Url:                    "/questions/1/translations/toolonglanguage",
+			Req:                    map[string]any{"body": "Qual?"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "UnknownOption",
+			Url:                    "/questions/1/translations/pt",
+			Req:                    map[string]any{"body": "Qual?", "options": []any{map[string]any{"optionId": 21, "body": "outra"}}},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName: "RepeatedOption",
+			Url:      "/questions/1/translations/pt",
+			Req: map[string]any{"body": "Qual?", "options": []any{
+				map[string]any{"optionId": 11, "body": "esta"},
+				map[string]any{"optionId": 11, "body": "essa"},
+			}},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "NotAuthor",
+			Url:                    "/questions/1/translations/pt",
+			Req:                    map[string]any{"body": "Qual?"},
+			AuthorId:               2,
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			questionRepository := mocks.NewQuestionRepository(t)
+			questionRepository.
+				On("GetQuestion", mock.Anything, uint(1), gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}).
+				Return(newTranslatedQuestion(), nil).
+				Maybe()
+			questionTranslationRepository := mocks.NewQuestionTranslationRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				questionTranslationRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					})
+				questionTranslationRepository.On("ListQuestionTranslations", mock.Anything, []uint{1}).
+					Return([]entity.QuestionTranslation{}, nil)
+				questionTranslationRepository.On("SaveQuestionTranslation", mock.Anything, &entity.QuestionTranslation{
+					QuestionId:       1,
+					QuestionRevision: 2,
+					Language:         "pt-BR",
+					Body:             "Qual?",
+					Options:          []entity.QuestionOptionTranslation{{QuestionOptionId: 12, Body: "aquela"}},
+				}).Return(nil)
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+			authorId := uint(1)
+			if test.AuthorId != 0 {
+				authorId = test.AuthorId
+			}
+			server := httpserver.NewServer(httpserver.Repositories{
+				Question:            questionRepository,
+				QuestionTranslation: questionTranslationRepository,
+				AuditEntry:          newAuditEntryRepository(t),
+			}, nil, nil)
+			req := httptest.NewRequest(http.MethodPut, test.Url, bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, authorId, ""))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestListMissingTranslations(t *testing.T) {
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(1000), (*uint)(nil), mock.Anything, mock.Anything).
+		Return([]entity.Question{newTranslatedQuestion(), {Id: 2, Language: "de", Revision: 1}}, nil)
+	questionTranslationRepository := mocks.NewQuestionTranslationRepository(t)
+	questionTranslationRepository.On("ListQuestionTranslations", mock.Anything, []uint{1, 2}).
+		Return(newQuestionTranslations(), nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:            questionRepository,
+		QuestionTranslation: questionTranslationRepository,
+	}, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/questions/translations/missing?languages=de,pt,es", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var missingTranslations []httpserver.MissingTranslation
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&missingTranslations))
+	assert.Equal(t, []httpserver.MissingTranslation{
+		{QuestionId: 1, Language: "de", OptionIds: []uint{12}},
+		{QuestionId: 1, Language: "pt", Outdated: true, OptionIds: []uint{}},
+		{QuestionId: 1, Language: "es", Missing: true, OptionIds: []uint{11, 12}},
+		{QuestionId: 2, Language: "pt", Missing: true, OptionIds: []uint{}},
+		{QuestionId: 2, Language: "es", Missing: true, OptionIds: []uint{}},
+	}, missingTranslations)
+
+	req = httptest.NewRequest(http.MethodGet, "/questions/translations/missing", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/eventbus"
+	"challenge/internal/repository"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// QuestionWriter changes questions with the checks and side effects of the question routes, so servers of
+// other protocols validate, audit and publish their changes exactly like the REST handlers
+type QuestionWriter struct {
+	questions QuestionServer
+}
+
+func NewQuestionWriter(
+	questionRepository repository.QuestionRepository,
+	questionOptionRepository repository.QuestionOptionRepository,
+	questionTagRepository repository.QuestionTagRepository,
+	attachmentRepository repository.AttachmentRepository,
+	questionTranslationRepository repository.QuestionTranslationRepository,
+	outboxRepository repository.OutboxRepository,
+	auditEntryRepository repository.AuditEntryRepository,
+	questionEvents *eventbus.Bus,
+) *QuestionWriter {
+	return &QuestionWriter{QuestionServer{
+		questionRepository:            questionRepository,
+		questionOptionRepository:      questionOptionRepository,
+		questionTagRepository:         questionTagRepository,
+		attachmentRepository:          attachmentRepository,
+		questionTranslationRepository: questionTranslationRepository,
+		outboxRepository:              outboxRepository,
+		auditEntryRepository:          auditEntryRepository,
+		questionEvents:                questionEvents,
+	}}
+}
+
+// QuestionWriteError is the status and error response the question routes answer a failed change with
+type QuestionWriteError struct {
+	Status   int
+	Response any
+}
+
+func (e QuestionWriteError) Error() string {
+	switch errRes := e.Response.(type) {
+	case ErrorResponse:
+		return errRes.Error
+	case ValidationErrorResponse:
+		return "Validation failed"
+	}
+	return "Internal error"
+}
+
+// CreateQuestion checks and creates the question of the author like POST /questions does
+func (w *QuestionWriter) CreateQuestion(ctx context.Context, authorId uint, question *entity.Question) error {
+	if errRes, valid := validateStruct(*question); !valid {
+		return QuestionWriteError{fiber.StatusBadRequest, errRes}
+	}
+	question.AuthorId = authorId
+	setOrderingOptionsCorrect(question)
+	errStatus, errRes := checkQuestionAttachments(ctx, w.questions.attachmentRepository, *question, authorId)
+	if errRes != nil {
+		return QuestionWriteError{errStatus, errRes}
+	}
+
+	err := w.questions.createQuestion(ctx, question)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create questions")
+		return QuestionWriteError{fiber.StatusInternalServerError, ErrorResponse{Error: "Internal error"}}
+	}
+	return nil
+}
+
+// UpdateQuestion checks the question and replaces it like PUT /questions/:id does, only its author can update it
+func (w *QuestionWriter) UpdateQuestion(ctx context.Context, id uint, authorId uint, questionUpdate *entity.Question) error {
+	if errRes, valid := validateStruct(*questionUpdate); !valid {
+		return QuestionWriteError{fiber.StatusBadRequest, errRes}
+	}
+
+	errStatus, errRes := w.questions.updateQuestion(ctx, id, authorId, questionUpdate)
+	if errRes != nil {
+		return QuestionWriteError{errStatus, errRes}
+	}
+	questionUpdate.AuthorId = authorId
+	return nil
+}
+
+// DeleteQuestion deletes the question like DELETE /questions/:id does, only its author can delete it and deleting
+// a missing question succeeds
+func (w *QuestionWriter) DeleteQuestion(ctx context.Context, id uint, authorId uint) error {
+	errStatus, errRes := w.questions.deleteQuestion(ctx, id, authorId)
+	if errRes != nil {
+		return QuestionWriteError{errStatus, errRes}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/richtext"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const RenderHTML = "html"
+
+// parseRender reads the render query param of read endpoints, which only accepts html
+func parseRender(c *fiber.Ctx) (bool, any) {
+	switch c.Query("render") {
+	case "":
+		return false, nil
+	case RenderHTML:
+		return true, nil
+	default:
+		return false, ErrorResponse{Error: "render is invalid"}
+	}
+}
+
+// sanitizeQuestion escapes the HTML embedded in the question and option bodies, explanation and feedbacks
+func sanitizeQuestion(question *entity.Question) {
+	question.Body = richtext.Sanitize(question.GetBodyFormat(), question.Body)
+	question.Explanation = richtext.Sanitize(question.GetExplanationFormat(), question.Explanation)
+	for i := range question.QuestionOptions {
+		questionOption := &question.QuestionOptions[i]
+		questionOption.Body = richtext.Sanitize(questionOption.GetBodyFormat(), questionOption.Body)
+		questionOption.Feedback = richtext.Sanitize(questionOption.GetFeedbackFormat(), questionOption.Feedback)
+	}
+}
+
+// renderQuestionHTML fills the safe HTML of the question and option bodies, explanation and feedbacks
+func renderQuestionHTML(question *entity.Question) error {
+	var err error
+	question.BodyHTML, err = richtext.RenderHTML(question.GetBodyFormat(), question.Body)
+	if err != nil {
+		return err
+	}
+	question.ExplanationHTML, err = renderOptionalHTML(question.GetExplanationFormat(), question.Explanation)
+	if err != nil {
+		return err
+	}
+	return renderQuestionOptionsHTML(question.QuestionOptions)
+}
+
+func renderQuestionOptionsHTML(questionOptions []entity.QuestionOption) error {
+	for i := range questionOptions {
+		var err error
+		questionOptions[i].BodyHTML, err = richtext.RenderHTML(questionOptions[i].GetBodyFormat(), questionOptions[i].Body)
+		if err != nil {
+			return err
+		}
+		questionOptions[i].FeedbackHTML, err = renderOptionalHTML(questionOptions[i].GetFeedbackFormat(), questionOptions[i].Feedback)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderOptionalHTML renders nothing for empty texts, so their HTML is omitted
+func renderOptionalHTML(format string, text string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	return richtext.RenderHTML(format, text)
+}
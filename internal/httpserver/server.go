@@ -1,17 +1,30 @@
 package httpserver
 
 import (
+	"challenge/internal/entity"
+	"challenge/internal/eventbus"
 	"challenge/internal/repository"
+	"challenge/pkg/blobstore"
+	"challenge/pkg/env"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	jwtware "github.com/gofiber/jwt/v3"
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+const (
+	RoleAdmin    = "admin"
+	RoleReviewer = "reviewer"
+)
 
 type ErrorResponse struct {
 	Error string
@@ -21,15 +34,144 @@ type ValidationErrorResponse struct {
 	Errors map[string][]string
 }
 
-func NewServer(questionRepository repository.QuestionRepository, questionOptionRepository repository.QuestionOptionRepository) *fiber.App {
-	app := fiber.New()
+// Repositories groups the repositories used by the http servers
+type Repositories struct {
+	Question            repository.QuestionRepository
+	QuestionOption      repository.QuestionOptionRepository
+	QuestionTag         repository.QuestionTagRepository
+	QuestionComment     repository.QuestionCommentRepository
+	Assessment          repository.AssessmentRepository
+	AssessmentQuestion  repository.AssessmentQuestionRepository
+	Attempt             repository.AttemptRepository
+	AttemptAnswer       repository.AttemptAnswerRepository
+	QuestionStats       repository.QuestionStatsRepository
+	QuestionOptionStats repository.QuestionOptionStatsRepository
+	IdempotencyKey      repository.IdempotencyKeyRepository
+	Attachment          repository.AttachmentRepository
+	QuestionTranslation repository.QuestionTranslationRepository
+	WebhookSubscription repository.WebhookSubscriptionRepository
+	WebhookDelivery     repository.WebhookDeliveryRepository
+	Outbox              repository.OutboxRepository
+	AuditEntry          repository.AuditEntryRepository
+}
+
+// NewServer serves the REST API, questionEvents streams its question changes and is created when nil
+func NewServer(repositories Repositories, blobStore blobstore.Store, questionEvents *eventbus.Bus) *fiber.App {
+	attachmentMaxSize := newAttachmentMaxSize()
+	app := fiber.New(fiber.Config{BodyLimit: newBodyLimit(attachmentMaxSize)})
 	app.Use(recover.New())
+	app.Use(requestid.New())
 	app.Use(logger.New())
-	app.Mount("/questions", NewQuestionServer(questionRepository, questionOptionRepository))
+	app.Use(withAuditContext)
+	if questionEvents == nil {
+		questionEvents = eventbus.NewBus(eventbus.HistorySize())
+	}
+	app.Mount("/questions", NewQuestionServer(
+		repositories.Question,
+		repositories.QuestionOption,
+		repositories.QuestionTag,
+		repositories.IdempotencyKey,
+		repositories.Attachment,
+		repositories.QuestionTranslation,
+		repositories.Outbox,
+		repositories.AuditEntry,
+		questionEvents,
+	))
+	app.Route("/questions/:id/options", NewQuestionOptionServer(
+		repositories.Question,
+		repositories.QuestionOption,
+		repositories.Attachment,
+		repositories.QuestionTranslation,
+		repositories.Outbox,
+		repositories.AuditEntry,
+		questionEvents,
+	))
+	app.Route("/questions/:id/translations", NewQuestionTranslationServer(repositories.Question, repositories.QuestionTranslation, repositories.AuditEntry))
+	app.Route("/questions/:id/comments", NewQuestionCommentServer(repositories.Question, repositories.QuestionComment, repositories.AuditEntry))
+	app.Route("/questions/:id/stats", NewQuestionStatsServer(repositories.Question, repositories.QuestionStats, repositories.QuestionOptionStats))
+	app.Mount("/assessments", NewAssessmentServer(
+		repositories.Question,
+		repositories.Assessment,
+		repositories.AssessmentQuestion,
+		repositories.Attempt,
+		repositories.Outbox,
+	))
+	app.Mount("/attempts", NewAttemptServer(
+		repositories.Assessment,
+		repositories.Attempt,
+		repositories.AttemptAnswer,
+		repositories.QuestionStats,
+		repositories.QuestionOptionStats,
+		repositories.QuestionTranslation,
+	))
+	app.Mount("/attachments", NewAttachmentServer(repositories.Attachment, blobStore, attachmentMaxSize))
+	app.Mount("/webhooks", NewWebhookServer(repositories.WebhookSubscription, repositories.WebhookDelivery))
+	app.Mount("/audit", NewAuditServer(repositories.AuditEntry))
+	app.Mount("/graphql", NewGraphQLServer(
+		repositories.Question,
+		repositories.QuestionOption,
+		repositories.QuestionTag,
+		repositories.Attachment,
+		repositories.QuestionTranslation,
+		repositories.Outbox,
+		repositories.AuditEntry,
+		repositories.QuestionStats,
+		questionEvents,
+	))
+	openAPIServer := NewOpenAPIServer()
+	app.Get("/openapi.json", openAPIServer.GetDocument)
+	app.Get("/docs", openAPIServer.GetDocs)
 
 	return app
 }
 
+func newJwtAuth() fiber.Handler {
+	return jwtware.New(jwtware.Config{
+		SigningKey:     []byte(env.GetOrDefault("JWT_SIGNING_KEY", "secret")),
+		SuccessHandler: withAuditActor,
+	})
+}
+
+// newOptionalJwtAuth verifies the JWT of the requests that have one and lets anonymous requests through
+func newOptionalJwtAuth() fiber.Handler {
+	return jwtware.New(jwtware.Config{
+		SigningKey:     []byte(env.GetOrDefault("JWT_SIGNING_KEY", "secret")),
+		SuccessHandler: withAuditActor,
+		Filter: func(c *fiber.Ctx) bool {
+			return c.Get(fiber.HeaderAuthorization) == ""
+		},
+	})
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterStructValidation(validateQuestion, entity.Question{})
+	return v
+}
+
+// validateQuestion requires the correct flag on options, except for ordering questions
+// where the option order is the answer, and enforces the option invariants
+func validateQuestion(sl validator.StructLevel) {
+	question := sl.Current().Interface().(entity.Question)
+
+	// Missing options are reported by the tag of the field
+	if len(question.QuestionOptions) > 0 {
+		if tag := questionOptionsViolation(question); tag != "" {
+			sl.ReportError(question.QuestionOptions, "QuestionOptions", "QuestionOptions", tag, "")
+		}
+	}
+
+	if question.GetType() == entity.QuestionTypeOrdering {
+		return
+	}
+	for i, questionOption := range question.QuestionOptions {
+		if questionOption.Correct == nil {
+			fieldName := fmt.Sprintf("QuestionOptions[%d].Correct", i)
+			sl.ReportError(questionOption.Correct, fieldName, fieldName, "required", "")
+		}
+	}
+}
+
 func validateRequest(c *fiber.Ctx, req any) (res any, valid bool) {
 	// Parse body
 	if err := c.BodyParser(&req); err != nil {
@@ -37,7 +179,11 @@ func validateRequest(c *fiber.Ctx, req any) (res any, valid bool) {
 	}
 
 	// Validare
-	err := validate.Struct(req)
+	return validateStruct(req)
+}
+
+func validateStruct(v any) (res any, valid bool) {
+	err := validate.Struct(v)
 	if err != nil {
 		errs := map[string][]string{}
 		for _, err := range err.(validator.ValidationErrors) {
@@ -62,3 +208,17 @@ func getAuthUserId(c *fiber.Ctx) (uint, error) {
 	}
 	return uint(userIdU64), nil
 }
+
+func getAuthUserRole(c *fiber.Ctx) string {
+	user, exists := c.Locals("user").(*jwt.Token)
+	if !exists {
+		return ""
+	}
+	claims := user.Claims.(jwt.MapClaims)
+	role, _ := claims["role"].(string)
+	return role
+}
+
+func isUniqueConstraintError(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,20 @@
+package httpserver_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthHeader(t *testing.T, userId uint, role string) string {
+	claims := jwt.MapClaims{"user_id": strconv.FormatUint(uint64(userId), 10)}
+	if role != "" {
+		claims["role"] = role
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return fmt.Sprintf("Bearer %s", token)
+}
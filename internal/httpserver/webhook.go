@@ -0,0 +1,182 @@
+package httpserver
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type WebhookServer struct {
+	webhookSubscriptionRepository repository.WebhookSubscriptionRepository
+	webhookDeliveryRepository     repository.WebhookDeliveryRepository
+}
+
+// NewWebhookServer manages the webhook subscriptions and their delivery log, only admins can use it
+// since subscriptions receive the questions of every author
+func NewWebhookServer(
+	webhookSubscriptionRepository repository.WebhookSubscriptionRepository,
+	webhookDeliveryRepository repository.WebhookDeliveryRepository,
+) *fiber.App {
+	server := &WebhookServer{webhookSubscriptionRepository, webhookDeliveryRepository}
+	app := fiber.New()
+	app.Use(newJwtAuth(), requireAdmin)
+	app.Get("/", server.ListWebhookSubscriptions)
+	app.Post("/", server.CreateWebhookSubscription)
+	app.Get("/deliveries", server.ListWebhookDeliveries)
+	app.Post("/deliveries/:id/retry", server.RetryWebhookDelivery)
+	app.Get("/:id", server.GetWebhookSubscription)
+	app.Delete("/:id", server.DeleteWebhookSubscription)
+
+	return app
+}
+
+type CreateWebhookSubscriptionRequest struct {
+	Url    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=question.created question.updated question.deleted question.published"`
+	Secret string   `json:"secret" validate:"required,min=16"` // Key of the delivery signatures, never returned
+}
+
+type ListWebhookDeliveriesRequest struct {
+	LastId    *uint  `json:"lastId"`
+	PageSize  uint   `json:"pageSize" validate:"max=1000"`
+	WebhookId uint   `json:"webhookId"`
+	Status    string `json:"status" validate:"omitempty,oneof=pending delivered dead"` // dead lists the dead letters
+	EventId   string `json:"eventId"`
+}
+
+func (s WebhookServer) ListWebhookSubscriptions(c *fiber.Ctx) error {
+	webhookSubscriptions, err := s.webhookSubscriptionRepository.ListWebhookSubscriptions(c.UserContext())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhooks")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(webhookSubscriptions)
+}
+
+func (s WebhookServer) CreateWebhookSubscription(c *fiber.Ctx) error {
+	authorId, err := getAuthUserId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid JWT claims"})
+	}
+
+	// Validate and parse request
+	var req CreateWebhookSubscriptionRequest
+	errRes, valid := validateRequest(c, &req)
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+	webhookUrl, err := url.Parse(req.Url)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "url must be an http or https url"})
+	}
+
+	webhookSubscription := entity.WebhookSubscription{
+		Url:       webhookUrl.String(),
+		Events:    entity.WebhookEventTypes{},
+		Secret:    req.Secret,
+		AuthorId:  authorId,
+		CreatedAt: time.Now().UTC(),
+	}
+	seen := map[string]bool{}
+	for _, eventType := range req.Events {
+		if !seen[eventType] {
+			webhookSubscription.Events = append(webhookSubscription.Events, eventType)
+			seen[eventType] = true
+		}
+	}
+	err = s.webhookSubscriptionRepository.CreateWebhookSubscription(c.UserContext(), &webhookSubscription)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create webhook")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(webhookSubscription)
+}
+
+func (s WebhookServer) GetWebhookSubscription(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	webhookSubscription, err := s.webhookSubscriptionRepository.GetWebhookSubscription(c.UserContext(), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Not found"})
+	}
+
+	return c.JSON(webhookSubscription)
+}
+
+// DeleteWebhookSubscription deletes the subscription with its deliveries
+func (s WebhookServer) DeleteWebhookSubscription(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	err = s.webhookSubscriptionRepository.DeleteWebhookSubscription(c.UserContext(), uint(id))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete webhook")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries pages through the delivery log, filtered by webhook, status or event
+func (s WebhookServer) ListWebhookDeliveries(c *fiber.Ctx) error {
+	var req ListWebhookDeliveriesRequest
+
+	// Validate and parse request
+	if c.Request().Header.ContentLength() > 0 {
+		errRes, valid := validateRequest(c, &req)
+		if !valid {
+			return c.Status(fiber.StatusBadRequest).JSON(errRes)
+		}
+	}
+
+	webhookDeliveries, err := s.webhookDeliveryRepository.ListWebhookDeliveries(
+		c.UserContext(),
+		req.PageSize,
+		req.LastId,
+		repository.WebhookDeliveryFilter{WebhookSubscriptionId: req.WebhookId, Status: req.Status, EventId: req.EventId},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook deliveries")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+
+	return c.JSON(webhookDeliveries)
+}
+
+// RetryWebhookDelivery puts a dead letter back in the queue, it is attempted on the next dispatch
+func (s WebhookServer) RetryWebhookDelivery(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "id is invalid"})
+	}
+
+	retried, err := s.webhookDeliveryRepository.RetryWebhookDelivery(c.UserContext(), uint(id), time.Now().UTC())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to retry webhook delivery")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Internal error"})
+	}
+	if !retried {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Delivery is not a dead letter"})
+	}
+
+	return nil
+}
+
+// requireAdmin rejects the requests of users other than admins
+func requireAdmin(c *fiber.Ctx) error {
+	if getAuthUserRole(c) != RoleAdmin {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "Unauthorized"})
+	}
+	return c.Next()
+}
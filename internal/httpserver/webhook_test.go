@@ -0,0 +1,170 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newOutboxRepository accepts the events of any test that changes questions
+func newOutboxRepository(t *testing.T) *mocks.OutboxRepository {
+	outboxRepository := mocks.NewOutboxRepository(t)
+	outboxRepository.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return outboxRepository
+}
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	type Test struct {
+		TestName               string
+		Role                   string
+		Req                    map[string]any
+		ExpectedHttpStatusCode int
+	}
+	tests := []Test{
+		{
+			TestName:               "Create",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"url": "https://ats.example.com/hooks", "events": []string{"question.created", "question.created", "question.deleted"}, "secret": "0123456789abcdef"},
+			ExpectedHttpStatusCode: http.StatusOK,
+		},
+		{
+			TestName:               "NotAdmin",
+			Req:                    map[string]any{"url": "https://ats.example.com/hooks", "events": []string{"question.created"}, "secret": "0123456789abcdef"},
+			ExpectedHttpStatusCode: http.StatusUnauthorized,
+		},
+		{
+			TestName:               "UnknownEvent",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"url": "https://ats.example.com/hooks", "events": []string{"question.viewed"}, "secret": "0123456789abcdef"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "NotHttp",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"url": "ftp://ats.example.com/hooks", "events": []string{"question.created"}, "secret": "0123456789abcdef"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+		{
+			TestName:               "ShortSecret",
+			Role:                   httpserver.RoleAdmin,
+			Req:                    map[string]any{"url": "https://ats.example.com/hooks", "events": []string{"question.created"}, "secret": "secret"},
+			ExpectedHttpStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			webhookSubscriptionRepository := mocks.NewWebhookSubscriptionRepository(t)
+			if test.ExpectedHttpStatusCode == http.StatusOK {
+				webhookSubscriptionRepository.On("CreateWebhookSubscription", mock.Anything, mock.Anything).
+					Return(func(_ context.Context, webhookSubscription *entity.WebhookSubscription) error {
+						assert.Equal(t, entity.WebhookEventTypes{"question.created", "question.deleted"}, webhookSubscription.Events)
+						assert.Equal(t, "0123456789abcdef", webhookSubscription.Secret)
+						webhookSubscription.Id = 1
+						return nil
+					})
+			}
+
+			reqBodyBytes, err := json.Marshal(test.Req)
+			require.NoError(t, err)
+			server := httpserver.NewServer(httpserver.Repositories{WebhookSubscription: webhookSubscriptionRepository}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(reqBodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", newAuthHeader(t, 1, test.Role))
+			res, err := server.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedHttpStatusCode, res.StatusCode)
+			if test.ExpectedHttpStatusCode != http.StatusOK {
+				return
+			}
+
+			var webhookSubscription map[string]any
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&webhookSubscription))
+			assert.NotContains(t, webhookSubscription, "secret")
+		})
+	}
+}
+
+func TestQuestionWebhookEvents(t *testing.T) {
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			question.Id = 1
+			return nil
+		})
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"}, gormprovider.PreloadOption("Tags")).
+		Return(entity.Question{Id: 1, AuthorId: 1}, nil)
+	questionRepository.On("DeleteQuestion", mock.Anything, uint(1)).Return(nil)
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil)
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil)
+
+	events := []entity.WebhookEvent{}
+	outboxRepository := mocks.NewOutboxRepository(t)
+	outboxRepository.On("CreateOutboxEvent", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, event entity.WebhookEvent) error {
+			events = append(events, event)
+			return nil
+		})
+
+	server := httpserver.NewServer(httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		Outbox:         outboxRepository,
+		AuditEntry:     newAuditEntryRepository(t),
+	}, nil, nil)
+	reqBody := `{"body": "Which?", "options": [{"body": "this", "correct": true}, {"body": "that", "correct": false}]}`
+	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader([]byte(reqBody)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err := server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodDelete, "/questions/1", nil)
+	req.Header.Set("Authorization", newAuthHeader(t, 1, ""))
+	res, err = server.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, entity.WebhookEventQuestionCreated, events[0].Type)
+	var question entity.Question
+	require.NoError(t, json.Unmarshal(events[0].Data, &question))
+	assert.Equal(t, uint(1), question.Id)
+	assert.Equal(t, "Which?", question.Body)
+	assert.Equal(t, entity.WebhookEventQuestionDeleted, events[1].Type)
+	assert.JSONEq(t, `{"id": 1}`, string(events[1].Data))
+	assert.NotEqual(t, events[0].Id, events[1].Id)
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	webhookDeliveryRepository := mocks.NewWebhookDeliveryRepository(t)
+	webhookDeliveryRepository.On("RetryWebhookDelivery", mock.Anything, uint(1), mock.Anything).Return(true, nil)
+	webhookDeliveryRepository.On("RetryWebhookDelivery", mock.Anything, uint(2), mock.Anything).Return(false, nil)
+
+	server := httpserver.NewServer(httpserver.Repositories{WebhookDelivery: webhookDeliveryRepository}, nil, nil)
+	for id, expectedHttpStatusCode := range map[string]int{"1": http.StatusOK, "2": http.StatusConflict} {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/deliveries/"+id+"/retry", nil)
+		req.Header.Set("Authorization", newAuthHeader(t, 1, httpserver.RoleAdmin))
+		res, err := server.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, expectedHttpStatusCode, res.StatusCode, id)
+	}
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"challenge/internal/entity"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSV columns, only body is required. Question level columns are read from the first row of every question
+const (
+	CSVColumnQuestion          = "question" // Key grouping the rows of a question, defaults to the body
+	CSVColumnType              = "type"
+	CSVColumnBody              = "body"
+	CSVColumnDifficulty        = "difficulty"
+	CSVColumnDurationSeconds   = "durationSeconds"
+	CSVColumnTags              = "tags" // Separated by |
+	CSVColumnNumericAnswer     = "numericAnswer"
+	CSVColumnNumericTolerance  = "numericTolerance"
+	CSVColumnOption            = "option"
+	CSVColumnCorrect           = "correct"
+	CSVColumnBodyFormat        = "bodyFormat"
+	CSVColumnOptionFormat      = "optionFormat"
+	CSVColumnExplanation       = "explanation"
+	CSVColumnExplanationFormat = "explanationFormat"
+	CSVColumnFeedback          = "feedback" // Feedback of the option of the row
+	CSVColumnFeedbackFormat    = "feedbackFormat"
+)
+
+var csvColumns = []string{
+	CSVColumnQuestion,
+	CSVColumnType,
+	CSVColumnBody,
+	CSVColumnDifficulty,
+	CSVColumnDurationSeconds,
+	CSVColumnTags,
+	CSVColumnNumericAnswer,
+	CSVColumnNumericTolerance,
+	CSVColumnOption,
+	CSVColumnCorrect,
+	CSVColumnBodyFormat,
+	CSVColumnOptionFormat,
+	CSVColumnExplanation,
+	CSVColumnExplanationFormat,
+	CSVColumnFeedback,
+	CSVColumnFeedbackFormat,
+}
+
+// ParseCSV reads a header row followed by one row per option.
+// Consecutive rows with the same question key are options of the same question
+func ParseCSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columnIndexes := map[string]int{}
+	for i, name := range header {
+		column := matchCSVColumn(strings.TrimSpace(name))
+		if column == "" {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columnIndexes[column] = i
+	}
+	if _, ok := columnIndexes[CSVColumnBody]; !ok {
+		return nil, errors.New("missing body column")
+	}
+
+	items := []Item{}
+	var lastKey string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			items = append(items, Item{Row: parseErr.StartLine, Err: err})
+			lastKey = ""
+			continue
+		}
+		// The position is only known for the records read
+		line, _ := reader.FieldPos(0)
+
+		field := func(column string) string {
+			i, ok := columnIndexes[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		key := field(CSVColumnQuestion)
+		if key == "" {
+			key = field(CSVColumnBody)
+		}
+		if len(items) == 0 || key != lastKey {
+			items = append(items, parseCSVQuestion(line, field))
+			lastKey = key
+		}
+
+		item := &items[len(items)-1]
+		if item.Err != nil || field(CSVColumnOption) == "" {
+			continue
+		}
+		questionOption := entity.QuestionOption{
+			Body:           field(CSVColumnOption),
+			BodyFormat:     field(CSVColumnOptionFormat),
+			Feedback:       field(CSVColumnFeedback),
+			FeedbackFormat: field(CSVColumnFeedbackFormat),
+		}
+		if correct := field(CSVColumnCorrect); correct != "" {
+			value, err := strconv.ParseBool(correct)
+			if err != nil {
+				item.Err = fmt.Errorf("line %d: correct is invalid", line)
+				continue
+			}
+			questionOption.Correct = &value
+		}
+		item.Question.QuestionOptions = append(item.Question.QuestionOptions, questionOption)
+	}
+}
+
+func parseCSVQuestion(line int, field func(column string) string) Item {
+	item := Item{
+		Row: line,
+		Question: entity.Question{
+			Type:              field(CSVColumnType),
+			Body:              field(CSVColumnBody),
+			BodyFormat:        field(CSVColumnBodyFormat),
+			Explanation:       field(CSVColumnExplanation),
+			ExplanationFormat: field(CSVColumnExplanationFormat),
+			Difficulty:        field(CSVColumnDifficulty),
+		},
+	}
+
+	if durationSeconds := field(CSVColumnDurationSeconds); durationSeconds != "" {
+		value, err := strconv.ParseUint(durationSeconds, 10, 32)
+		if err != nil {
+			item.Err = errors.New("durationSeconds is invalid")
+			return item
+		}
+		item.Question.DurationSeconds = uint(value)
+	}
+	if numericAnswer := field(CSVColumnNumericAnswer); numericAnswer != "" {
+		value, err := strconv.ParseFloat(numericAnswer, 64)
+		if err != nil {
+			item.Err = errors.New("numericAnswer is invalid")
+			return item
+		}
+		item.Question.NumericAnswer = &value
+	}
+	if numericTolerance := field(CSVColumnNumericTolerance); numericTolerance != "" {
+		value, err := strconv.ParseFloat(numericTolerance, 64)
+		if err != nil {
+			item.Err = errors.New("numericTolerance is invalid")
+			return item
+		}
+		item.Question.NumericTolerance = value
+	}
+	if tags := field(CSVColumnTags); tags != "" {
+		for _, tag := range strings.Split(tags, "|") {
+			item.Question.Tags = append(item.Question.Tags, entity.QuestionTag{Name: strings.TrimSpace(tag)})
+		}
+	}
+
+	return item
+}
+
+func matchCSVColumn(name string) string {
+	for _, column := range csvColumns {
+		if strings.EqualFold(column, name) {
+			return column
+		}
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+package importer
+
+import (
+	"bufio"
+	"challenge/internal/entity"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// giftEscapable are the characters GIFT escapes with a backslash
+const giftEscapable = "~=#{}:\\"
+
+// ParseGIFT reads Moodle GIFT questions separated by blank lines.
+// Multiple choice, true/false and numeric questions are supported
+func ParseGIFT(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	var block []string
+	blockLine := 0
+
+	flush := func() {
+		if len(block) > 0 {
+			question, err := parseGIFTQuestion(strings.Join(block, "\n"))
+			items = append(items, Item{Row: blockLine, Question: question, Err: err})
+		}
+		block = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "":
+			flush()
+		case strings.HasPrefix(text, "//"), strings.HasPrefix(text, "$CATEGORY:"):
+			continue
+		default:
+			if len(block) == 0 {
+				blockLine = line
+			}
+			block = append(block, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return items, nil
+}
+
+func parseGIFTQuestion(text string) (entity.Question, error) {
+	// Titles are not kept
+	if strings.HasPrefix(text, "::") {
+		end := indexUnescaped(text[2:], "::")
+		if end < 0 {
+			return entity.Question{}, errors.New("title is not closed")
+		}
+		text = strings.TrimSpace(text[end+4:])
+	}
+	// Text formats are not supported, the text is kept as is
+	if strings.HasPrefix(text, "[") {
+		if end := strings.Index(text, "]"); end > 0 {
+			text = text[end+1:]
+		}
+	}
+
+	start := indexUnescaped(text, "{")
+	if start < 0 {
+		return entity.Question{}, errors.New("answers are missing")
+	}
+	end := indexUnescaped(text[start:], "}")
+	if end < 0 {
+		return entity.Question{}, errors.New("answers are not closed")
+	}
+	end += start
+
+	question := entity.Question{Body: unescapeGIFT(strings.TrimSpace(text[:start]))}
+	// Answers in the middle of the text are a blank to fill
+	if after := strings.TrimSpace(text[end+1:]); after != "" {
+		question.Body += " _____ " + unescapeGIFT(after)
+	}
+
+	answers := strings.TrimSpace(text[start+1 : end])
+	var err error
+	switch {
+	case strings.HasPrefix(answers, "#"):
+		err = parseGIFTNumeric(&question, answers[1:])
+	case isGIFTBoolean(answers):
+		parseGIFTBoolean(&question, answers)
+	default:
+		err = parseGIFTChoices(&question, answers)
+	}
+	return question, err
+}
+
+func isGIFTBoolean(answers string) bool {
+	switch strings.ToUpper(cutUnescaped(answers, "#")) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+func parseGIFTBoolean(question *entity.Question, answers string) {
+	answer := strings.ToUpper(cutUnescaped(answers, "#"))
+	isTrue := answer == "T" || answer == "TRUE"
+	isFalse := !isTrue
+	question.Type = entity.QuestionTypeSingleChoice
+	question.QuestionOptions = []entity.QuestionOption{
+		{Body: "True", Correct: &isTrue},
+		{Body: "False", Correct: &isFalse},
+	}
+}
+
+// parseGIFTNumeric reads "answer", "answer:tolerance" and "min..max" answers
+func parseGIFTNumeric(question *entity.Question, answer string) error {
+	answer = cutUnescaped(answer, "#")
+	if strings.ContainsAny(answer, "=~") {
+		return errors.New("numeric questions with many answers are not supported")
+	}
+	question.Type = entity.QuestionTypeNumeric
+
+	var value, tolerance float64
+	var err error
+	if min, max, isRange := strings.Cut(answer, ".."); isRange {
+		var minValue, maxValue float64
+		minValue, err = strconv.ParseFloat(strings.TrimSpace(min), 64)
+		if err == nil {
+			maxValue, err = strconv.ParseFloat(strings.TrimSpace(max), 64)
+		}
+		value = (minValue + maxValue) / 2
+		tolerance = (maxValue - minValue) / 2
+	} else {
+		number, toleranceText, hasTolerance := strings.Cut(answer, ":")
+		value, err = strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err == nil && hasTolerance {
+			tolerance, err = strconv.ParseFloat(strings.TrimSpace(toleranceText), 64)
+		}
+	}
+	if err != nil || tolerance < 0 {
+		return errors.New("numeric answer is invalid")
+	}
+
+	question.NumericAnswer = &value
+	question.NumericTolerance = tolerance
+	return nil
+}
+
+// parseGIFTChoices reads "=right ~wrong" single choice answers and "~%50%right ~%-100%wrong"
+// multiple choice answers, where options with a positive weight are correct
+func parseGIFTChoices(question *entity.Question, answers string) error {
+	weighted := false
+	hasWrong := false
+	correctCount := 0
+	for len(answers) > 0 {
+		marker := answers[0]
+		if marker != '=' && marker != '~' {
+			return errors.New("answers must start with = or ~")
+		}
+		next := indexAnyUnescaped(answers[1:], "=~")
+		var answer string
+		if next < 0 {
+			answer, answers = answers[1:], ""
+		} else {
+			answer, answers = answers[1:next+1], strings.TrimSpace(answers[next+1:])
+		}
+		answer = strings.TrimSpace(cutUnescaped(answer, "#"))
+		if indexUnescaped(answer, "->") >= 0 {
+			return errors.New("matching questions are not supported")
+		}
+
+		correct := marker == '='
+		if strings.HasPrefix(answer, "%") {
+			weightEnd := strings.Index(answer[1:], "%")
+			if weightEnd < 0 {
+				return errors.New("answer weight is not closed")
+			}
+			weight, err := strconv.ParseFloat(answer[1:weightEnd+1], 64)
+			if err != nil {
+				return errors.New("answer weight is invalid")
+			}
+			weighted = true
+			correct = weight > 0
+			answer = strings.TrimSpace(answer[weightEnd+2:])
+		}
+		if correct {
+			correctCount++
+		}
+		if marker == '~' {
+			hasWrong = true
+		}
+
+		question.QuestionOptions = append(question.QuestionOptions, entity.QuestionOption{Body: unescapeGIFT(answer), Correct: &correct})
+	}
+
+	if !hasWrong {
+		return errors.New("short answer questions are not supported")
+	}
+	if weighted && correctCount > 1 {
+		question.Type = entity.QuestionTypeMultipleChoice
+	} else {
+		question.Type = entity.QuestionTypeSingleChoice
+	}
+	return nil
+}
+
+// cutUnescaped returns the text before the first unescaped separator, like answer feedback
+func cutUnescaped(text string, separator string) string {
+	if i := indexUnescaped(text, separator); i >= 0 {
+		return strings.TrimSpace(text[:i])
+	}
+	return strings.TrimSpace(text)
+}
+
+func indexUnescaped(text string, substr string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(text[i:], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexAnyUnescaped(text string, chars string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte(chars, text[i]) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeGIFT(text string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			if text[i+1] == 'n' {
+				builder.WriteByte('\n')
+				i++
+				continue
+			}
+			if strings.IndexByte(giftEscapable, text[i+1]) >= 0 {
+				builder.WriteByte(text[i+1])
+				i++
+				continue
+			}
+		}
+		builder.WriteByte(text[i])
+	}
+	return builder.String()
+}
@@ -0,0 +1,44 @@
+// Package importer parses question files from other tools into questions
+package importer
+
+import (
+	"challenge/internal/entity"
+	"errors"
+	"io"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatGIFT   = "gift"
+	FormatQTI    = "qti"
+)
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Item is a question parsed from an import file, or the reason it could not be parsed
+type Item struct {
+	Row      int // Element or line number where the question starts, from 1
+	Question entity.Question
+	Err      error
+}
+
+// Parse reads every question in the file. Malformed questions are returned as items with an error,
+// the error is only returned when the file as a whole cannot be read. maxSize bounds the size archive formats unpack to
+func Parse(format string, r io.Reader, maxSize int64) ([]Item, error) {
+	switch format {
+	case FormatJSON:
+		return ParseJSON(r)
+	case FormatNDJSON:
+		return ParseNDJSON(r)
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatGIFT:
+		return ParseGIFT(r)
+	case FormatQTI:
+		return ParseQTI(r, maxSize)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
@@ -0,0 +1,246 @@
+package importer_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/importer"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boolPointer(value bool) *bool {
+	return &value
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+func TestParseJSON(t *testing.T) {
+	items, err := importer.ParseJSON(strings.NewReader(`[
+		{"body": "question", "options": [{"body": "option", "correct": true}], "tags": ["go"]},
+		{"body": 1}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, 1, items[0].Row)
+	assert.NoError(t, items[0].Err)
+	assert.Equal(t, entity.Question{
+		Body:            "question",
+		QuestionOptions: []entity.QuestionOption{{Body: "option", Correct: boolPointer(true)}},
+		Tags:            []entity.QuestionTag{{Name: "go"}},
+	}, items[0].Question)
+
+	assert.Equal(t, 2, items[1].Row)
+	assert.Error(t, items[1].Err)
+
+	_, err = importer.ParseJSON(strings.NewReader(`{"body": "question"}`))
+	assert.Error(t, err)
+}
+
+func TestParseCSV(t *testing.T) {
+	items, err := importer.ParseCSV(strings.NewReader(strings.Join([]string{
+		"question,type,body,difficulty,durationSeconds,tags,numericAnswer,numericTolerance,option,correct",
+		"1,,2+2,easy,30,math|basic,,,4,true",
+		"1,,2+2,,,,,,5,false",
+		"2,numeric,pi,,,,3.14,0.01,,",
+		",,same body,,,,,,a,true",
+		",,same body,,,,,,b,false",
+		"3,,broken,,,,,,a,maybe",
+		"4,,slow,,forever,,,,a,true",
+	}, "\n")))
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+
+	assert.Equal(t, 2, items[0].Row)
+	assert.NoError(t, items[0].Err)
+	assert.Equal(t, entity.Question{
+		Body:            "2+2",
+		Difficulty:      entity.DifficultyEasy,
+		DurationSeconds: 30,
+		QuestionOptions: []entity.QuestionOption{
+			{Body: "4", Correct: boolPointer(true)},
+			{Body: "5", Correct: boolPointer(false)},
+		},
+		Tags: []entity.QuestionTag{{Name: "math"}, {Name: "basic"}},
+	}, items[0].Question)
+
+	assert.Equal(t, 4, items[1].Row)
+	assert.NoError(t, items[1].Err)
+	assert.Equal(t, entity.Question{
+		Type:             entity.QuestionTypeNumeric,
+		Body:             "pi",
+		NumericAnswer:    floatPointer(3.14),
+		NumericTolerance: 0.01,
+	}, items[1].Question)
+
+	assert.Equal(t, 5, items[2].Row)
+	assert.NoError(t, items[2].Err)
+	assert.Len(t, items[2].Question.QuestionOptions, 2)
+
+	assert.Equal(t, 7, items[3].Row)
+	assert.Error(t, items[3].Err)
+	assert.Equal(t, 8, items[4].Row)
+	assert.Error(t, items[4].Err)
+}
+
+func TestParseCSV_MalformedRow(t *testing.T) {
+	items, err := importer.ParseCSV(strings.NewReader("body,option,correct\n\"a\"b,c,true\nq,o,true\nr,p,false\n"))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, 2, items[0].Row)
+	var parseErr *csv.ParseError
+	assert.ErrorAs(t, items[0].Err, &parseErr)
+	assert.Equal(t, 3, items[1].Row)
+	assert.NoError(t, items[1].Err)
+	assert.Equal(t, "q", items[1].Question.Body)
+	assert.Equal(t, 4, items[2].Row)
+	assert.NoError(t, items[2].Err)
+}
+
+func TestParseCSV_InvalidHeader(t *testing.T) {
+	_, err := importer.ParseCSV(strings.NewReader("body,unknown\nquestion,value"))
+	assert.Error(t, err)
+
+	_, err = importer.ParseCSV(strings.NewReader("option,correct\na,true"))
+	assert.Error(t, err)
+}
+
+func TestParseGIFT(t *testing.T) {
+	type Test struct {
+		TestName         string
+		GIFT             string
+		ExpectedQuestion entity.Question
+		ExpectedErr      bool
+	}
+	tests := []Test{
+		{
+			TestName: "SingleChoice",
+			GIFT:     "::Title:: Who wrote Go? {=Google ~Oracle#No ~Microsoft}",
+			ExpectedQuestion: entity.Question{
+				Type: entity.QuestionTypeSingleChoice,
+				Body: "Who wrote Go?",
+				QuestionOptions: []entity.QuestionOption{
+					{Body: "Google", Correct: boolPointer(true)},
+					{Body: "Oracle", Correct: boolPointer(false)},
+					{Body: "Microsoft", Correct: boolPointer(false)},
+				},
+			},
+		},
+		{
+			TestName: "MultipleChoice",
+			GIFT:     "Pick the primes {\n~%50%2\n~%50%3\n~%-100%4\n}",
+			ExpectedQuestion: entity.Question{
+				Type: entity.QuestionTypeMultipleChoice,
+				Body: "Pick the primes",
+				QuestionOptions: []entity.QuestionOption{
+					{Body: "2", Correct: boolPointer(true)},
+					{Body: "3", Correct: boolPointer(true)},
+					{Body: "4", Correct: boolPointer(false)},
+				},
+			},
+		},
+		{
+			TestName: "TrueFalse",
+			GIFT:     "Go has generics {TRUE}",
+			ExpectedQuestion: entity.Question{
+				Type: entity.QuestionTypeSingleChoice,
+				Body: "Go has generics",
+				QuestionOptions: []entity.QuestionOption{
+					{Body: "True", Correct: boolPointer(true)},
+					{Body: "False", Correct: boolPointer(false)},
+				},
+			},
+		},
+		{
+			TestName: "NumericTolerance",
+			GIFT:     "Value of pi {#3.14:0.01}",
+			ExpectedQuestion: entity.Question{
+				Type:             entity.QuestionTypeNumeric,
+				Body:             "Value of pi",
+				NumericAnswer:    floatPointer(3.14),
+				NumericTolerance: 0.01,
+			},
+		},
+		{
+			TestName: "NumericRange",
+			GIFT:     "Pick a number {#1..5}",
+			ExpectedQuestion: entity.Question{
+				Type:             entity.QuestionTypeNumeric,
+				Body:             "Pick a number",
+				NumericAnswer:    floatPointer(3),
+				NumericTolerance: 2,
+			},
+		},
+		{
+			TestName: "FillTheBlankAndEscapes",
+			GIFT:     `1 \= 1 is {=true ~false} in Go \{\}`,
+			ExpectedQuestion: entity.Question{
+				Type: entity.QuestionTypeSingleChoice,
+				Body: "1 = 1 is _____ in Go {}",
+				QuestionOptions: []entity.QuestionOption{
+					{Body: "true", Correct: boolPointer(true)},
+					{Body: "false", Correct: boolPointer(false)},
+				},
+			},
+		},
+		{
+			TestName:    "ShortAnswer",
+			GIFT:        "Capital of France {=Paris}",
+			ExpectedErr: true,
+		},
+		{
+			TestName:    "Matching",
+			GIFT:        "Match {=a -> 1 =b -> 2 ~c}",
+			ExpectedErr: true,
+		},
+		{
+			TestName:    "MissingAnswers",
+			GIFT:        "Just a description",
+			ExpectedErr: true,
+		},
+		{
+			TestName:    "InvalidNumeric",
+			GIFT:        "Value {#pi}",
+			ExpectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			items, err := importer.ParseGIFT(strings.NewReader(test.GIFT))
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			if test.ExpectedErr {
+				assert.Error(t, items[0].Err)
+				return
+			}
+			require.NoError(t, items[0].Err)
+			assert.Equal(t, test.ExpectedQuestion, items[0].Question)
+		})
+	}
+}
+
+func TestParseGIFT_Rows(t *testing.T) {
+	items, err := importer.ParseGIFT(strings.NewReader(strings.Join([]string{
+		"// Exported from Moodle",
+		"$CATEGORY: $course$/Go",
+		"",
+		"First {=a ~b}",
+		"",
+		"",
+		"Second {",
+		"=a",
+		"~b",
+		"}",
+	}, "\n")))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, 4, items[0].Row)
+	assert.Equal(t, 7, items[1].Row)
+	assert.Equal(t, "Second", items[1].Question.Body)
+}
@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"bufio"
+	"challenge/internal/entity"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// ParseJSON reads an array of questions in the same shape accepted by POST /questions
+func ParseJSON(r io.Reader) ([]Item, error) {
+	var elements []json.RawMessage
+	err := json.NewDecoder(r).Decode(&elements)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, len(elements))
+	for i, element := range elements {
+		items[i].Row = i + 1
+		var question entity.Question
+		items[i].Err = json.Unmarshal(element, &question)
+		items[i].Question = question
+	}
+	return items, nil
+}
+
+// ParseNDJSON reads a question per line, blank lines are skipped
+func ParseNDJSON(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		item := Item{Row: line}
+		item.Err = json.Unmarshal([]byte(text), &item.Question)
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
@@ -0,0 +1,29 @@
+package importer
+
+import (
+	"challenge/internal/qti"
+	"fmt"
+	"io"
+)
+
+// ParseQTI reads the choice items of a QTI 2.1 content package zip, rows are the item order in the manifest.
+// The files read from the package unpack to maxSize bytes at most
+func ParseQTI(r io.Reader, maxSize int64) ([]Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	packageItems, err := qti.ReadPackage(data, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, len(packageItems))
+	for i, packageItem := range packageItems {
+		items[i] = Item{Row: i + 1, Question: packageItem.Question}
+		if packageItem.Err != nil {
+			items[i].Err = fmt.Errorf("%s: %w", packageItem.Href, packageItem.Err)
+		}
+	}
+	return items, nil
+}
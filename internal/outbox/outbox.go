@@ -0,0 +1,129 @@
+// Package outbox publishes the events written to the outbox table by the transactions that changed
+// questions, at least once and oldest first
+package outbox
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/webhook"
+	"challenge/pkg/env"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DispatchInterval reads how often outbox events are published, every second by default
+func DispatchInterval() time.Duration {
+	return env.GetDurationOrDefault("OUTBOX_DISPATCH_INTERVAL", time.Second)
+}
+
+// Lease reads how long a dispatch owns the events it publishes, 30 seconds by default.
+// The events of a dispatch that crashed are published by another one once the lease expires
+func Lease() time.Duration {
+	return env.GetDurationOrDefault("OUTBOX_LEASE", 30*time.Second)
+}
+
+// RetryBackoff reads the delay before publishing an event again after a failure, doubled on every failure,
+// 5 seconds by default
+func RetryBackoff() time.Duration {
+	return env.GetDurationOrDefault("OUTBOX_RETRY_BACKOFF", 5*time.Second)
+}
+
+// Emit writes an event of the type with the data to the outbox. It must run in the transaction
+// of the change so the event is only published when the change is committed
+func Emit(ctx context.Context, outboxRepository repository.OutboxRepository, eventType string, data any) error {
+	event, err := webhook.NewEvent(eventType, data, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return outboxRepository.CreateOutboxEvent(ctx, event)
+}
+
+type DispatchResult struct {
+	Published uint `json:"published"`
+	Failed    uint `json:"failed"` // Published again after the backoff
+}
+
+// Dispatcher leases the available events of the outbox and publishes them
+type Dispatcher struct {
+	outboxRepository repository.OutboxRepository
+	publisher        Publisher
+	lease            time.Duration
+	retryBackoff     time.Duration
+}
+
+func NewDispatcher(outboxRepository repository.OutboxRepository, publisher Publisher, lease time.Duration, retryBackoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		outboxRepository: outboxRepository,
+		publisher:        publisher,
+		lease:            lease,
+		retryBackoff:     retryBackoff,
+	}
+}
+
+// Dispatch publishes every event available at the given time
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) (DispatchResult, error) {
+	var result DispatchResult
+	for {
+		outboxEvents, err := d.outboxRepository.LeaseOutboxEvents(ctx, now, d.lease, webhook.DispatchBatchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, outboxEvent := range outboxEvents {
+			outboxEvent.Attempts++
+			err := d.publish(ctx, outboxEvent)
+			if err == nil {
+				result.Published++
+				err = d.outboxRepository.MarkOutboxEventPublished(ctx, outboxEvent, now)
+			} else {
+				result.Failed++
+				outboxEvent.LastError = webhook.LastError(err)
+				outboxEvent.AvailableAt = now.Add(webhook.Backoff(d.retryBackoff, outboxEvent.Attempts))
+				err = d.outboxRepository.ReleaseOutboxEvent(ctx, outboxEvent)
+			}
+			// The remaining events of the batch are leased again once the lease expires
+			if err != nil {
+				return result, err
+			}
+		}
+		// Failed events are available later, so the next batch only has events not published yet
+		if len(outboxEvents) < webhook.DispatchBatchSize {
+			return result, nil
+		}
+	}
+}
+
+func (d *Dispatcher) publish(ctx context.Context, outboxEvent entity.OutboxEvent) error {
+	var event entity.WebhookEvent
+	err := json.Unmarshal([]byte(outboxEvent.Payload), &event)
+	if err != nil {
+		return err
+	}
+	return d.publisher.Publish(ctx, event)
+}
+
+// Run publishes the available events every interval until the context is done
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := d.Dispatch(ctx, now.UTC())
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to dispatch outbox events")
+				continue
+			}
+			if result.Failed > 0 {
+				log.Warn().
+					Uint("published", result.Published).
+					Uint("failed", result.Failed).
+					Msg("Failed to publish outbox events")
+			}
+		}
+	}
+}
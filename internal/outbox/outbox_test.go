@@ -0,0 +1,117 @@
+package outbox_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/outbox"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingPublisher struct {
+	failures int
+	*outbox.MemoryPublisher
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event entity.WebhookEvent) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func newOutboxRepository(t *testing.T) (*gormprovider.SQLiteProvider, repository.OutboxRepository) {
+	databaseInitSql, err := os.ReadFile("../../database_init.sql")
+	require.NoError(t, err)
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, string(databaseInitSql))
+	return sqlProvider, repository.NewOutboxRepository(sqlProvider)
+}
+
+func TestEmit(t *testing.T) {
+	sqlProvider, outboxRepo := newOutboxRepository(t)
+	ctx := context.Background()
+
+	// Events emitted in a rolled back transaction are never published
+	err := sqlProvider.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := outbox.Emit(txCtx, outboxRepo, entity.WebhookEventQuestionDeleted, map[string]uint{"id": 1})
+		require.NoError(t, err)
+		return errors.New("rolled back")
+	})
+	require.Error(t, err)
+	err = sqlProvider.RunInTransaction(ctx, func(txCtx context.Context) error {
+		return outbox.Emit(txCtx, outboxRepo, entity.WebhookEventQuestionDeleted, map[string]uint{"id": 2})
+	})
+	require.NoError(t, err)
+
+	publisher := outbox.NewMemoryPublisher()
+	result, err := outbox.NewDispatcher(outboxRepo, publisher, time.Minute, time.Second).Dispatch(ctx, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{Published: 1}, result)
+	events := publisher.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, entity.WebhookEventQuestionDeleted, events[0].Type)
+	assert.JSONEq(t, `{"id": 2}`, string(events[0].Data))
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	_, outboxRepo := newOutboxRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, eventType := range []string{entity.WebhookEventQuestionCreated, entity.WebhookEventQuestionUpdated} {
+		require.NoError(t, outbox.Emit(ctx, outboxRepo, eventType, map[string]uint{"id": 1}))
+	}
+
+	// Failed events are published again after the backoff
+	publisher := &failingPublisher{failures: 1, MemoryPublisher: outbox.NewMemoryPublisher()}
+	dispatcher := outbox.NewDispatcher(outboxRepo, publisher, time.Minute, 10*time.Second)
+	result, err := dispatcher.Dispatch(ctx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{Published: 1, Failed: 1}, result)
+	result, err = dispatcher.Dispatch(ctx, now.Add(5*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{}, result)
+	result, err = dispatcher.Dispatch(ctx, now.Add(11*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{Published: 1}, result)
+
+	events := publisher.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, entity.WebhookEventQuestionUpdated, events[0].Type)
+	assert.Equal(t, entity.WebhookEventQuestionCreated, events[1].Type)
+}
+
+func TestDispatcher_Lease(t *testing.T) {
+	_, outboxRepo := newOutboxRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, outbox.Emit(ctx, outboxRepo, entity.WebhookEventQuestionCreated, map[string]uint{"id": 1}))
+
+	// A dispatch that crashed after leasing holds the event until its lease expires
+	leased, err := outboxRepo.LeaseOutboxEvents(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, leased, 1)
+
+	publisher := outbox.NewMemoryPublisher()
+	dispatcher := outbox.NewDispatcher(outboxRepo, publisher, time.Minute, time.Second)
+	result, err := dispatcher.Dispatch(ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{}, result)
+	result, err = dispatcher.Dispatch(ctx, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{Published: 1}, result)
+
+	// The crashed dispatch lost its lease, so it can't mark the event published again
+	require.NoError(t, outboxRepo.MarkOutboxEventPublished(ctx, leased[0], now.Add(3*time.Minute)))
+	result, err = dispatcher.Dispatch(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, outbox.DispatchResult{}, result)
+	assert.Len(t, publisher.Events(), 1)
+}
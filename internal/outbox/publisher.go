@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/env"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Publisher publishes the events of the outbox. Events are published at least once, so publishers
+// should ignore the events they already published, which are identified by their id
+type Publisher interface {
+	Publish(ctx context.Context, event entity.WebhookEvent) error
+}
+
+// NewPublisherFromEnv builds the publisher selected by OUTBOX_PUBLISHER, webhook by default
+func NewPublisherFromEnv(webhookDeliveryRepository repository.WebhookDeliveryRepository) (Publisher, error) {
+	switch publisherType := env.GetOrDefault("OUTBOX_PUBLISHER", "webhook"); publisherType {
+	case "webhook":
+		return NewWebhookPublisher(webhookDeliveryRepository), nil
+	case "log":
+		return LogPublisher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", publisherType)
+	}
+}
+
+// WebhookPublisher enqueues the deliveries of the events to the webhook subscriptions
+type WebhookPublisher struct {
+	webhookDeliveryRepository repository.WebhookDeliveryRepository
+}
+
+func NewWebhookPublisher(webhookDeliveryRepository repository.WebhookDeliveryRepository) *WebhookPublisher {
+	return &WebhookPublisher{webhookDeliveryRepository: webhookDeliveryRepository}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event entity.WebhookEvent) error {
+	return p.webhookDeliveryRepository.EnqueueWebhookEvent(ctx, event)
+}
+
+// LogPublisher writes the events to the log
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, event entity.WebhookEvent) error {
+	log.Info().
+		Str("id", event.Id).
+		Str("type", event.Type).
+		RawJSON("data", event.Data).
+		Msg("Published event")
+	return nil
+}
+
+// MemoryPublisher keeps the events in memory
+type MemoryPublisher struct {
+	mux    sync.Mutex
+	events []entity.WebhookEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{events: []entity.WebhookEvent{}}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event entity.WebhookEvent) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the published events in publication order
+func (p *MemoryPublisher) Events() []entity.WebhookEvent {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return append([]entity.WebhookEvent{}, p.events...)
+}
@@ -0,0 +1,165 @@
+package qti
+
+import (
+	"encoding/xml"
+	"strings"
+	"unicode"
+)
+
+// blockElements are the XHTML elements that start a new paragraph of the flattened text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "div": true, "dl": true, "dd": true, "dt": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// skippedElements are the QTI elements inside the content that are not part of the question text
+var skippedElements = map[string]bool{
+	"feedbackBlock": true, "feedbackInline": true, "rubricBlock": true, "templateBlock": true, "templateInline": true,
+}
+
+// flowText is the XHTML content of an element flattened to markdown-like text: paragraphs are separated by a blank
+// line, list items start with "- " and whitespace is collapsed outside <pre>. Content without elements is kept as is
+type flowText string
+
+func (t *flowText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	text, err := readFlowText(d, nil)
+	*t = flowText(text)
+	return err
+}
+
+// readFlowText flattens the content of the element just started until its end. Child elements handled by
+// onElement, which returns whether it consumed them, are left out of the text
+func readFlowText(d *xml.Decoder, onElement func(xml.StartElement) (bool, error)) (string, error) {
+	w := flowWriter{}
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if onElement != nil {
+				consumed, err := onElement(token)
+				if err != nil {
+					return "", err
+				}
+				if consumed {
+					continue
+				}
+			}
+			if skippedElements[token.Name.Local] {
+				if err := d.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			depth++
+			w.start(token.Name.Local)
+		case xml.EndElement:
+			if depth == 0 {
+				return w.String(), nil
+			}
+			depth--
+			w.end(token.Name.Local)
+		case xml.CharData:
+			w.text(string(token))
+		}
+	}
+}
+
+type flowWriter struct {
+	raw         strings.Builder
+	b           strings.Builder
+	hasElements bool
+	pre         int
+	// pendingBreak separates the next text from the text before it
+	pendingBreak string
+}
+
+func (w *flowWriter) start(name string) {
+	w.hasElements = true
+	switch {
+	case name == "br":
+		w.lineBreak("\n")
+	case name == "li":
+		w.lineBreak("\n")
+		w.pendingBreak += "- "
+	case blockElements[name]:
+		w.lineBreak("\n\n")
+		if name == "pre" {
+			w.pre++
+		}
+	}
+}
+
+func (w *flowWriter) end(name string) {
+	if blockElements[name] {
+		w.lineBreak("\n\n")
+		if name == "pre" {
+			w.pre--
+		}
+	}
+}
+
+// lineBreak requests a break before the next text, the widest break requested wins
+func (w *flowWriter) lineBreak(separator string) {
+	if len(separator) > len(w.pendingBreak) {
+		w.pendingBreak = separator
+	}
+}
+
+func (w *flowWriter) text(s string) {
+	w.raw.WriteString(s)
+	if w.pre == 0 {
+		s = collapseSpace(s)
+		// Leading whitespace is not rendered at the start of a line
+		if w.b.Len() == 0 || w.pendingBreak != "" || strings.HasSuffix(w.b.String(), " ") {
+			s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		}
+	}
+	if s == "" {
+		return
+	}
+	if w.pendingBreak != "" {
+		if w.b.Len() > 0 {
+			trimmed := strings.TrimRightFunc(w.b.String(), unicode.IsSpace)
+			w.b.Reset()
+			w.b.WriteString(trimmed)
+			w.b.WriteString(w.pendingBreak)
+		} else {
+			w.b.WriteString(strings.TrimLeft(w.pendingBreak, "\n"))
+		}
+		w.pendingBreak = ""
+	}
+	w.b.WriteString(s)
+}
+
+func (w *flowWriter) String() string {
+	if !w.hasElements {
+		return w.raw.String()
+	}
+	return strings.TrimSpace(w.b.String())
+}
+
+// collapseSpace replaces every run of whitespace with a single space
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
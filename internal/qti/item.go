@@ -0,0 +1,178 @@
+// Package qti maps choice questions to and from IMS QTI 2.1 items and content packages
+package qti
+
+import (
+	"challenge/internal/entity"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	itemNamespace = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	// matchCorrectTemplate scores the item with 1 when the response matches the correct response
+	matchCorrectTemplate = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	responseIdentifier   = "RESPONSE"
+)
+
+var ErrUnsupportedQuestionType = errors.New("only choice questions are supported by QTI")
+
+type assessmentItem struct {
+	XMLName             xml.Name            `xml:"assessmentItem"`
+	Xmlns               string              `xml:"xmlns,attr,omitempty"`
+	Identifier          string              `xml:"identifier,attr"`
+	Title               string              `xml:"title,attr"`
+	Adaptive            bool                `xml:"adaptive,attr"`
+	TimeDependent       bool                `xml:"timeDependent,attr"`
+	ResponseDeclaration responseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclaration  outcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody            itemBody            `xml:"itemBody"`
+	ResponseProcessing  responseProcessing  `xml:"responseProcessing"`
+}
+
+type responseDeclaration struct {
+	Identifier      string   `xml:"identifier,attr"`
+	Cardinality     string   `xml:"cardinality,attr"`
+	BaseType        string   `xml:"baseType,attr"`
+	CorrectResponse []string `xml:"correctResponse>value"`
+}
+
+type outcomeDeclaration struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type itemBody struct {
+	Text              string            `xml:"-"`
+	ChoiceInteraction choiceInteraction `xml:"choiceInteraction"`
+}
+
+// UnmarshalXML reads the choice interaction, also when it is nested in XHTML blocks, and the text around it
+func (b *itemBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	text, err := readFlowText(d, func(element xml.StartElement) (bool, error) {
+		if element.Name.Local != "choiceInteraction" {
+			return false, nil
+		}
+		return true, d.DecodeElement(&b.ChoiceInteraction, &element)
+	})
+	b.Text = text
+	return err
+}
+
+type choiceInteraction struct {
+	ResponseIdentifier string         `xml:"responseIdentifier,attr"`
+	Shuffle            bool           `xml:"shuffle,attr"`
+	MaxChoices         int            `xml:"maxChoices,attr"`
+	Prompt             flowText       `xml:"prompt,omitempty"`
+	SimpleChoices      []simpleChoice `xml:"simpleChoice"`
+}
+
+type simpleChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+// UnmarshalXML reads the choice content as text, LMS exports wrap it in XHTML like <p>
+func (c *simpleChoice) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "identifier" {
+			c.Identifier = attr.Value
+		}
+	}
+	text, err := readFlowText(d, nil)
+	c.Text = text
+	return err
+}
+
+type responseProcessing struct {
+	Template string `xml:"template,attr"`
+}
+
+// ItemIdentifier is the identifier of the question item, QTI identifiers cannot start with a digit
+func ItemIdentifier(question entity.Question) string {
+	return fmt.Sprintf("question-%d", question.Id)
+}
+
+// MarshalItem returns the assessmentItem document of a choice question
+func MarshalItem(question entity.Question) ([]byte, error) {
+	cardinality, maxChoices := "single", 1
+	switch question.GetType() {
+	case entity.QuestionTypeSingleChoice:
+	case entity.QuestionTypeMultipleChoice:
+		cardinality, maxChoices = "multiple", 0
+	default:
+		return nil, ErrUnsupportedQuestionType
+	}
+
+	item := assessmentItem{
+		Xmlns:      itemNamespace,
+		Identifier: ItemIdentifier(question),
+		Title:      ItemIdentifier(question),
+		ResponseDeclaration: responseDeclaration{
+			Identifier:      responseIdentifier,
+			Cardinality:     cardinality,
+			BaseType:        "identifier",
+			CorrectResponse: []string{},
+		},
+		OutcomeDeclaration: outcomeDeclaration{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+		ItemBody: itemBody{ChoiceInteraction: choiceInteraction{
+			ResponseIdentifier: responseIdentifier,
+			MaxChoices:         maxChoices,
+			Prompt:             flowText(question.Body),
+			SimpleChoices:      make([]simpleChoice, len(question.QuestionOptions)),
+		}},
+		ResponseProcessing: responseProcessing{Template: matchCorrectTemplate},
+	}
+	for i, questionOption := range question.QuestionOptions {
+		identifier := fmt.Sprintf("choice-%d", i)
+		item.ItemBody.ChoiceInteraction.SimpleChoices[i] = simpleChoice{Identifier: identifier, Text: questionOption.Body}
+		if questionOption.Correct != nil && *questionOption.Correct {
+			item.ResponseDeclaration.CorrectResponse = append(item.ResponseDeclaration.CorrectResponse, identifier)
+		}
+	}
+
+	data, err := xml.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// UnmarshalItem reads a choice question from an assessmentItem document
+func UnmarshalItem(data []byte) (entity.Question, error) {
+	var item assessmentItem
+	err := xml.Unmarshal(data, &item)
+	if err != nil {
+		return entity.Question{}, err
+	}
+	interaction := item.ItemBody.ChoiceInteraction
+	if len(interaction.SimpleChoices) == 0 {
+		return entity.Question{}, ErrUnsupportedQuestionType
+	}
+
+	question := entity.Question{
+		Type:            entity.QuestionTypeSingleChoice,
+		Body:            strings.TrimSpace(string(interaction.Prompt)),
+		QuestionOptions: make([]entity.QuestionOption, len(interaction.SimpleChoices)),
+	}
+	if item.ResponseDeclaration.Cardinality == "multiple" {
+		question.Type = entity.QuestionTypeMultipleChoice
+	}
+	// Items without prompt have the question in the item body
+	if question.Body == "" {
+		question.Body = strings.TrimSpace(item.ItemBody.Text)
+	}
+
+	correctIdentifiers := map[string]bool{}
+	for _, identifier := range item.ResponseDeclaration.CorrectResponse {
+		correctIdentifiers[strings.TrimSpace(identifier)] = true
+	}
+	for i, choice := range interaction.SimpleChoices {
+		correct := correctIdentifiers[choice.Identifier]
+		question.QuestionOptions[i] = entity.QuestionOption{Body: strings.TrimSpace(choice.Text), Correct: &correct}
+	}
+
+	return question, nil
+}
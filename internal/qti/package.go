@@ -0,0 +1,177 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"challenge/internal/entity"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	manifestName      = "imsmanifest.xml"
+	manifestNamespace = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	itemResourceType  = "imsqti_item_xmlv2p1"
+)
+
+type manifest struct {
+	XMLName    xml.Name   `xml:"manifest"`
+	Xmlns      string     `xml:"xmlns,attr,omitempty"`
+	Identifier string     `xml:"identifier,attr"`
+	Metadata   metadata   `xml:"metadata"`
+	Resources  []resource `xml:"resources>resource"`
+}
+
+type metadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type resource struct {
+	Identifier string `xml:"identifier,attr"`
+	Type       string `xml:"type,attr"`
+	Href       string `xml:"href,attr"`
+	Files      []file `xml:"file"`
+}
+
+type file struct {
+	Href string `xml:"href,attr"`
+}
+
+// PackageWriter writes a content package zip with an item per question, as questions are written.
+// The manifest is written on Close
+type PackageWriter struct {
+	zw        *zip.Writer
+	resources []resource
+}
+
+func NewPackageWriter(w io.Writer) *PackageWriter {
+	return &PackageWriter{zw: zip.NewWriter(w)}
+}
+
+// WriteQuestion adds the question item to the package. Questions QTI cannot represent as choices are skipped
+func (pw *PackageWriter) WriteQuestion(question entity.Question) error {
+	data, err := MarshalItem(question)
+	if errors.Is(err, ErrUnsupportedQuestionType) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	identifier := ItemIdentifier(question)
+	href := fmt.Sprintf("items/%s.xml", identifier)
+	itemWriter, err := pw.zw.Create(href)
+	if err != nil {
+		return err
+	}
+	_, err = itemWriter.Write(data)
+	if err != nil {
+		return err
+	}
+
+	pw.resources = append(pw.resources, resource{
+		Identifier: identifier,
+		Type:       itemResourceType,
+		Href:       href,
+		Files:      []file{{Href: href}},
+	})
+	return nil
+}
+
+// Close writes the manifest and finishes the zip, it does not close the underlying writer
+func (pw *PackageWriter) Close() error {
+	data, err := xml.MarshalIndent(manifest{
+		Xmlns:      manifestNamespace,
+		Identifier: "questions",
+		Metadata:   metadata{Schema: "QTIv2.1 Package", SchemaVersion: "1.0.0"},
+		Resources:  pw.resources,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	manifestWriter, err := pw.zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	_, err = manifestWriter.Write(append([]byte(xml.Header), data...))
+	if err != nil {
+		return err
+	}
+
+	return pw.zw.Close()
+}
+
+// ErrPackageTooLarge is returned when the files read from a package unpack to more than the size allowed
+var ErrPackageTooLarge = errors.New("QTI package is too large")
+
+// PackageItem is a question read from a package item, or the reason it could not be read
+type PackageItem struct {
+	Href     string
+	Question entity.Question
+	Err      error
+}
+
+// ReadPackage reads the items listed in the package manifest, in manifest order. The manifest and items read unpack
+// to maxSize bytes at most, so that small zip bombs cannot exhaust the memory
+func ReadPackage(data []byte, maxSize int64) ([]PackageItem, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := maxSize
+	manifestData, err := readZipFile(zr, manifestName, &remaining)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	err = xml.Unmarshal(manifestData, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []PackageItem{}
+	for _, r := range m.Resources {
+		// Only QTI 2.x items are read, other resources like images are referenced by the items
+		if !strings.HasPrefix(r.Type, "imsqti_item_xmlv2p") {
+			continue
+		}
+		item := PackageItem{Href: r.Href}
+		itemData, err := readZipFile(zr, path.Clean(r.Href), &remaining)
+		if errors.Is(err, ErrPackageTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			item.Err = err
+		} else {
+			item.Question, item.Err = UnmarshalItem(itemData)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// readZipFile reads a file of the zip and takes its size from remaining, the read stops past remaining bytes
+func readZipFile(zr *zip.Reader, name string, remaining *int64) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, *remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > *remaining {
+		return nil, ErrPackageTooLarge
+	}
+	*remaining -= int64(len(data))
+	return data, nil
+}
@@ -0,0 +1,198 @@
+package qti_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/qti"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChoiceQuestion(id uint, questionType string, correctFlags ...bool) entity.Question {
+	question := entity.Question{
+		Id:              id,
+		Type:            questionType,
+		Body:            "Which of these <tags> & entities?",
+		QuestionOptions: make([]entity.QuestionOption, len(correctFlags)),
+	}
+	for i := range correctFlags {
+		question.QuestionOptions[i] = entity.QuestionOption{Body: string(rune('a' + i)), Correct: &correctFlags[i]}
+	}
+	return question
+}
+
+func TestItem_RoundTrip(t *testing.T) {
+	type Test struct {
+		TestName string
+		Question entity.Question
+	}
+	tests := []Test{
+		{"SingleChoice", newChoiceQuestion(1, entity.QuestionTypeSingleChoice, false, true, false)},
+		{"MultipleChoice", newChoiceQuestion(2, entity.QuestionTypeMultipleChoice, true, true, false)},
+		{"MultipleChoiceWithoutCorrect", newChoiceQuestion(3, entity.QuestionTypeMultipleChoice, false, false)},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			data, err := qti.MarshalItem(test.Question)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1"`)
+			assert.Contains(t, string(data), "choiceInteraction")
+
+			question, err := qti.UnmarshalItem(data)
+			require.NoError(t, err)
+			expectedQuestion := test.Question
+			expectedQuestion.Id = 0
+			assert.Equal(t, expectedQuestion, question)
+		})
+	}
+}
+
+func TestMarshalItem_UnsupportedType(t *testing.T) {
+	numericAnswer := 1.0
+	_, err := qti.MarshalItem(entity.Question{Type: entity.QuestionTypeNumeric, NumericAnswer: &numericAnswer})
+	assert.ErrorIs(t, err, qti.ErrUnsupportedQuestionType)
+
+	_, err = qti.MarshalItem(entity.Question{Type: entity.QuestionTypeOrdering})
+	assert.ErrorIs(t, err, qti.ErrUnsupportedQuestionType)
+}
+
+func TestUnmarshalItem_ExternalItem(t *testing.T) {
+	question, err := qti.UnmarshalItem([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="choice" title="Unattended Luggage" adaptive="false" timeDependent="false">
+	<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+		<correctResponse>
+			<value>ChoiceA</value>
+		</correctResponse>
+	</responseDeclaration>
+	<itemBody>
+		<choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="1">
+			<prompt>What should you do with unattended luggage?</prompt>
+			<simpleChoice identifier="ChoiceA">Report it</simpleChoice>
+			<simpleChoice identifier="ChoiceB">Ignore it</simpleChoice>
+		</choiceInteraction>
+	</itemBody>
+	<responseProcessing template="http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"/>
+</assessmentItem>`))
+	require.NoError(t, err)
+	correct := true
+	incorrect := false
+	assert.Equal(t, entity.Question{
+		Type: entity.QuestionTypeSingleChoice,
+		Body: "What should you do with unattended luggage?",
+		QuestionOptions: []entity.QuestionOption{
+			{Body: "Report it", Correct: &correct},
+			{Body: "Ignore it", Correct: &incorrect},
+		},
+	}, question)
+
+	_, err = qti.UnmarshalItem([]byte(`<assessmentItem identifier="text"><itemBody><extendedTextInteraction/></itemBody></assessmentItem>`))
+	assert.ErrorIs(t, err, qti.ErrUnsupportedQuestionType)
+}
+
+func TestUnmarshalItem_XHTMLContent(t *testing.T) {
+	// Item exported by TAO, the content is wrapped in XHTML blocks
+	data, err := os.ReadFile("testdata/tao_choice_item.xml")
+	require.NoError(t, err)
+	question, err := qti.UnmarshalItem(data)
+	require.NoError(t, err)
+	correct := true
+	incorrect := false
+	assert.Equal(t, entity.Question{
+		Type: entity.QuestionTypeMultipleChoice,
+		Body: "Which statements about goroutines are true?\n\nSelect every correct answer.",
+		QuestionOptions: []entity.QuestionOption{
+			{Body: "They are multiplexed onto OS threads", Correct: &correct},
+			{Body: "Each one owns a 1 MB stack", Correct: &incorrect},
+			{Body: "They start with the go keyword", Correct: &correct},
+		},
+	}, question)
+
+	question, err = qti.UnmarshalItem([]byte(`<assessmentItem identifier="list"><itemBody><choiceInteraction maxChoices="1">
+		<prompt><p>Pick the <b>odd</b> one:</p><ul><li>map</li><li>slice</li></ul><pre>x :=  1
+y := 2</pre></prompt>
+		<simpleChoice identifier="a">map<br/>type</simpleChoice>
+		<simpleChoice identifier="b">  plain   text  </simpleChoice>
+	</choiceInteraction></itemBody></assessmentItem>`))
+	require.NoError(t, err)
+	assert.Equal(t, "Pick the odd one:\n\n- map\n- slice\n\nx :=  1\ny := 2", question.Body)
+	assert.Equal(t, "map\ntype", question.QuestionOptions[0].Body)
+	assert.Equal(t, "plain   text", question.QuestionOptions[1].Body)
+}
+
+func TestPackage_RoundTrip(t *testing.T) {
+	numericAnswer := 1.0
+	questions := []entity.Question{
+		newChoiceQuestion(1, entity.QuestionTypeSingleChoice, true, false),
+		{Id: 2, Type: entity.QuestionTypeNumeric, Body: "skipped", NumericAnswer: &numericAnswer},
+		newChoiceQuestion(3, entity.QuestionTypeMultipleChoice, true, true, false),
+	}
+
+	var buf bytes.Buffer
+	packageWriter := qti.NewPackageWriter(&buf)
+	for _, question := range questions {
+		require.NoError(t, packageWriter.WriteQuestion(question))
+	}
+	require.NoError(t, packageWriter.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"items/question-1.xml", "items/question-3.xml", "imsmanifest.xml"}, names)
+
+	items, err := qti.ReadPackage(buf.Bytes(), 1<<20)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	for i, expectedQuestion := range []entity.Question{questions[0], questions[2]} {
+		require.NoError(t, items[i].Err)
+		expectedQuestion.Id = 0
+		assert.Equal(t, expectedQuestion, items[i].Question)
+	}
+}
+
+func TestReadPackage_MissingItem(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifestWriter, err := zw.Create("imsmanifest.xml")
+	require.NoError(t, err)
+	_, err = manifestWriter.Write([]byte(`<manifest identifier="m"><resources>
+		<resource identifier="missing" type="imsqti_item_xmlv2p1" href="items/missing.xml"/>
+		<resource identifier="image" type="webcontent" href="images/a.png"/>
+	</resources></manifest>`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	items, err := qti.ReadPackage(buf.Bytes(), 1<<20)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Error(t, items[0].Err)
+
+	_, err = qti.ReadPackage([]byte("not a zip"), 1<<20)
+	assert.Error(t, err)
+}
+
+func TestReadPackage_TooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	packageWriter := qti.NewPackageWriter(&buf)
+	question := newChoiceQuestion(1, entity.QuestionTypeSingleChoice, true, false)
+	question.Body = strings.Repeat("a", 4096)
+	require.NoError(t, packageWriter.WriteQuestion(question))
+	require.NoError(t, packageWriter.Close())
+
+	// The item compresses well below the limit, but unpacks over it
+	require.Less(t, buf.Len(), 2048)
+	_, err := qti.ReadPackage(buf.Bytes(), 2048)
+	assert.ErrorIs(t, err, qti.ErrPackageTooLarge)
+
+	items, err := qti.ReadPackage(buf.Bytes(), 8192)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.NoError(t, items[0].Err)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+type AssessmentRepository interface {
+	gormprovider.Repository
+	ListAssessments(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.Assessment, error)
+	CreateAssessment(ctx context.Context, assessment *entity.Assessment) error
+	GetAssessment(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Assessment, error)
+	UpdateAssessment(ctx context.Context, id uint, assessment *entity.Assessment) error
+	DeleteAssessment(ctx context.Context, id uint) error
+}
+
+func NewAssessmentRepository(provider *gormprovider.SQLiteProvider) *assessmentRepository {
+	return &assessmentRepository{provider.NewRepository("assessments")}
+}
+
+type assessmentRepository struct {
+	gormprovider.Repository
+}
+
+func (r *assessmentRepository) ListAssessments(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.Assessment, error) {
+	if pageSize == 0 {
+		pageSize = 10
+	}
+
+	qry := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Order("id").Limit(int(pageSize))
+	if lastId != nil {
+		qry = qry.Where("id > ?", *lastId)
+	}
+
+	var assessments []entity.Assessment
+	err := qry.Find(&assessments).Error
+
+	return assessments, err
+}
+
+func (r *assessmentRepository) CreateAssessment(ctx context.Context, assessment *entity.Assessment) error {
+	return r.NewQuery(ctx).Omit(clause.Associations).Create(assessment).Error
+}
+
+func (r *assessmentRepository) GetAssessment(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Assessment, error) {
+	var assessment entity.Assessment
+	err := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Where("id", id).First(&assessment).Error
+	return assessment, err
+}
+
+func (r *assessmentRepository) UpdateAssessment(ctx context.Context, id uint, assessment *entity.Assessment) error {
+	return r.NewQuery(ctx).
+		Omit(clause.Associations).
+		Where("id", id).
+		Select("title", "time_limit_seconds", "passing_score", "partial_credit", "negative_marking").
+		Updates(assessment).Error
+}
+
+func (r *assessmentRepository) DeleteAssessment(ctx context.Context, id uint) error {
+	return r.NewQuery(ctx).Delete(&entity.Assessment{Id: id}).Error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "gorm.io/gorm"
+
+type AssessmentFilter struct {
+	AuthorId uint
+}
+
+func (f AssessmentFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.AuthorId != 0 {
+		db.Where("assessments.author_id", f.AuthorId)
+	}
+
+	return db
+}
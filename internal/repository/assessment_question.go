@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+)
+
+type AssessmentQuestionRepository interface {
+	gormprovider.Repository
+	BulkCreateAssessmentQuestions(ctx context.Context, assessmentId uint, assessmentQuestions []entity.AssessmentQuestion) error
+	BulkReplaceAssessmentQuestions(ctx context.Context, assessmentId uint, assessmentQuestions []entity.AssessmentQuestion) error
+}
+
+func NewAssessmentQuestionRepository(provider *gormprovider.SQLiteProvider) *assessmentQuestionRepository {
+	return &assessmentQuestionRepository{provider.NewRepository("assessment_questions")}
+}
+
+type assessmentQuestionRepository struct {
+	gormprovider.Repository
+}
+
+func (r *assessmentQuestionRepository) BulkCreateAssessmentQuestions(ctx context.Context, assessmentId uint, assessmentQuestions []entity.AssessmentQuestion) error {
+	for i := range assessmentQuestions {
+		assessmentQuestions[i].AssessmentId = assessmentId
+		assessmentQuestions[i].Position = uint(i)
+	}
+	return r.NewQuery(ctx).Create(&assessmentQuestions).Error
+}
+
+func (r *assessmentQuestionRepository) BulkReplaceAssessmentQuestions(ctx context.Context, assessmentId uint, assessmentQuestions []entity.AssessmentQuestion) error {
+	return r.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := r.NewQuery(txCtx).Where("assessment_id", assessmentId).Delete(&entity.AssessmentQuestion{}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range assessmentQuestions {
+			assessmentQuestions[i].Id = 0
+		}
+		return r.BulkCreateAssessmentQuestions(txCtx, assessmentId, assessmentQuestions)
+	})
+}
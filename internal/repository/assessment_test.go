@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssessmentRepository_GetAssessment(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1, Body: "first"})
+	addQuestion(t, sqlProvider, &entity.Question{Id: 2, Body: "second"})
+
+	assessmentRepo := repository.NewAssessmentRepository(sqlProvider)
+	assessmentQuestionRepo := repository.NewAssessmentQuestionRepository(sqlProvider)
+	assessment := entity.Assessment{Title: "assessment", TimeLimitSeconds: 600, PassingScore: 50}
+	require.NoError(t, assessmentRepo.CreateAssessment(context.Background(), &assessment))
+
+	points := 2.0
+	assessmentQuestions := []entity.AssessmentQuestion{
+		{QuestionId: 2, QuestionRevision: 1, Snapshot: entity.QuestionSnapshot{Id: 2, Body: "second"}, Points: &points},
+		{QuestionId: 1, QuestionRevision: 1, Snapshot: entity.QuestionSnapshot{Id: 1, Body: "first"}},
+	}
+	require.NoError(t, assessmentQuestionRepo.BulkCreateAssessmentQuestions(context.Background(), assessment.Id, assessmentQuestions))
+
+	assessment, err := assessmentRepo.GetAssessment(
+		context.Background(),
+		assessment.Id,
+		gormprovider.OrderedPreloadOption{Association: "AssessmentQuestions", Order: "position"},
+	)
+	require.NoError(t, err)
+	require.Len(t, assessment.AssessmentQuestions, 2)
+	assert.Equal(t, "second", assessment.AssessmentQuestions[0].Snapshot.Body)
+	assert.Equal(t, 2.0, assessment.AssessmentQuestions[0].GetPoints())
+	assert.Equal(t, "first", assessment.AssessmentQuestions[1].Snapshot.Body)
+	assert.Equal(t, float64(entity.DefaultAssessmentQuestionPoints), assessment.AssessmentQuestions[1].GetPoints())
+}
+
+func TestAssessmentRepository_ListAssessments(t *testing.T) {
+	var authorId uint = 1
+
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	repo := repository.NewAssessmentRepository(sqlProvider)
+	for _, assessment := range []entity.Assessment{
+		{Title: "first", AuthorId: authorId},
+		{Title: "second", AuthorId: 2},
+	} {
+		assessment := assessment
+		require.NoError(t, repo.CreateAssessment(context.Background(), &assessment))
+	}
+
+	assessments, err := repo.ListAssessments(context.Background(), 0, nil, repository.AssessmentFilter{AuthorId: authorId})
+	require.NoError(t, err)
+	require.Len(t, assessments, 1)
+	assert.Equal(t, "first", assessments[0].Title)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+	"time"
+)
+
+type AttachmentRepository interface {
+	gormprovider.Repository
+	CreateAttachment(ctx context.Context, attachment *entity.Attachment) error
+	GetAttachment(ctx context.Context, id uint) (entity.Attachment, error)
+	ListAttachments(ctx context.Context, ids []uint) ([]entity.Attachment, error)
+	ListAttachmentBlobKeys(ctx context.Context) ([]string, error)
+	ListOrphanedAttachments(ctx context.Context, createdBefore time.Time) ([]entity.Attachment, error)
+	IsAttachmentReferenced(ctx context.Context, id uint) (bool, error)
+	DeleteAttachment(ctx context.Context, id uint) error
+}
+
+func NewAttachmentRepository(provider *gormprovider.SQLiteProvider) *attachmentRepository {
+	return &attachmentRepository{provider.NewRepository("attachments")}
+}
+
+type attachmentRepository struct {
+	gormprovider.Repository
+}
+
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	return r.NewQuery(ctx).Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetAttachment(ctx context.Context, id uint) (entity.Attachment, error) {
+	var attachment entity.Attachment
+	err := r.NewQuery(ctx).Where("id", id).First(&attachment).Error
+	return attachment, err
+}
+
+func (r *attachmentRepository) ListAttachments(ctx context.Context, ids []uint) ([]entity.Attachment, error) {
+	attachments := []entity.Attachment{}
+	err := r.NewQuery(ctx).Where("id IN ?", ids).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) ListAttachmentBlobKeys(ctx context.Context) ([]string, error) {
+	blobKeys := []string{}
+	err := r.NewQuery(ctx).Pluck("blob_key", &blobKeys).Error
+	return blobKeys, err
+}
+
+// ListOrphanedAttachments returns the attachments created before the given time that no question,
+// option or assessment snapshot references
+func (r *attachmentRepository) ListOrphanedAttachments(ctx context.Context, createdBefore time.Time) ([]entity.Attachment, error) {
+	attachments := []entity.Attachment{}
+	err := r.NewQuery(ctx).
+		Where("created_at < ?", createdBefore).
+		Where("NOT EXISTS (" + attachmentReferencesSql + ")").
+		Order("id").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) IsAttachmentReferenced(ctx context.Context, id uint) (bool, error) {
+	var referenced bool
+	err := r.NewQuery(ctx).
+		Select("EXISTS ("+attachmentReferencesSql+")").
+		Where("id", id).
+		Scan(&referenced).Error
+	return referenced, err
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, id uint) error {
+	return r.NewQuery(ctx).Where("id", id).Delete(&entity.Attachment{}).Error
+}
+
+// attachmentReferencesSql selects the references to the attachment of the outer query.
+// Assessment snapshots keep their attachments alive after the question drops them
+const attachmentReferencesSql = `
+	SELECT 1 FROM questions WHERE questions.attachment_id = attachments.id
+	UNION ALL
+	SELECT 1 FROM question_options WHERE question_options.attachment_id = attachments.id
+	UNION ALL
+	SELECT 1 FROM assessment_questions WHERE json_extract(assessment_questions.snapshot, '$.attachmentId') = attachments.id
+	UNION ALL
+	SELECT 1 FROM assessment_questions, json_each(assessment_questions.snapshot, '$.options') AS snapshot_options
+		WHERE json_extract(snapshot_options.value, '$.attachmentId') = attachments.id`
@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentRepository_ListOrphanedAttachments(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	repo := repository.NewAttachmentRepository(sqlProvider)
+	now := time.Now().UTC().Truncate(time.Second)
+	attachments := make([]entity.Attachment, 6)
+	for i := range attachments {
+		attachments[i] = entity.Attachment{BlobKey: fmt.Sprintf("blob%d", i), CreatedAt: now.Add(-time.Hour)}
+		require.NoError(t, repo.CreateAttachment(context.Background(), &attachments[i]))
+	}
+	correct := true
+
+	// Referenced by a question, an option and the question and option of a snapshot
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1, AttachmentId: &attachments[0].Id})
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "option", Correct: &correct, QuestionId: 1, AttachmentId: &attachments[1].Id})
+	assessment := entity.Assessment{Title: "assessment"}
+	require.NoError(t, repository.NewAssessmentRepository(sqlProvider).CreateAssessment(context.Background(), &assessment))
+	err := repository.NewAssessmentQuestionRepository(sqlProvider).BulkCreateAssessmentQuestions(
+		context.Background(),
+		assessment.Id,
+		[]entity.AssessmentQuestion{{QuestionId: 1, Snapshot: entity.QuestionSnapshot{
+			Id:              1,
+			AttachmentId:    &attachments[2].Id,
+			QuestionOptions: []entity.QuestionOption{{Body: "option"}, {Body: "snapshot", AttachmentId: &attachments[3].Id}},
+		}}},
+	)
+	require.NoError(t, err)
+
+	orphanedAttachments, err := repo.ListOrphanedAttachments(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, orphanedAttachments, 2)
+	assert.Equal(t, attachments[4].Id, orphanedAttachments[0].Id)
+	assert.Equal(t, attachments[5].Id, orphanedAttachments[1].Id)
+
+	// Recent attachments may still be referenced by the upload that created them
+	orphanedAttachments, err = repo.ListOrphanedAttachments(context.Background(), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, orphanedAttachments)
+
+	for i, attachment := range attachments {
+		referenced, err := repo.IsAttachmentReferenced(context.Background(), attachment.Id)
+		require.NoError(t, err)
+		assert.Equal(t, i < 4, referenced, attachment.BlobKey)
+	}
+
+	blobKeys, err := repo.ListAttachmentBlobKeys(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, blobKeys, 6)
+}
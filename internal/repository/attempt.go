@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttemptRepository interface {
+	gormprovider.Repository
+	CreateAttempt(ctx context.Context, attempt *entity.Attempt) error
+	GetAttempt(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Attempt, error)
+	CountAssessmentAttempts(ctx context.Context, assessmentId uint) (int64, error)
+	FinishAttempt(ctx context.Context, id uint, attempt *entity.Attempt) (bool, error)
+	LockOpenAttempt(ctx context.Context, id uint) (bool, error)
+}
+
+func NewAttemptRepository(provider *gormprovider.SQLiteProvider) *attemptRepository {
+	return &attemptRepository{provider.NewRepository("attempts")}
+}
+
+type attemptRepository struct {
+	gormprovider.Repository
+}
+
+func (r *attemptRepository) CreateAttempt(ctx context.Context, attempt *entity.Attempt) error {
+	return r.NewQuery(ctx).Omit(clause.Associations).Create(attempt).Error
+}
+
+func (r *attemptRepository) GetAttempt(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Attempt, error) {
+	var attempt entity.Attempt
+	err := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Where("id", id).First(&attempt).Error
+	return attempt, err
+}
+
+func (r *attemptRepository) CountAssessmentAttempts(ctx context.Context, assessmentId uint) (int64, error) {
+	var count int64
+	err := r.NewQuery(ctx).Where("assessment_id", assessmentId).Count(&count).Error
+	return count, err
+}
+
+// FinishAttempt locks the attempt with its finish time and scores.
+// It returns false when the attempt was already finished
+func (r *attemptRepository) FinishAttempt(ctx context.Context, id uint, attempt *entity.Attempt) (bool, error) {
+	res := r.NewQuery(ctx).
+		Where("id", id).
+		Where("finished_at IS NULL").
+		Select("finished_at", "score", "max_score", "passed").
+		Updates(attempt)
+	return res.RowsAffected > 0, res.Error
+}
+
+// LockOpenAttempt takes the write lock of the attempt until the transaction in ctx ends, so that it cannot be finished
+// meanwhile. It returns false when the attempt is already finished
+func (r *attemptRepository) LockOpenAttempt(ctx context.Context, id uint) (bool, error) {
+	res := r.NewQuery(ctx).
+		Where("id", id).
+		Where("finished_at IS NULL").
+		Update("id", gorm.Expr("id"))
+	return res.RowsAffected > 0, res.Error
+}
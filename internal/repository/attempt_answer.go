@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+type AttemptAnswerRepository interface {
+	gormprovider.Repository
+	SaveAttemptAnswer(ctx context.Context, attemptAnswer *entity.AttemptAnswer) error
+	UpdateAttemptAnswerScore(ctx context.Context, id uint, score float64) error
+}
+
+func NewAttemptAnswerRepository(provider *gormprovider.SQLiteProvider) *attemptAnswerRepository {
+	return &attemptAnswerRepository{provider.NewRepository("attempt_answers")}
+}
+
+type attemptAnswerRepository struct {
+	gormprovider.Repository
+}
+
+// SaveAttemptAnswer creates the answer or replaces the previous answer to the same question
+func (r *attemptAnswerRepository) SaveAttemptAnswer(ctx context.Context, attemptAnswer *entity.AttemptAnswer) error {
+	return r.NewQuery(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "attempt_id"}, {Name: "assessment_question_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"selected_options", "numeric_value", "answered_at"}),
+		}).
+		Create(attemptAnswer).Error
+}
+
+func (r *attemptAnswerRepository) UpdateAttemptAnswerScore(ctx context.Context, id uint, score float64) error {
+	return r.NewQuery(ctx).Where("id", id).Update("score", score).Error
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addAttemptAssessment(t *testing.T, sqlProvider *gormprovider.SQLiteProvider) entity.Assessment {
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+	assessment := entity.Assessment{Title: "assessment"}
+	require.NoError(t, repository.NewAssessmentRepository(sqlProvider).CreateAssessment(context.Background(), &assessment))
+	assessment.AssessmentQuestions = []entity.AssessmentQuestion{{QuestionId: 1, Snapshot: entity.QuestionSnapshot{Id: 1}}}
+	err := repository.NewAssessmentQuestionRepository(sqlProvider).
+		BulkCreateAssessmentQuestions(context.Background(), assessment.Id, assessment.AssessmentQuestions)
+	require.NoError(t, err)
+	return assessment
+}
+
+func TestAttemptRepository_FinishAttempt(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	assessment := addAttemptAssessment(t, sqlProvider)
+
+	repo := repository.NewAttemptRepository(sqlProvider)
+	attempt := entity.Attempt{StartedAt: time.Now(), CandidateId: 1, AssessmentId: assessment.Id}
+	require.NoError(t, repo.CreateAttempt(context.Background(), &attempt))
+
+	open, err := repo.LockOpenAttempt(context.Background(), attempt.Id)
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	finishedAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	score, maxScore, passed := 1.5, 2.0, true
+	finished, err := repo.FinishAttempt(context.Background(), attempt.Id, &entity.Attempt{FinishedAt: &finishedAt, Score: &score, MaxScore: &maxScore, Passed: &passed})
+	require.NoError(t, err)
+	assert.True(t, finished)
+	laterFinishedAt := finishedAt.Add(time.Minute)
+	finished, err = repo.FinishAttempt(context.Background(), attempt.Id, &entity.Attempt{FinishedAt: &laterFinishedAt})
+	require.NoError(t, err)
+	assert.False(t, finished)
+	open, err = repo.LockOpenAttempt(context.Background(), attempt.Id)
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	attempt, err = repo.GetAttempt(context.Background(), attempt.Id)
+	require.NoError(t, err)
+	require.NotNil(t, attempt.FinishedAt)
+	assert.True(t, finishedAt.Equal(*attempt.FinishedAt))
+	require.NotNil(t, attempt.Score)
+	assert.Equal(t, score, *attempt.Score)
+	require.NotNil(t, attempt.MaxScore)
+	assert.Equal(t, maxScore, *attempt.MaxScore)
+	require.NotNil(t, attempt.Passed)
+	assert.True(t, *attempt.Passed)
+
+	count, err := repo.CountAssessmentAttempts(context.Background(), assessment.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAttemptAnswerRepository_SaveAttemptAnswer(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	assessment := addAttemptAssessment(t, sqlProvider)
+
+	attemptRepo := repository.NewAttemptRepository(sqlProvider)
+	attempt := entity.Attempt{StartedAt: time.Now(), CandidateId: 1, AssessmentId: assessment.Id}
+	require.NoError(t, attemptRepo.CreateAttempt(context.Background(), &attempt))
+
+	repo := repository.NewAttemptAnswerRepository(sqlProvider)
+	for _, selectedOptions := range []entity.OptionPositions{{0}, {1, 2}} {
+		attemptAnswer := entity.AttemptAnswer{
+			SelectedOptions:      selectedOptions,
+			AnsweredAt:           time.Now(),
+			AttemptId:            attempt.Id,
+			AssessmentQuestionId: assessment.AssessmentQuestions[0].Id,
+		}
+		require.NoError(t, repo.SaveAttemptAnswer(context.Background(), &attemptAnswer))
+	}
+
+	attempt, err := attemptRepo.GetAttempt(context.Background(), attempt.Id, gormprovider.PreloadOption("AttemptAnswers"))
+	require.NoError(t, err)
+	require.Len(t, attempt.AttemptAnswers, 1)
+	assert.Equal(t, entity.OptionPositions{1, 2}, attempt.AttemptAnswers[0].SelectedOptions)
+
+	require.NoError(t, repo.UpdateAttemptAnswerScore(context.Background(), attempt.AttemptAnswers[0].Id, 0.5))
+	attempt, err = attemptRepo.GetAttempt(context.Background(), attempt.Id, gormprovider.PreloadOption("AttemptAnswers"))
+	require.NoError(t, err)
+	require.NotNil(t, attempt.AttemptAnswers[0].Score)
+	assert.Equal(t, 0.5, *attempt.AttemptAnswers[0].Score)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+)
+
+type AuditEntryRepository interface {
+	gormprovider.Repository
+	CreateAuditEntry(ctx context.Context, auditEntry *entity.AuditEntry) error
+	ListAuditEntries(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.AuditEntry, error)
+}
+
+func NewAuditEntryRepository(provider *gormprovider.SQLiteProvider) *auditEntryRepository {
+	return &auditEntryRepository{provider.NewRepository("audit_entries")}
+}
+
+type auditEntryRepository struct {
+	gormprovider.Repository
+}
+
+func (r *auditEntryRepository) CreateAuditEntry(ctx context.Context, auditEntry *entity.AuditEntry) error {
+	return r.NewQuery(ctx).Create(auditEntry).Error
+}
+
+func (r *auditEntryRepository) ListAuditEntries(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.AuditEntry, error) {
+	if pageSize == 0 {
+		pageSize = 10
+	}
+
+	qry := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Order("audit_entries.id").Limit(int(pageSize))
+	if lastId != nil {
+		qry = qry.Where("audit_entries.id > ?", *lastId)
+	}
+
+	auditEntries := []entity.AuditEntry{}
+	err := qry.Find(&auditEntries).Error
+	return auditEntries, err
+}
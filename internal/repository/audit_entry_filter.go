@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditEntryFilter struct {
+	ActorId    uint
+	EntityType string
+	EntityId   uint
+	Since      *time.Time // Inclusive
+	Until      *time.Time // Exclusive
+}
+
+func (f AuditEntryFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.ActorId != 0 {
+		db.Where("audit_entries.actor_id", f.ActorId)
+	}
+	if f.EntityType != "" {
+		db.Where("audit_entries.entity_type", f.EntityType)
+	}
+	if f.EntityId != 0 {
+		db.Where("audit_entries.entity_id", f.EntityId)
+	}
+	if f.Since != nil {
+		db.Where("audit_entries.created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		db.Where("audit_entries.created_at < ?", *f.Until)
+	}
+
+	return db
+}
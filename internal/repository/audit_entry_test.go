@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEntryRepository_ListAuditEntries(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+
+	repo := repository.NewAuditEntryRepository(sqlProvider)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	create := func(actorId uint, entityId uint, createdAt time.Time) entity.AuditEntry {
+		auditEntry := entity.AuditEntry{
+			ActorId:    actorId,
+			Action:     entity.AuditActionUpdate,
+			EntityType: entity.AuditEntityQuestion,
+			EntityId:   entityId,
+			Before:     json.RawMessage(`{"body":"before"}`),
+			After:      json.RawMessage(`{"body":"after"}`),
+			CreatedAt:  createdAt,
+		}
+		require.NoError(t, repo.CreateAuditEntry(ctx, &auditEntry))
+		return auditEntry
+	}
+	create(1, 1, now.Add(-time.Hour))
+	second := create(2, 1, now)
+	create(1, 2, now)
+
+	auditEntries, err := repo.ListAuditEntries(ctx, 0, nil, repository.AuditEntryFilter{EntityType: entity.AuditEntityQuestion, EntityId: 1, Since: &now})
+	require.NoError(t, err)
+	require.Len(t, auditEntries, 1)
+	assert.Equal(t, second.Id, auditEntries[0].Id)
+	assert.JSONEq(t, `{"body":"before"}`, string(auditEntries[0].Before))
+	auditEntries, err = repo.ListAuditEntries(ctx, 0, nil, repository.AuditEntryFilter{ActorId: 1, Until: &now})
+	require.NoError(t, err)
+	assert.Len(t, auditEntries, 1)
+
+	// Entries are append-only
+	err = sqlProvider.DB.Table("audit_entries").Where("id", second.Id).Update("actor_id", 3).Error
+	assert.Error(t, err)
+	err = sqlProvider.DB.Exec("DELETE FROM audit_entries").Error
+	assert.Error(t, err)
+	auditEntries, err = repo.ListAuditEntries(ctx, 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, auditEntries, 3)
+}
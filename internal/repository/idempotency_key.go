@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyKeyRepository interface {
+	gormprovider.Repository
+	GetIdempotencyKey(ctx context.Context, userId uint, key string, now time.Time) (entity.IdempotencyKey, error)
+	SaveIdempotencyKey(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error)
+}
+
+func NewIdempotencyKeyRepository(provider *gormprovider.SQLiteProvider) *idempotencyKeyRepository {
+	return &idempotencyKeyRepository{provider.NewRepository("idempotency_keys")}
+}
+
+type idempotencyKeyRepository struct {
+	gormprovider.Repository
+}
+
+// GetIdempotencyKey returns the user key unless it has expired
+func (r *idempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, userId uint, key string, now time.Time) (entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+	err := r.NewQuery(ctx).
+		Where("user_id", userId).
+		Where("key", key).
+		Where("expires_at > ?", now).
+		First(&idempotencyKey).Error
+	return idempotencyKey, err
+}
+
+// SaveIdempotencyKey removes the expired keys and stores the new one.
+// It returns false when the user already has an unexpired key with the same value
+func (r *idempotencyKeyRepository) SaveIdempotencyKey(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error) {
+	err := r.NewQuery(ctx).Where("expires_at <= ?", idempotencyKey.CreatedAt).Delete(&entity.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+	res := r.NewQuery(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(idempotencyKey)
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeyRepository_SaveIdempotencyKey(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+
+	repo := repository.NewIdempotencyKeyRepository(sqlProvider)
+	now := time.Now().UTC().Truncate(time.Second)
+	newKey := func(userId uint, requestHash string, createdAt time.Time) *entity.IdempotencyKey {
+		return &entity.IdempotencyKey{
+			UserId:       userId,
+			Key:          "key",
+			RequestHash:  requestHash,
+			StatusCode:   200,
+			ResponseBody: []byte(`{"id":1}`),
+			CreatedAt:    createdAt,
+			ExpiresAt:    createdAt.Add(time.Hour),
+		}
+	}
+
+	saved, err := repo.SaveIdempotencyKey(context.Background(), newKey(1, "first", now))
+	require.NoError(t, err)
+	assert.True(t, saved)
+	saved, err = repo.SaveIdempotencyKey(context.Background(), newKey(1, "second", now))
+	require.NoError(t, err)
+	assert.False(t, saved)
+	saved, err = repo.SaveIdempotencyKey(context.Background(), newKey(2, "second", now))
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	idempotencyKey, err := repo.GetIdempotencyKey(context.Background(), 1, "key", now)
+	require.NoError(t, err)
+	assert.Equal(t, "first", idempotencyKey.RequestHash)
+	assert.Equal(t, []byte(`{"id":1}`), idempotencyKey.ResponseBody)
+
+	// Expired keys are not returned and can be reused
+	later := now.Add(2 * time.Hour)
+	_, err = repo.GetIdempotencyKey(context.Background(), 1, "key", later)
+	assert.Error(t, err)
+	saved, err = repo.SaveIdempotencyKey(context.Background(), newKey(1, "third", later))
+	require.NoError(t, err)
+	assert.True(t, saved)
+	idempotencyKey, err = repo.GetIdempotencyKey(context.Background(), 1, "key", later)
+	require.NoError(t, err)
+	assert.Equal(t, "third", idempotencyKey.RequestHash)
+}
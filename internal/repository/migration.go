@@ -0,0 +1,51 @@
+package repository
+
+import "challenge/pkg/gormprovider"
+
+// addedColumns are the columns added to tables after they were first created, in the order they were added.
+// database_init.sql creates the tables with them, existing databases get them from Migrate
+var addedColumns = []gormprovider.Column{
+	// Question revisions, snapshotted by assessments
+	{Table: "questions", Name: "revision", Definition: "INTEGER NOT NULL DEFAULT 1"},
+	// Question filters of the generation rules
+	{Table: "questions", Name: "difficulty", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "questions", Name: "duration_seconds", Definition: "INTEGER NOT NULL DEFAULT 0"},
+	// Question types, assessment scoring and attempt scores
+	{Table: "questions", Name: "type", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "questions", Name: "numeric_answer", Definition: "REAL"},
+	{Table: "questions", Name: "numeric_tolerance", Definition: "REAL NOT NULL DEFAULT 0"},
+	{Table: "assessments", Name: "partial_credit", Definition: "INTEGER NOT NULL DEFAULT 0"},
+	{Table: "assessments", Name: "negative_marking", Definition: "INTEGER NOT NULL DEFAULT 0"},
+	{Table: "attempts", Name: "score", Definition: "REAL"},
+	{Table: "attempts", Name: "max_score", Definition: "REAL"},
+	{Table: "attempts", Name: "passed", Definition: "INTEGER"},
+	{Table: "attempt_answers", Name: "numeric_value", Definition: "REAL"},
+	{Table: "attempt_answers", Name: "score", Definition: "REAL"},
+	// Seed of the option order shown to the candidate
+	{Table: "attempts", Name: "option_seed", Definition: "INTEGER NOT NULL DEFAULT 0"},
+	// Option order
+	{Table: "question_options", Name: "position", Definition: "INTEGER NOT NULL DEFAULT 0"},
+	// Body formats
+	{Table: "questions", Name: "body_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_options", Name: "body_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+	// Attachments, a column added with a foreign key must default to null
+	{Table: "questions", Name: "attachment_id", Definition: "INTEGER REFERENCES attachments(id)"},
+	{Table: "question_options", Name: "attachment_id", Definition: "INTEGER REFERENCES attachments(id)"},
+	// Question languages
+	{Table: "questions", Name: "language", Definition: "TEXT NOT NULL DEFAULT ''"},
+	// Explanations and option feedback
+	{Table: "questions", Name: "explanation", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "questions", Name: "explanation_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_options", Name: "feedback", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_options", Name: "feedback_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_translations", Name: "explanation", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_translations", Name: "explanation_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_option_translations", Name: "feedback", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "question_option_translations", Name: "feedback_format", Definition: "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Migrate adds the columns missing from the tables of databases created by an older database_init.sql.
+// It runs after database_init.sql, which creates the missing tables
+func Migrate(provider *gormprovider.SQLiteProvider) error {
+	return provider.AddMissingColumns(addedColumns)
+}
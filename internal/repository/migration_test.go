@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLegacySQLiteProvider returns a database created by the first database_init.sql, with a question, once
+// the current database_init.sql ran on it
+func newLegacySQLiteProvider(t *testing.T) *gormprovider.SQLiteProvider {
+	legacySchema, err := os.ReadFile("testdata/legacy_schema.sql")
+	require.NoError(t, err)
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, string(legacySchema))
+	require.NoError(t, sqlProvider.Exec("INSERT INTO questions (id, body, author_id) VALUES (1, 'legacy', 1)").Error)
+	require.NoError(t, sqlProvider.Exec(getDatabaseInitSql(t)).Error)
+	return sqlProvider
+}
+
+func TestMigrate(t *testing.T) {
+	sqlProvider := newLegacySQLiteProvider(t)
+
+	// Migrate runs on every start
+	require.NoError(t, repository.Migrate(sqlProvider))
+	require.NoError(t, repository.Migrate(sqlProvider))
+
+	question, err := repository.NewQuestionRepository(sqlProvider).GetQuestion(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Question{Id: 1, Body: "legacy", AuthorId: 1, Revision: 1}, question)
+}
+
+// tableColumns returns the column names of every table
+func tableColumns(t *testing.T, sqlProvider *gormprovider.SQLiteProvider) map[string][]string {
+	var tables []string
+	err := sqlProvider.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables).Error
+	require.NoError(t, err)
+	columns := map[string][]string{}
+	for _, table := range tables {
+		var names []string
+		require.NoError(t, sqlProvider.Raw("SELECT name FROM pragma_table_info(?) ORDER BY name", table).Scan(&names).Error)
+		columns[table] = names
+	}
+	return columns
+}
+
+func TestMigrate_MatchesDatabaseInit(t *testing.T) {
+	sqlProvider := newLegacySQLiteProvider(t)
+	require.NoError(t, repository.Migrate(sqlProvider))
+
+	// Every column database_init.sql adds to a table that existed before is migrated
+	newSqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	assert.Equal(t, tableColumns(t, newSqlProvider), tableColumns(t, sqlProvider))
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+type OutboxRepository interface {
+	gormprovider.Repository
+	CreateOutboxEvent(ctx context.Context, event entity.WebhookEvent) error
+	LeaseOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, outboxEvent entity.OutboxEvent, now time.Time) error
+	ReleaseOutboxEvent(ctx context.Context, outboxEvent entity.OutboxEvent) error
+}
+
+func NewOutboxRepository(provider *gormprovider.SQLiteProvider) *outboxRepository {
+	return &outboxRepository{provider.NewRepository("outbox")}
+}
+
+type outboxRepository struct {
+	gormprovider.Repository
+}
+
+// CreateOutboxEvent writes the event in the transaction of the context, if any
+func (r *outboxRepository) CreateOutboxEvent(ctx context.Context, event entity.WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.NewQuery(ctx).Create(&entity.OutboxEvent{
+		EventId:     event.Id,
+		EventType:   event.Type,
+		Payload:     string(payload),
+		AvailableAt: event.CreatedAt,
+		CreatedAt:   event.CreatedAt,
+	}).Error
+}
+
+// LeaseOutboxEvents leases the unpublished events available at the given time, oldest first.
+// Events leased by another dispatch are skipped until their lease expires, so a crashed dispatch
+// only delays its events
+func (r *outboxRepository) LeaseOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.OutboxEvent, error) {
+	// The lease is taken with a single statement so concurrent dispatches can't take the same events
+	leaseToken := ulid.Make().String()
+	err := r.NewQuery(ctx).Exec(`
+		UPDATE outbox SET lease_token = ?, leased_until = ?
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND available_at <= ? AND (leased_until IS NULL OR leased_until <= ?)
+			ORDER BY id LIMIT ?
+		)`,
+		leaseToken, now.Add(lease), now, now, limit,
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	outboxEvents := []entity.OutboxEvent{}
+	err = r.NewQuery(ctx).Where("lease_token", leaseToken).Order("id").Find(&outboxEvents).Error
+	return outboxEvents, err
+}
+
+// MarkOutboxEventPublished records the publication of a leased event, unless its lease was taken over
+func (r *outboxRepository) MarkOutboxEventPublished(ctx context.Context, outboxEvent entity.OutboxEvent, now time.Time) error {
+	return r.NewQuery(ctx).
+		Where("id", outboxEvent.Id).
+		Where("lease_token", outboxEvent.LeaseToken).
+		Updates(map[string]any{
+			"attempts":     outboxEvent.Attempts,
+			"published_at": now,
+			"lease_token":  nil,
+			"leased_until": nil,
+			"last_error":   "",
+		}).Error
+}
+
+// ReleaseOutboxEvent records the failed publication of a leased event, it is leased again once available
+func (r *outboxRepository) ReleaseOutboxEvent(ctx context.Context, outboxEvent entity.OutboxEvent) error {
+	return r.NewQuery(ctx).
+		Where("id", outboxEvent.Id).
+		Where("lease_token", outboxEvent.LeaseToken).
+		Updates(map[string]any{
+			"attempts":     outboxEvent.Attempts,
+			"available_at": outboxEvent.AvailableAt,
+			"lease_token":  nil,
+			"leased_until": nil,
+			"last_error":   outboxEvent.LastError,
+		}).Error
+}
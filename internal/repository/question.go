@@ -5,6 +5,7 @@ import (
 	"challenge/pkg/gormprovider"
 	"context"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -12,7 +13,7 @@ type QuestionRepository interface {
 	gormprovider.Repository
 	ListQuestions(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.Question, error)
 	CreateQuestion(ctx context.Context, question *entity.Question) error
-	GetQuestion(ctx context.Context, id uint) (entity.Question, error)
+	GetQuestion(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Question, error)
 	UpdateQuestion(ctx context.Context, id uint, question *entity.Question) error
 	DeleteQuestion(ctx context.Context, id uint) error
 }
@@ -30,9 +31,9 @@ func (r *questionRepository) ListQuestions(ctx context.Context, pageSize uint, l
 		pageSize = 10
 	}
 
-	qry := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Limit(int(pageSize))
+	qry := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Order("questions.id").Limit(int(pageSize))
 	if lastId != nil {
-		qry = qry.Where("id > ?", *lastId)
+		qry = qry.Where("questions.id > ?", *lastId)
 	}
 
 	var questions []entity.Question
@@ -41,18 +42,31 @@ func (r *questionRepository) ListQuestions(ctx context.Context, pageSize uint, l
 	return questions, err
 }
 
-func (r *questionRepository) GetQuestion(ctx context.Context, id uint) (entity.Question, error) {
+func (r *questionRepository) GetQuestion(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Question, error) {
 	var question entity.Question
-	err := r.NewQuery(ctx).Where("id", id).First(&question).Error
+	err := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Where("id", id).First(&question).Error
 	return question, err
 }
 
 func (r *questionRepository) CreateQuestion(ctx context.Context, question *entity.Question) error {
+	question.Revision = 1
 	return r.NewQuery(ctx).Omit(clause.Associations).Create(question).Error
 }
 
+// UpdateQuestion updates the question and bumps its revision
 func (r *questionRepository) UpdateQuestion(ctx context.Context, id uint, question *entity.Question) error {
-	return r.NewQuery(ctx).Omit(clause.Associations).Where("id", id).Updates(&question).Error
+	return r.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := r.NewQuery(txCtx).
+			Select("*").
+			Omit(clause.Associations, "id", "author_id", "revision").
+			Where("id", id).
+			Updates(&question).Error
+		if err != nil {
+			return err
+		}
+
+		return r.NewQuery(txCtx).Where("id", id).UpdateColumn("revision", gorm.Expr("revision + 1")).Error
+	})
 }
 
 func (r *questionRepository) DeleteQuestion(ctx context.Context, id uint) error {
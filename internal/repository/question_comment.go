@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+)
+
+type QuestionCommentRepository interface {
+	gormprovider.Repository
+	ListQuestionComments(ctx context.Context, questionId uint) ([]entity.QuestionComment, error)
+	CreateQuestionComment(ctx context.Context, questionComment *entity.QuestionComment) error
+	GetQuestionComment(ctx context.Context, questionId uint, id uint) (entity.QuestionComment, error)
+	UpdateQuestionComment(ctx context.Context, id uint, questionComment *entity.QuestionComment) error
+	DeleteQuestionComment(ctx context.Context, id uint) error
+}
+
+func NewQuestionCommentRepository(provider *gormprovider.SQLiteProvider) *questionCommentRepository {
+	return &questionCommentRepository{provider.NewRepository("question_comments")}
+}
+
+type questionCommentRepository struct {
+	gormprovider.Repository
+}
+
+func (r *questionCommentRepository) ListQuestionComments(ctx context.Context, questionId uint) ([]entity.QuestionComment, error) {
+	var questionComments []entity.QuestionComment
+	err := r.NewQuery(ctx).Where("question_id", questionId).Order("id").Find(&questionComments).Error
+	return questionComments, err
+}
+
+func (r *questionCommentRepository) CreateQuestionComment(ctx context.Context, questionComment *entity.QuestionComment) error {
+	return r.NewQuery(ctx).Create(questionComment).Error
+}
+
+func (r *questionCommentRepository) GetQuestionComment(ctx context.Context, questionId uint, id uint) (entity.QuestionComment, error) {
+	var questionComment entity.QuestionComment
+	err := r.NewQuery(ctx).Where("question_id", questionId).Where("id", id).First(&questionComment).Error
+	return questionComment, err
+}
+
+func (r *questionCommentRepository) UpdateQuestionComment(ctx context.Context, id uint, questionComment *entity.QuestionComment) error {
+	return r.NewQuery(ctx).
+		Where("id", id).
+		Select("body", "resolved", "updated_at").
+		Updates(questionComment).Error
+}
+
+func (r *questionCommentRepository) DeleteQuestionComment(ctx context.Context, id uint) error {
+	return r.NewQuery(ctx).Delete(&entity.QuestionComment{Id: id}).Error
+}
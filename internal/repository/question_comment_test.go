@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionCommentRepository_ListQuestionComments(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+	addQuestion(t, sqlProvider, &entity.Question{Id: 2})
+
+	repo := repository.NewQuestionCommentRepository(sqlProvider)
+	var parentId uint = 1
+	for _, questionComment := range []entity.QuestionComment{
+		{Body: "thread", QuestionId: 1},
+		{Body: "other question", QuestionId: 2},
+		{Body: "reply", QuestionId: 1, ParentId: &parentId},
+	} {
+		questionComment := questionComment
+		require.NoError(t, repo.CreateQuestionComment(context.Background(), &questionComment))
+	}
+
+	questionComments, err := repo.ListQuestionComments(context.Background(), 1)
+	require.NoError(t, err)
+	bodies := make([]string, len(questionComments))
+	for i, questionComment := range questionComments {
+		bodies[i] = questionComment.Body
+	}
+	assert.Equal(t, []string{"thread", "reply"}, bodies)
+}
+
+func TestQuestionCommentRepository_UpdateQuestionComment(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+
+	repo := repository.NewQuestionCommentRepository(sqlProvider)
+	questionComment := entity.QuestionComment{Body: "comment", QuestionId: 1}
+	require.NoError(t, repo.CreateQuestionComment(context.Background(), &questionComment))
+
+	questionComment.Resolved = true
+	require.NoError(t, repo.UpdateQuestionComment(context.Background(), questionComment.Id, &questionComment))
+
+	questionComment, err := repo.GetQuestionComment(context.Background(), 1, questionComment.Id)
+	require.NoError(t, err)
+	assert.True(t, questionComment.Resolved)
+}
+
+func TestQuestionCommentRepository_DeleteQuestionComment(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+
+	repo := repository.NewQuestionCommentRepository(sqlProvider)
+	thread := entity.QuestionComment{Body: "thread", QuestionId: 1}
+	require.NoError(t, repo.CreateQuestionComment(context.Background(), &thread))
+	reply := entity.QuestionComment{Body: "reply", QuestionId: 1, ParentId: &thread.Id}
+	require.NoError(t, repo.CreateQuestionComment(context.Background(), &reply))
+
+	require.NoError(t, repo.DeleteQuestionComment(context.Background(), thread.Id))
+
+	questionComments, err := repo.ListQuestionComments(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, questionComments)
+}
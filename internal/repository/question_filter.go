@@ -3,13 +3,25 @@ package repository
 import "gorm.io/gorm"
 
 type QuestionFilter struct {
-	AuthorId uint
+	AuthorId   uint
+	Difficulty string
+	Tags       []string // Questions with any of the tags
+	ExcludeIds []uint
 }
 
 func (f QuestionFilter) Apply(db *gorm.DB) *gorm.DB {
 	if f.AuthorId != 0 {
 		db.Where("questions.author_id", f.AuthorId)
 	}
+	if f.Difficulty != "" {
+		db.Where("questions.difficulty", f.Difficulty)
+	}
+	if len(f.Tags) > 0 {
+		db.Where("EXISTS (SELECT 1 FROM question_tags WHERE question_tags.question_id = questions.id AND question_tags.name IN ?)", f.Tags)
+	}
+	if len(f.ExcludeIds) > 0 {
+		db.Where("questions.id NOT IN ?", f.ExcludeIds)
+	}
 
 	return db
 }
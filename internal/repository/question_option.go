@@ -4,12 +4,16 @@ import (
 	"challenge/internal/entity"
 	"challenge/pkg/gormprovider"
 	"context"
+	"fmt"
+
+	"gorm.io/gorm"
 )
 
 type QuestionOptionRepository interface {
 	gormprovider.Repository
 	BulkCreateQuestionOptions(ctx context.Context, questionId uint, questionOptions []entity.QuestionOption) error
 	BulkReplaceQuestionOptions(ctx context.Context, questionId uint, questionOptions []entity.QuestionOption) error
+	ListQuestionOptions(ctx context.Context, questionIds []uint) ([]entity.QuestionOption, error)
 }
 
 func NewQuestionOptionRepository(provider *gormprovider.SQLiteProvider) *questionOptionRepository {
@@ -21,19 +25,72 @@ type questionOptionRepository struct {
 }
 
 func (r *questionOptionRepository) BulkCreateQuestionOptions(ctx context.Context, questionId uint, questionOptions []entity.QuestionOption) error {
+	// Numeric questions have no options
+	if len(questionOptions) == 0 {
+		return nil
+	}
 	for i := range questionOptions {
 		questionOptions[i].QuestionId = questionId
+		questionOptions[i].Position = uint(i)
 	}
 	return r.NewQuery(ctx).Create(&questionOptions).Error
 }
 
+// BulkReplaceQuestionOptions makes the question options match the given ones, keeping their identity:
+// options with the id of an existing option update it, options without id are created
+// and the existing options that are not given are deleted
 func (r *questionOptionRepository) BulkReplaceQuestionOptions(ctx context.Context, questionId uint, questionOptions []entity.QuestionOption) error {
 	return r.RunInTransaction(ctx, func(txCtx context.Context) error {
-		err := r.NewQuery(ctx).Where("question_id", questionId).Delete(&entity.QuestionOption{}).Error
+		keptIds := []uint{}
+		for _, questionOption := range questionOptions {
+			if questionOption.Id != 0 {
+				keptIds = append(keptIds, questionOption.Id)
+			}
+		}
+		qry := r.NewQuery(txCtx).Where("question_id", questionId)
+		if len(keptIds) > 0 {
+			qry = qry.Where("id NOT IN ?", keptIds)
+		}
+		err := qry.Delete(&entity.QuestionOption{}).Error
 		if err != nil {
 			return err
 		}
 
-		return r.BulkCreateQuestionOptions(ctx, questionId, questionOptions)
+		for i := range questionOptions {
+			questionOption := &questionOptions[i]
+			questionOption.QuestionId = questionId
+			questionOption.Position = uint(i)
+			if questionOption.Id == 0 {
+				err = r.NewQuery(txCtx).Create(questionOption).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			res := r.NewQuery(txCtx).
+				Where("id", questionOption.Id).
+				Where("question_id", questionId).
+				Select("body", "body_format", "feedback", "feedback_format", "correct", "position", "attachment_id").
+				Updates(questionOption)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("question option %d: %w", questionOption.Id, gorm.ErrRecordNotFound)
+			}
+		}
+		return nil
 	})
 }
+
+// ListQuestionOptions returns the options of all the questions at once, in question and position order
+func (r *questionOptionRepository) ListQuestionOptions(ctx context.Context, questionIds []uint) ([]entity.QuestionOption, error) {
+	var questionOptions []entity.QuestionOption
+	err := r.NewQuery(ctx).
+		Where("question_id IN ?", questionIds).
+		Order("question_id").
+		Order("position").
+		Find(&questionOptions).Error
+	return questionOptions, err
+}
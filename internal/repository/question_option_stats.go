@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuestionOptionStatsRepository interface {
+	gormprovider.Repository
+	IncrementQuestionOptionStats(ctx context.Context, questionOptionStats []entity.QuestionOptionStats) error
+	ListQuestionOptionStats(ctx context.Context, questionId uint, questionRevision uint) ([]entity.QuestionOptionStats, error)
+}
+
+func NewQuestionOptionStatsRepository(provider *gormprovider.SQLiteProvider) *questionOptionStatsRepository {
+	return &questionOptionStatsRepository{provider.NewRepository("question_option_stats")}
+}
+
+type questionOptionStatsRepository struct {
+	gormprovider.Repository
+}
+
+// IncrementQuestionOptionStats adds the sums to the stored ones, creating them on the first response
+func (r *questionOptionStatsRepository) IncrementQuestionOptionStats(ctx context.Context, questionOptionStats []entity.QuestionOptionStats) error {
+	if len(questionOptionStats) == 0 {
+		return nil
+	}
+	return r.NewQuery(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "question_id"}, {Name: "question_revision"}, {Name: "position"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"selections": gorm.Expr("selections + excluded.selections"),
+				"total_sum":  gorm.Expr("total_sum + excluded.total_sum"),
+			}),
+		}).
+		Create(&questionOptionStats).Error
+}
+
+func (r *questionOptionStatsRepository) ListQuestionOptionStats(ctx context.Context, questionId uint, questionRevision uint) ([]entity.QuestionOptionStats, error) {
+	var questionOptionStats []entity.QuestionOptionStats
+	err := r.NewQuery(ctx).
+		Where("question_id", questionId).
+		Where("question_revision", questionRevision).
+		Order("position").
+		Find(&questionOptionStats).Error
+	return questionOptionStats, err
+}
@@ -2,8 +2,13 @@ package repository_test
 
 import (
 	"challenge/internal/entity"
+	"challenge/internal/repository"
 	"challenge/pkg/gormprovider"
+	"context"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func addQuestionOption(t *testing.T, sqlProvider *gormprovider.SQLiteProvider, questionOption *entity.QuestionOption) *entity.QuestionOption {
@@ -19,5 +24,56 @@ func TestQuestionOptionRepository_BulkCreateQuestionOptions(t *testing.T) {
 }
 
 func TestQuestionOptionRepository_BulkReplaceQuestionOptions(t *testing.T) {
-	// TODO
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	correct := true
+	incorrect := false
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+	addQuestion(t, sqlProvider, &entity.Question{Id: 2})
+	first := addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "first", Correct: &correct, QuestionId: 1})
+	second := addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "second", Correct: &incorrect, QuestionId: 1, Position: 1})
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "removed", Correct: &incorrect, QuestionId: 1, Position: 2})
+	otherQuestionOption := addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "other", Correct: &correct, QuestionId: 2})
+
+	repo := repository.NewQuestionOptionRepository(sqlProvider)
+	questionOptions := []entity.QuestionOption{
+		{Id: second.Id, Body: "second updated", Correct: &correct},
+		{Body: "new", Correct: &incorrect},
+		{Id: first.Id, Body: "first", Correct: &incorrect},
+	}
+	require.NoError(t, repo.BulkReplaceQuestionOptions(context.Background(), 1, questionOptions))
+	assert.NotZero(t, questionOptions[1].Id)
+
+	question, err := repository.NewQuestionRepository(sqlProvider).GetQuestion(
+		context.Background(),
+		1,
+		gormprovider.OrderedPreloadOption{Association: "QuestionOptions", Order: "position"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, questionOptions, question.QuestionOptions)
+
+	// Options of other questions cannot be taken over
+	err = repo.BulkReplaceQuestionOptions(context.Background(), 1, []entity.QuestionOption{
+		{Id: otherQuestionOption.Id, Body: "taken", Correct: &correct},
+	})
+	assert.Error(t, err)
+}
+
+func TestQuestionOptionRepository_ListQuestionOptions(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	correct := true
+	for _, questionId := range []uint{1, 2, 3} {
+		addQuestion(t, sqlProvider, &entity.Question{Id: questionId})
+	}
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "b", Correct: &correct, Position: 1, QuestionId: 2})
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "c", Correct: &correct, Position: 0, QuestionId: 3})
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "a", Correct: &correct, Position: 0, QuestionId: 2})
+	addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "d", Correct: &correct, Position: 0, QuestionId: 1})
+
+	questionOptions, err := repository.NewQuestionOptionRepository(sqlProvider).ListQuestionOptions(context.Background(), []uint{2, 3})
+	require.NoError(t, err)
+	bodies := []string{}
+	for _, questionOption := range questionOptions {
+		bodies = append(bodies, questionOption.Body)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, bodies)
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuestionStatsRepository interface {
+	gormprovider.Repository
+	IncrementQuestionStats(ctx context.Context, questionStats []entity.QuestionStats) error
+	GetQuestionStats(ctx context.Context, questionId uint, questionRevision uint) (entity.QuestionStats, error)
+	ListQuestionStats(ctx context.Context, questionIds []uint) ([]entity.QuestionStats, error)
+}
+
+func NewQuestionStatsRepository(provider *gormprovider.SQLiteProvider) *questionStatsRepository {
+	return &questionStatsRepository{provider.NewRepository("question_stats")}
+}
+
+type questionStatsRepository struct {
+	gormprovider.Repository
+}
+
+// IncrementQuestionStats adds the sums to the stored ones, creating them on the first response
+func (r *questionStatsRepository) IncrementQuestionStats(ctx context.Context, questionStats []entity.QuestionStats) error {
+	if len(questionStats) == 0 {
+		return nil
+	}
+	return r.NewQuery(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "question_id"}, {Name: "question_revision"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"responses":         gorm.Expr("responses + excluded.responses"),
+				"score_sum":         gorm.Expr("score_sum + excluded.score_sum"),
+				"score_squares_sum": gorm.Expr("score_squares_sum + excluded.score_squares_sum"),
+				"total_sum":         gorm.Expr("total_sum + excluded.total_sum"),
+				"total_squares_sum": gorm.Expr("total_squares_sum + excluded.total_squares_sum"),
+				"score_total_sum":   gorm.Expr("score_total_sum + excluded.score_total_sum"),
+			}),
+		}).
+		Create(&questionStats).Error
+}
+
+func (r *questionStatsRepository) GetQuestionStats(ctx context.Context, questionId uint, questionRevision uint) (entity.QuestionStats, error) {
+	var questionStats entity.QuestionStats
+	err := r.NewQuery(ctx).
+		Where("question_id", questionId).
+		Where("question_revision", questionRevision).
+		First(&questionStats).Error
+	return questionStats, err
+}
+
+// ListQuestionStats returns the stats of every answered revision of all the questions at once
+func (r *questionStatsRepository) ListQuestionStats(ctx context.Context, questionIds []uint) ([]entity.QuestionStats, error) {
+	var questionStats []entity.QuestionStats
+	err := r.NewQuery(ctx).
+		Where("question_id IN ?", questionIds).
+		Order("question_id").
+		Order("question_revision").
+		Find(&questionStats).Error
+	return questionStats, err
+}
@@ -0,0 +1,76 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionStatsRepository_IncrementQuestionStats(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+
+	repo := repository.NewQuestionStatsRepository(sqlProvider)
+	increment := entity.QuestionStats{
+		QuestionId:       1,
+		QuestionRevision: 2,
+		Responses:        1,
+		ScoreSum:         0.5,
+		ScoreSquaresSum:  0.25,
+		TotalSum:         1,
+		TotalSquaresSum:  1,
+		ScoreTotalSum:    0.5,
+	}
+	require.NoError(t, repo.IncrementQuestionStats(context.Background(), []entity.QuestionStats{increment}))
+	require.NoError(t, repo.IncrementQuestionStats(context.Background(), []entity.QuestionStats{increment}))
+	require.NoError(t, repo.IncrementQuestionStats(context.Background(), nil))
+
+	questionStats, err := repo.GetQuestionStats(context.Background(), 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, entity.QuestionStats{
+		QuestionId:       1,
+		QuestionRevision: 2,
+		Responses:        2,
+		ScoreSum:         1,
+		ScoreSquaresSum:  0.5,
+		TotalSum:         2,
+		TotalSquaresSum:  2,
+		ScoreTotalSum:    1,
+	}, questionStats)
+
+	_, err = repo.GetQuestionStats(context.Background(), 1, 1)
+	assert.Error(t, err)
+
+	require.NoError(t, repo.IncrementQuestionStats(context.Background(), []entity.QuestionStats{{QuestionId: 1, QuestionRevision: 1}, {QuestionId: 2, QuestionRevision: 1}}))
+	questionStatsList, err := repo.ListQuestionStats(context.Background(), []uint{1})
+	require.NoError(t, err)
+	require.Len(t, questionStatsList, 2)
+	assert.Equal(t, uint(1), questionStatsList[0].QuestionRevision)
+	assert.Equal(t, questionStats, questionStatsList[1])
+}
+
+func TestQuestionOptionStatsRepository_IncrementQuestionOptionStats(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+
+	repo := repository.NewQuestionOptionStatsRepository(sqlProvider)
+	require.NoError(t, repo.IncrementQuestionOptionStats(context.Background(), []entity.QuestionOptionStats{
+		{QuestionId: 1, QuestionRevision: 1, Position: 1, Selections: 1, TotalSum: 0.5},
+		{QuestionId: 1, QuestionRevision: 1, Position: 0, Correct: true},
+	}))
+	require.NoError(t, repo.IncrementQuestionOptionStats(context.Background(), []entity.QuestionOptionStats{
+		{QuestionId: 1, QuestionRevision: 1, Position: 1, Selections: 1, TotalSum: 0.25},
+		{QuestionId: 1, QuestionRevision: 1, Position: 0, Correct: true, Selections: 1, TotalSum: 1},
+		{QuestionId: 1, QuestionRevision: 2, Position: 0, Selections: 1, TotalSum: 1},
+	}))
+
+	questionOptionStats, err := repo.ListQuestionOptionStats(context.Background(), 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.QuestionOptionStats{
+		{QuestionId: 1, QuestionRevision: 1, Position: 0, Correct: true, Selections: 1, TotalSum: 1},
+		{QuestionId: 1, QuestionRevision: 1, Position: 1, Selections: 2, TotalSum: 0.75},
+	}, questionOptionStats)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+)
+
+type QuestionTagRepository interface {
+	gormprovider.Repository
+	BulkCreateQuestionTags(ctx context.Context, questionId uint, questionTags []entity.QuestionTag) error
+	BulkReplaceQuestionTags(ctx context.Context, questionId uint, questionTags []entity.QuestionTag) error
+	ListQuestionTags(ctx context.Context, questionIds []uint) ([]entity.QuestionTag, error)
+}
+
+func NewQuestionTagRepository(provider *gormprovider.SQLiteProvider) *questionTagRepository {
+	return &questionTagRepository{provider.NewRepository("question_tags")}
+}
+
+type questionTagRepository struct {
+	gormprovider.Repository
+}
+
+func (r *questionTagRepository) BulkCreateQuestionTags(ctx context.Context, questionId uint, questionTags []entity.QuestionTag) error {
+	if len(questionTags) == 0 {
+		return nil
+	}
+	for i := range questionTags {
+		questionTags[i].QuestionId = questionId
+	}
+	return r.NewQuery(ctx).Create(&questionTags).Error
+}
+
+func (r *questionTagRepository) BulkReplaceQuestionTags(ctx context.Context, questionId uint, questionTags []entity.QuestionTag) error {
+	return r.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := r.NewQuery(txCtx).Where("question_id", questionId).Delete(&entity.QuestionTag{}).Error
+		if err != nil {
+			return err
+		}
+
+		return r.BulkCreateQuestionTags(txCtx, questionId, questionTags)
+	})
+}
+
+// ListQuestionTags returns the tags of all the questions at once
+func (r *questionTagRepository) ListQuestionTags(ctx context.Context, questionIds []uint) ([]entity.QuestionTag, error) {
+	var questionTags []entity.QuestionTag
+	err := r.NewQuery(ctx).
+		Where("question_id IN ?", questionIds).
+		Order("question_id").
+		Order("id").
+		Find(&questionTags).Error
+	return questionTags, err
+}
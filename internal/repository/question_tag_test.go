@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addQuestionTag(t *testing.T, sqlProvider *gormprovider.SQLiteProvider, questionTag *entity.QuestionTag) *entity.QuestionTag {
+	err := sqlProvider.DB.Table("question_tags").Create(questionTag).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	return questionTag
+}
+
+func TestQuestionTagRepository_BulkReplaceQuestionTags(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+	addQuestionTag(t, sqlProvider, &entity.QuestionTag{Name: "go", QuestionId: 1})
+
+	repo := repository.NewQuestionTagRepository(sqlProvider)
+	err := repo.BulkReplaceQuestionTags(context.Background(), 1, []entity.QuestionTag{{Name: "sql"}, {Name: "databases"}})
+	require.NoError(t, err)
+
+	question, err := repository.NewQuestionRepository(sqlProvider).GetQuestion(context.Background(), 1, gormprovider.PreloadOption("Tags"))
+	require.NoError(t, err)
+	names := make([]string, len(question.Tags))
+	for i, questionTag := range question.Tags {
+		names[i] = questionTag.Name
+	}
+	assert.ElementsMatch(t, []string{"sql", "databases"}, names)
+}
+
+func TestQuestionTagRepository_ListQuestionTags(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	for _, questionId := range []uint{1, 2, 3} {
+		addQuestion(t, sqlProvider, &entity.Question{Id: questionId})
+	}
+	addQuestionTag(t, sqlProvider, &entity.QuestionTag{Name: "sql", QuestionId: 2})
+	addQuestionTag(t, sqlProvider, &entity.QuestionTag{Name: "go", QuestionId: 1})
+	addQuestionTag(t, sqlProvider, &entity.QuestionTag{Name: "go", QuestionId: 2})
+
+	questionTags, err := repository.NewQuestionTagRepository(sqlProvider).ListQuestionTags(context.Background(), []uint{2, 3})
+	require.NoError(t, err)
+	require.Len(t, questionTags, 2)
+	assert.Equal(t, entity.QuestionTag{Id: 1, Name: "sql", QuestionId: 2}, questionTags[0])
+	assert.Equal(t, entity.QuestionTag{Id: 3, Name: "go", QuestionId: 2}, questionTags[1])
+}
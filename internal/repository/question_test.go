@@ -50,12 +50,28 @@ func TestQuestionRepository_ListQuestions(t *testing.T) {
 			Opts:        []gormprovider.Option{repository.QuestionFilter{AuthorId: authorId}},
 			ExpectedIds: []uint{2},
 		},
+		{
+			TestName:    "Use QuestionFilter Difficulty",
+			Opts:        []gormprovider.Option{repository.QuestionFilter{Difficulty: entity.DifficultyHard}},
+			ExpectedIds: []uint{1},
+		},
+		{
+			TestName:    "Use QuestionFilter Tags",
+			Opts:        []gormprovider.Option{repository.QuestionFilter{Tags: []string{"go", "sql"}}},
+			ExpectedIds: []uint{2},
+		},
+		{
+			TestName:    "Use QuestionFilter ExcludeIds",
+			Opts:        []gormprovider.Option{repository.QuestionFilter{ExcludeIds: []uint{2}}},
+			ExpectedIds: []uint{1},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.TestName, func(t *testing.T) {
 			sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
-			addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+			addQuestion(t, sqlProvider, &entity.Question{Id: 1, Difficulty: entity.DifficultyHard})
 			addQuestion(t, sqlProvider, &entity.Question{Id: 2, AuthorId: authorId})
+			addQuestionTag(t, sqlProvider, &entity.QuestionTag{Name: "go", QuestionId: 2})
 
 			repo := repository.NewQuestionRepository(sqlProvider)
 			questions, err := repo.ListQuestions(context.Background(), test.PageSize, test.LastId, test.Opts...)
@@ -78,7 +94,17 @@ func TestQuestionRepository_GetQuestion(t *testing.T) {
 }
 
 func TestQuestionRepository_UpdateQuestion(t *testing.T) {
-	// TODO
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1, Body: "question", Revision: 1})
+
+	repo := repository.NewQuestionRepository(sqlProvider)
+	err := repo.UpdateQuestion(context.Background(), 1, &entity.Question{Body: "updated question"})
+	require.NoError(t, err)
+
+	question, err := repo.GetQuestion(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "updated question", question.Body)
+	assert.Equal(t, uint(2), question.Revision)
 }
 
 func TestQuestionRepository_DeleteQuestion(t *testing.T) {
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuestionTranslationRepository interface {
+	gormprovider.Repository
+	ListQuestionTranslations(ctx context.Context, questionIds []uint) ([]entity.QuestionTranslation, error)
+	SaveQuestionTranslation(ctx context.Context, questionTranslation *entity.QuestionTranslation) error
+	DeleteQuestionTranslation(ctx context.Context, questionId uint, language string) error
+}
+
+func NewQuestionTranslationRepository(provider *gormprovider.SQLiteProvider) *questionTranslationRepository {
+	return &questionTranslationRepository{provider.NewRepository("question_translations")}
+}
+
+type questionTranslationRepository struct {
+	gormprovider.Repository
+}
+
+// ListQuestionTranslations returns the translations of the questions with their option translations,
+// ordered by question and language
+func (r *questionTranslationRepository) ListQuestionTranslations(ctx context.Context, questionIds []uint) ([]entity.QuestionTranslation, error) {
+	questionTranslations := []entity.QuestionTranslation{}
+	err := r.NewQuery(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where("question_id IN ?", questionIds).
+		Order("question_id").
+		Order("language").
+		Find(&questionTranslations).Error
+	return questionTranslations, err
+}
+
+// SaveQuestionTranslation replaces the translation of the question into the same language, if any
+func (r *questionTranslationRepository) SaveQuestionTranslation(ctx context.Context, questionTranslation *entity.QuestionTranslation) error {
+	return r.RunInTransaction(ctx, func(txCtx context.Context) error {
+		err := r.NewQuery(txCtx).
+			Where("question_id", questionTranslation.QuestionId).
+			Where("language", questionTranslation.Language).
+			Delete(&entity.QuestionTranslation{}).Error
+		if err != nil {
+			return err
+		}
+
+		questionTranslation.Id = 0
+		err = r.NewQuery(txCtx).Omit(clause.Associations).Create(questionTranslation).Error
+		if err != nil || len(questionTranslation.Options) == 0 {
+			return err
+		}
+		for i := range questionTranslation.Options {
+			questionTranslation.Options[i].Id = 0
+			questionTranslation.Options[i].QuestionTranslationId = questionTranslation.Id
+		}
+		return r.NewQuery(txCtx).Table("question_option_translations").Create(&questionTranslation.Options).Error
+	})
+}
+
+func (r *questionTranslationRepository) DeleteQuestionTranslation(ctx context.Context, questionId uint, language string) error {
+	res := r.NewQuery(ctx).Where("question_id", questionId).Where("language", language).Delete(&entity.QuestionTranslation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
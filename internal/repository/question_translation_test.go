@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestQuestionTranslationRepository_SaveQuestionTranslation(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+	correct := true
+	addQuestion(t, sqlProvider, &entity.Question{Id: 1})
+	addQuestion(t, sqlProvider, &entity.Question{Id: 2})
+	first := addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "first", Correct: &correct, QuestionId: 1})
+	second := addQuestionOption(t, sqlProvider, &entity.QuestionOption{Body: "second", Correct: &correct, QuestionId: 1, Position: 1})
+
+	repo := repository.NewQuestionTranslationRepository(sqlProvider)
+	ctx := context.Background()
+	require.NoError(t, repo.SaveQuestionTranslation(ctx, &entity.QuestionTranslation{
+		QuestionId: 1,
+		Language:   "de",
+		Body:       "Frage",
+		Options:    []entity.QuestionOptionTranslation{{QuestionOptionId: first.Id, Body: "erste"}},
+	}))
+	require.NoError(t, repo.SaveQuestionTranslation(ctx, &entity.QuestionTranslation{QuestionId: 2, Language: "pt", Body: "pergunta"}))
+
+	// Saving the same language again replaces the translation
+	require.NoError(t, repo.SaveQuestionTranslation(ctx, &entity.QuestionTranslation{
+		QuestionId:       1,
+		QuestionRevision: 2,
+		Language:         "de",
+		Body:             "Neue Frage",
+		Options:          []entity.QuestionOptionTranslation{{QuestionOptionId: second.Id, Body: "zweite"}},
+	}))
+	require.NoError(t, repo.SaveQuestionTranslation(ctx, &entity.QuestionTranslation{QuestionId: 1, Language: "pt", Body: "pergunta"}))
+
+	questionTranslations, err := repo.ListQuestionTranslations(ctx, []uint{1})
+	require.NoError(t, err)
+	require.Len(t, questionTranslations, 2)
+	assert.Equal(t, "de", questionTranslations[0].Language)
+	assert.Equal(t, "Neue Frage", questionTranslations[0].Body)
+	assert.Equal(t, uint(2), questionTranslations[0].QuestionRevision)
+	require.Len(t, questionTranslations[0].Options, 1)
+	assert.Equal(t, second.Id, questionTranslations[0].Options[0].QuestionOptionId)
+	assert.Equal(t, "pt", questionTranslations[1].Language)
+	assert.Empty(t, questionTranslations[1].Options)
+
+	// Deleting an option deletes its translations
+	require.NoError(t, sqlProvider.DB.Table("question_options").Delete(&entity.QuestionOption{}, second.Id).Error)
+	questionTranslations, err = repo.ListQuestionTranslations(ctx, []uint{1})
+	require.NoError(t, err)
+	assert.Empty(t, questionTranslations[0].Options)
+
+	require.NoError(t, repo.DeleteQuestionTranslation(ctx, 1, "de"))
+	assert.ErrorIs(t, repo.DeleteQuestionTranslation(ctx, 1, "de"), gorm.ErrRecordNotFound)
+	questionTranslations, err = repo.ListQuestionTranslations(ctx, []uint{1, 2})
+	require.NoError(t, err)
+	assert.Len(t, questionTranslations, 2)
+}
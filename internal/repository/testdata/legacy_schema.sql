@@ -0,0 +1,67 @@
+-- Tables as they were first created, before columns were added to them
+
+CREATE TABLE IF NOT EXISTS questions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	body TEXT NOT NULL,
+	author_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS question_options (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	body TEXT NOT NULL,
+  correct INTEGER NOT NULL,
+  question_id INTEGER NOT NULL,
+  FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS assessments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	time_limit_seconds INTEGER NOT NULL DEFAULT 0,
+	passing_score REAL NOT NULL DEFAULT 0,
+	author_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at DATETIME NOT NULL,
+	deadline DATETIME,
+	finished_at DATETIME,
+	candidate_id INTEGER NOT NULL,
+	assessment_id INTEGER NOT NULL,
+	FOREIGN KEY(assessment_id) REFERENCES assessments(id) ON DELETE CASCADE,
+	UNIQUE(assessment_id, candidate_id)
+);
+
+CREATE TABLE IF NOT EXISTS attempt_answers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	selected_options TEXT NOT NULL,
+	answered_at DATETIME NOT NULL,
+	attempt_id INTEGER NOT NULL,
+	assessment_question_id INTEGER NOT NULL,
+	FOREIGN KEY(attempt_id) REFERENCES attempts(id) ON DELETE CASCADE,
+	FOREIGN KEY(assessment_question_id) REFERENCES assessment_questions(id) ON DELETE CASCADE,
+	UNIQUE(attempt_id, assessment_question_id)
+);
+
+CREATE TABLE IF NOT EXISTS question_translations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	question_id INTEGER NOT NULL,
+	question_revision INTEGER NOT NULL,
+	language TEXT NOT NULL,
+	body TEXT NOT NULL,
+	body_format TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY(question_id) REFERENCES questions(id) ON DELETE CASCADE,
+	UNIQUE(question_id, language)
+);
+
+CREATE TABLE IF NOT EXISTS question_option_translations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	question_translation_id INTEGER NOT NULL,
+	question_option_id INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	body_format TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY(question_translation_id) REFERENCES question_translations(id) ON DELETE CASCADE,
+	FOREIGN KEY(question_option_id) REFERENCES question_options(id) ON DELETE CASCADE,
+	UNIQUE(question_translation_id, question_option_id)
+);
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type WebhookDeliveryRepository interface {
+	gormprovider.Repository
+	EnqueueWebhookEvent(ctx context.Context, event entity.WebhookEvent) error
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, webhookDelivery *entity.WebhookDelivery) error
+	RetryWebhookDelivery(ctx context.Context, id uint, now time.Time) (bool, error)
+}
+
+func NewWebhookDeliveryRepository(provider *gormprovider.SQLiteProvider) *webhookDeliveryRepository {
+	return &webhookDeliveryRepository{provider.NewRepository("webhook_deliveries")}
+}
+
+type webhookDeliveryRepository struct {
+	gormprovider.Repository
+}
+
+// EnqueueWebhookEvent creates a pending delivery of the event for every subscription to its type.
+// Enqueuing an event again doesn't create more deliveries
+func (r *webhookDeliveryRepository) EnqueueWebhookEvent(ctx context.Context, event entity.WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.NewQuery(ctx).Exec(`
+		INSERT INTO webhook_deliveries (webhook_subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT id, ?, ?, ?, ?, ?, ? FROM webhook_subscriptions
+		WHERE EXISTS (SELECT 1 FROM json_each(webhook_subscriptions.events) WHERE json_each.value = ?)
+		ON CONFLICT (webhook_subscription_id, event_id) DO NOTHING`,
+		event.Id, event.Type, string(payload), entity.WebhookDeliveryPending, event.CreatedAt, event.CreatedAt, event.Type,
+	).Error
+}
+
+// ListDueWebhookDeliveries returns the pending deliveries to attempt at the given time with their subscription,
+// oldest first
+func (r *webhookDeliveryRepository) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	webhookDeliveries := []entity.WebhookDelivery{}
+	err := r.NewQuery(ctx).
+		Preload("WebhookSubscription").
+		Where("status", entity.WebhookDeliveryPending).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&webhookDeliveries).Error
+	return webhookDeliveries, err
+}
+
+func (r *webhookDeliveryRepository) ListWebhookDeliveries(ctx context.Context, pageSize uint, lastId *uint, opts ...gormprovider.Option) ([]entity.WebhookDelivery, error) {
+	if pageSize == 0 {
+		pageSize = 10
+	}
+
+	qry := gormprovider.ApplyOptions(r.NewQuery(ctx), opts...).Order("webhook_deliveries.id").Limit(int(pageSize))
+	if lastId != nil {
+		qry = qry.Where("webhook_deliveries.id > ?", *lastId)
+	}
+
+	webhookDeliveries := []entity.WebhookDelivery{}
+	err := qry.Find(&webhookDeliveries).Error
+	return webhookDeliveries, err
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery attempt
+func (r *webhookDeliveryRepository) UpdateWebhookDelivery(ctx context.Context, webhookDelivery *entity.WebhookDelivery) error {
+	return r.NewQuery(ctx).
+		Where("id", webhookDelivery.Id).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "last_status_code", "last_error", "delivered_at").
+		Updates(webhookDelivery).Error
+}
+
+// RetryWebhookDelivery puts a dead delivery back in the queue with its attempts reset.
+// It returns false when the delivery is not dead
+func (r *webhookDeliveryRepository) RetryWebhookDelivery(ctx context.Context, id uint, now time.Time) (bool, error) {
+	res := r.NewQuery(ctx).
+		Where("id", id).
+		Where("status", entity.WebhookDeliveryDead).
+		Updates(map[string]any{
+			"status":          entity.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "gorm.io/gorm"
+
+type WebhookDeliveryFilter struct {
+	WebhookSubscriptionId uint
+	Status                string
+	EventId               string
+}
+
+func (f WebhookDeliveryFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.WebhookSubscriptionId != 0 {
+		db.Where("webhook_deliveries.webhook_subscription_id", f.WebhookSubscriptionId)
+	}
+	if f.Status != "" {
+		db.Where("webhook_deliveries.status", f.Status)
+	}
+	if f.EventId != "" {
+		db.Where("webhook_deliveries.event_id", f.EventId)
+	}
+
+	return db
+}
@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/gormprovider"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveryRepository_EnqueueWebhookEvent(t *testing.T) {
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, getDatabaseInitSql(t))
+
+	subscriptionRepo := repository.NewWebhookSubscriptionRepository(sqlProvider)
+	repo := repository.NewWebhookDeliveryRepository(sqlProvider)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	created := entity.WebhookSubscription{Url: "https://a.example.com", Events: entity.WebhookEventTypes{"question.created"}, CreatedAt: now}
+	require.NoError(t, subscriptionRepo.CreateWebhookSubscription(ctx, &created))
+	all := entity.WebhookSubscription{Url: "https://b.example.com", Events: entity.WebhookEventTypes{"question.created", "question.deleted"}, CreatedAt: now}
+	require.NoError(t, subscriptionRepo.CreateWebhookSubscription(ctx, &all))
+
+	require.NoError(t, repo.EnqueueWebhookEvent(ctx, entity.WebhookEvent{Id: "1", Type: "question.created", CreatedAt: now, Data: []byte(`{}`)}))
+	require.NoError(t, repo.EnqueueWebhookEvent(ctx, entity.WebhookEvent{Id: "2", Type: "question.deleted", CreatedAt: now, Data: []byte(`{}`)}))
+	require.NoError(t, repo.EnqueueWebhookEvent(ctx, entity.WebhookEvent{Id: "3", Type: "question.updated", CreatedAt: now, Data: []byte(`{}`)}))
+	// Events published again by the outbox are not delivered twice
+	require.NoError(t, repo.EnqueueWebhookEvent(ctx, entity.WebhookEvent{Id: "1", Type: "question.created", CreatedAt: now, Data: []byte(`{}`)}))
+
+	webhookDeliveries, err := repo.ListDueWebhookDeliveries(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, webhookDeliveries, 3)
+	for _, webhookDelivery := range webhookDeliveries {
+		require.NotNil(t, webhookDelivery.WebhookSubscription)
+		assert.Equal(t, entity.WebhookDeliveryPending, webhookDelivery.Status)
+	}
+	webhookDeliveries, err = repo.ListWebhookDeliveries(ctx, 0, nil, repository.WebhookDeliveryFilter{WebhookSubscriptionId: all.Id})
+	require.NoError(t, err)
+	require.Len(t, webhookDeliveries, 2)
+	assert.Equal(t, "1", webhookDeliveries[0].EventId)
+	assert.Equal(t, "2", webhookDeliveries[1].EventId)
+
+	// Only dead letters can be retried, and deleted subscriptions take their deliveries with them
+	retried, err := repo.RetryWebhookDelivery(ctx, webhookDeliveries[0].Id, now)
+	require.NoError(t, err)
+	assert.False(t, retried)
+	require.NoError(t, subscriptionRepo.DeleteWebhookSubscription(ctx, all.Id))
+	webhookDeliveries, err = repo.ListWebhookDeliveries(ctx, 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, webhookDeliveries, 1)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"challenge/internal/entity"
+	"challenge/pkg/gormprovider"
+	"context"
+)
+
+type WebhookSubscriptionRepository interface {
+	gormprovider.Repository
+	ListWebhookSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error)
+	CreateWebhookSubscription(ctx context.Context, webhookSubscription *entity.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, id uint) (entity.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id uint) error
+}
+
+func NewWebhookSubscriptionRepository(provider *gormprovider.SQLiteProvider) *webhookSubscriptionRepository {
+	return &webhookSubscriptionRepository{provider.NewRepository("webhook_subscriptions")}
+}
+
+type webhookSubscriptionRepository struct {
+	gormprovider.Repository
+}
+
+func (r *webhookSubscriptionRepository) ListWebhookSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	webhookSubscriptions := []entity.WebhookSubscription{}
+	err := r.NewQuery(ctx).Order("id").Find(&webhookSubscriptions).Error
+	return webhookSubscriptions, err
+}
+
+func (r *webhookSubscriptionRepository) CreateWebhookSubscription(ctx context.Context, webhookSubscription *entity.WebhookSubscription) error {
+	return r.NewQuery(ctx).Create(webhookSubscription).Error
+}
+
+func (r *webhookSubscriptionRepository) GetWebhookSubscription(ctx context.Context, id uint) (entity.WebhookSubscription, error) {
+	var webhookSubscription entity.WebhookSubscription
+	err := r.NewQuery(ctx).Where("id", id).First(&webhookSubscription).Error
+	return webhookSubscription, err
+}
+
+func (r *webhookSubscriptionRepository) DeleteWebhookSubscription(ctx context.Context, id uint) error {
+	return r.NewQuery(ctx).Delete(&entity.WebhookSubscription{Id: id}).Error
+}
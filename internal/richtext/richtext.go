@@ -0,0 +1,209 @@
+package richtext
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+)
+
+// allowedTags are the HTML tags kept in markdown bodies, only when they have no attributes
+var allowedTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "s": true, "del": true, "mark": true,
+	"code": true, "kbd": true, "sub": true, "sup": true, "small": true, "br": true,
+}
+
+// allowedSchemes are the URL schemes kept in links and images, URLs without scheme are relative
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+var tagPattern = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)\s*/?>$`)
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithASTTransformers(util.Prioritized(urlSanitizer{}, 100))),
+	goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(htmlSanitizer{}, 100))),
+)
+
+// Sanitize escapes the HTML embedded in markdown bodies, except the allowed tags,
+// so it shows as text. Plain bodies are text already and are returned as they are
+func Sanitize(format string, body string) string {
+	if format != FormatMarkdown {
+		return body
+	}
+
+	source := []byte(body)
+	var unsafeSegments []text.Segment
+	_ = ast.Walk(markdown.Parser().Parse(text.NewReader(source)), func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.RawHTML:
+			if _, allowed := allowedTag(segmentsValue(n.Segments, source)); !allowed {
+				unsafeSegments = append(unsafeSegments, n.Segments.Sliced(0, n.Segments.Len())...)
+			}
+		case *ast.HTMLBlock:
+			unsafeSegments = append(unsafeSegments, n.Lines().Sliced(0, n.Lines().Len())...)
+			if n.HasClosure() {
+				unsafeSegments = append(unsafeSegments, n.ClosureLine)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if len(unsafeSegments) == 0 {
+		return body
+	}
+
+	// Replace from the end, so the positions of the previous segments stay valid
+	sort.Slice(unsafeSegments, func(i, j int) bool { return unsafeSegments[i].Start > unsafeSegments[j].Start })
+	for _, segment := range unsafeSegments {
+		escaped := bytes.ReplaceAll(source[segment.Start:segment.Stop], []byte("<"), []byte("&lt;"))
+		source = append(source[:segment.Start:segment.Start], append(escaped, source[segment.Stop:]...)...)
+	}
+	return string(source)
+}
+
+// RenderHTML renders the body as HTML that is safe to embed in a page
+func RenderHTML(format string, body string) (string, error) {
+	if format != FormatMarkdown {
+		paragraph := strings.ReplaceAll(html.EscapeString(body), "\n", "<br>\n")
+		return "<p>" + paragraph + "</p>", nil
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(body), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// allowedTag returns the normalized tag when it is an allowed tag without attributes
+func allowedTag(raw []byte) (string, bool) {
+	match := tagPattern.FindSubmatch(bytes.TrimSpace(raw))
+	if match == nil {
+		return "", false
+	}
+	name := strings.ToLower(string(match[2]))
+	if !allowedTags[name] {
+		return "", false
+	}
+	return "<" + string(match[1]) + name + ">", true
+}
+
+// isSafeURL accepts relative URLs and the allowed schemes. The scheme is taken like browsers do, after the backslash
+// escapes and the HTML entities are decoded, ignoring the case and the whitespace and control characters
+func isSafeURL(url []byte) bool {
+	decoded := string(util.UnescapePunctuations(url))
+	// Decode until nothing changes, so entities of entities don't hide the scheme either
+	for {
+		unescaped := html.UnescapeString(decoded)
+		if unescaped == decoded {
+			break
+		}
+		decoded = unescaped
+	}
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, decoded)
+	scheme, _, found := strings.Cut(cleaned, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	return allowedSchemes[strings.ToLower(scheme)]
+}
+
+func segmentsValue(segments *text.Segments, source []byte) []byte {
+	var value []byte
+	for i := 0; i < segments.Len(); i++ {
+		segment := segments.At(i)
+		value = append(value, segment.Value(source)...)
+	}
+	return value
+}
+
+// urlSanitizer removes the destination of links and images with unsafe URLs
+// and turns unsafe autolinks into text
+type urlSanitizer struct{}
+
+func (urlSanitizer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	var unsafeAutoLinks []*ast.AutoLink
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Link:
+			if !isSafeURL(n.Destination) {
+				n.Destination = nil
+			}
+		case *ast.Image:
+			if !isSafeURL(n.Destination) {
+				n.Destination = nil
+			}
+		case *ast.AutoLink:
+			if !isSafeURL(n.URL(source)) {
+				unsafeAutoLinks = append(unsafeAutoLinks, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, autoLink := range unsafeAutoLinks {
+		autoLink.Parent().ReplaceChild(autoLink.Parent(), autoLink, ast.NewString(autoLink.Label(source)))
+	}
+}
+
+// htmlSanitizer renders the allowed embedded HTML tags and escapes the rest of the embedded HTML,
+// which goldmark would otherwise drop or, in unsafe mode, keep as is
+type htmlSanitizer struct{}
+
+func (r htmlSanitizer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+}
+
+func (htmlSanitizer) renderRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	raw := segmentsValue(node.(*ast.RawHTML).Segments, source)
+	if tag, allowed := allowedTag(raw); allowed {
+		_, _ = w.WriteString(tag)
+	} else {
+		_, _ = w.Write(util.EscapeHTML(raw))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (htmlSanitizer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*ast.HTMLBlock)
+	raw := segmentsValue(n.Lines(), source)
+	if n.HasClosure() {
+		raw = append(raw, n.ClosureLine.Value(source)...)
+	}
+	_, _ = w.WriteString("<p>")
+	_, _ = w.Write(util.EscapeHTML(bytes.TrimRight(raw, "\n")))
+	_, _ = w.WriteString("</p>\n")
+	return ast.WalkSkipChildren, nil
+}
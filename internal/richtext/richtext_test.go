@@ -0,0 +1,145 @@
+package richtext_test
+
+import (
+	"challenge/internal/richtext"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	type Test struct {
+		TestName string
+		Format   string
+		Body     string
+		Expected string
+	}
+	tests := []Test{
+		{
+			TestName: "PlainIsUnchanged",
+			Format:   richtext.FormatPlain,
+			Body:     "Is <script> a tag?",
+			Expected: "Is <script> a tag?",
+		},
+		{
+			TestName: "AllowedTagsAreKept",
+			Format:   richtext.FormatMarkdown,
+			Body:     "Press <kbd>Ctrl</kbd> and <b>C</b>",
+			Expected: "Press <kbd>Ctrl</kbd> and <b>C</b>",
+		},
+		{
+			TestName: "InlineTagsAreEscaped",
+			Format:   richtext.FormatMarkdown,
+			Body:     `Click <img src=x onerror="alert(1)"> or <b onclick="alert(1)">here</b>`,
+			Expected: `Click &lt;img src=x onerror="alert(1)"> or &lt;b onclick="alert(1)">here</b>`,
+		},
+		{
+			TestName: "BlocksAreEscaped",
+			Format:   richtext.FormatMarkdown,
+			Body:     "Intro\n\n<script>\nalert(1)\n</script>\n\nOutro",
+			Expected: "Intro\n\n&lt;script>\nalert(1)\n&lt;/script>\n\nOutro",
+		},
+		{
+			TestName: "CodeIsUnchanged",
+			Format:   richtext.FormatMarkdown,
+			Body:     "Use `<div>` in:\n\n```html\n<div onclick=\"x()\"></div>\n```",
+			Expected: "Use `<div>` in:\n\n```html\n<div onclick=\"x()\"></div>\n```",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			sanitized := richtext.Sanitize(test.Format, test.Body)
+			assert.Equal(t, test.Expected, sanitized)
+			assert.Equal(t, sanitized, richtext.Sanitize(test.Format, sanitized))
+		})
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	type Test struct {
+		TestName    string
+		Format      string
+		Body        string
+		Expected    string
+		NotExpected []string
+	}
+	tests := []Test{
+		{
+			TestName: "Plain",
+			Format:   richtext.FormatPlain,
+			Body:     "a < b\nb > c",
+			Expected: "<p>a &lt; b<br>\nb &gt; c</p>",
+		},
+		{
+			TestName: "Markdown",
+			Format:   richtext.FormatMarkdown,
+			Body:     "**Bold** and `code`\n\n```go\nfmt.Println(\"<hi>\")\n```",
+			Expected: "<p><strong>Bold</strong> and <code>code</code></p>\n<pre><code class=\"language-go\">fmt.Println(&quot;&lt;hi&gt;&quot;)\n</code></pre>\n",
+		},
+		{
+			TestName: "AllowedTag",
+			Format:   richtext.FormatMarkdown,
+			Body:     "H<SUB>2</SUB>O",
+			Expected: "<p>H<sub>2</sub>O</p>\n",
+		},
+		{
+			TestName:    "EmbeddedHTML",
+			Format:      richtext.FormatMarkdown,
+			Body:        "<img src=x onerror=alert(1)>\n\ntext <svg onload=alert(1)>",
+			NotExpected: []string{"<img", "<svg"},
+		},
+		{
+			TestName:    "UnsafeLinks",
+			Format:      richtext.FormatMarkdown,
+			Body:        "[a](JavaScript:alert(1)) [b](java\tscript:alert(1)) ![c](vbscript:x) <javascript:alert(1)>",
+			NotExpected: []string{`href="java`, `src="vbscript`, `href="javascript`},
+		},
+		{
+			// Browsers decode the entities and escapes before reading the scheme
+			TestName: "EncodedUnsafeLinks",
+			Format:   richtext.FormatMarkdown,
+			Body: "[a](&#106;avascript:alert(1)) [b](&#x4A;AVASCRIPT&colon;alert(1)) [c](javascript&amp;#58;alert(1)) " +
+				"[d](java\\script:alert(1)) [e](&#x6a;ava&#x09;script:alert(1))",
+			NotExpected: []string{`href="javascript`, `href="java\\`, `href="&`, `href="%`},
+		},
+		{
+			TestName:    "EncodedUnsafeImages",
+			Format:      richtext.FormatMarkdown,
+			Body:        "![a](&#106;avascript:alert(1)) ![b](DaTa:text/html;base64,PHNjcmlwdD4=) ![c](&#100;ata:image/svg+xml,x)",
+			NotExpected: []string{`src="javascript`, `src="data`, `src="&`, `src="%`},
+		},
+		{
+			TestName:    "UnsafeAutoLinks",
+			Format:      richtext.FormatMarkdown,
+			Body:        "<JaVaScRiPt:alert(1)> <vbscript:msgbox(1)> <data:text/html,x>",
+			NotExpected: []string{`href="javascript`, `href="vbscript`, `href="data`},
+		},
+		{
+			TestName: "SafeEncodedLink",
+			Format:   richtext.FormatMarkdown,
+			Body:     "[docs](&#104;ttps://go.dev/doc) [mail](MAILTO:a@b.c) [page](/questions?a=b:c)",
+			Expected: "<p><a href=\"https://go.dev/doc\">docs</a> <a href=\"MAILTO:a@b.c\">mail</a> " +
+				"<a href=\"/questions?a=b:c\">page</a></p>\n",
+		},
+		{
+			TestName: "SafeLink",
+			Format:   richtext.FormatMarkdown,
+			Body:     "[docs](https://go.dev/doc)",
+			Expected: "<p><a href=\"https://go.dev/doc\">docs</a></p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			rendered, err := richtext.RenderHTML(test.Format, test.Body)
+			require.NoError(t, err)
+			if test.Expected != "" {
+				assert.Equal(t, test.Expected, rendered)
+			}
+			for _, notExpected := range test.NotExpected {
+				assert.NotContains(t, strings.ToLower(rendered), strings.ToLower(notExpected))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package scoring
+
+import "challenge/internal/entity"
+
+type AttemptScore struct {
+	Score    float64
+	MaxScore float64
+	Passed   bool
+	// AnswerScores has the points of every answer by assessment question id
+	AnswerScores map[uint]float64
+}
+
+// ScoreAttempt scores every answer against the question snapshots of the assessment.
+// Unanswered questions score zero
+func ScoreAttempt(assessment entity.Assessment, attemptAnswers []entity.AttemptAnswer) AttemptScore {
+	policy := Policy{PartialCredit: assessment.PartialCredit, NegativeMarking: assessment.NegativeMarking}
+
+	answersByQuestion := map[uint]entity.AttemptAnswer{}
+	for _, attemptAnswer := range attemptAnswers {
+		answersByQuestion[attemptAnswer.AssessmentQuestionId] = attemptAnswer
+	}
+
+	attemptScore := AttemptScore{AnswerScores: map[uint]float64{}}
+	for _, assessmentQuestion := range assessment.AssessmentQuestions {
+		points := assessmentQuestion.GetPoints()
+		attemptScore.MaxScore += points
+
+		attemptAnswer, answered := answersByQuestion[assessmentQuestion.Id]
+		if !answered {
+			continue
+		}
+		answer := Answer{SelectedOptions: attemptAnswer.SelectedOptions, NumericValue: attemptAnswer.NumericValue}
+		score := ScoreQuestion(entity.Question(assessmentQuestion.Snapshot), answer, policy) * points
+		attemptScore.AnswerScores[assessmentQuestion.Id] = score
+		attemptScore.Score += score
+	}
+
+	if attemptScore.MaxScore == 0 {
+		attemptScore.Passed = assessment.PassingScore == 0
+	} else {
+		attemptScore.Passed = attemptScore.Score/attemptScore.MaxScore*100 >= assessment.PassingScore
+	}
+
+	return attemptScore
+}
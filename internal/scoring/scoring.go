@@ -0,0 +1,148 @@
+package scoring
+
+import (
+	"challenge/internal/entity"
+	"math"
+)
+
+// Policy configures how answers to multiple choice questions are scored
+type Policy struct {
+	// PartialCredit credits every correctly selected option instead of all or nothing
+	PartialCredit bool
+	// NegativeMarking lets wrong answers score below zero instead of being floored at zero
+	NegativeMarking bool
+}
+
+type Answer struct {
+	SelectedOptions []uint // Option positions, in the candidate order for ordering questions
+	NumericValue    *float64
+}
+
+// ScoreQuestion returns the fraction of the question points earned by the answer,
+// between -1 and 1. Negative fractions only happen with negative marking
+func ScoreQuestion(question entity.Question, answer Answer, policy Policy) float64 {
+	switch question.GetType() {
+	case entity.QuestionTypeMultipleChoice:
+		return scoreMultipleChoice(question, answer, policy)
+	case entity.QuestionTypeNumeric:
+		return scoreNumeric(question, answer)
+	case entity.QuestionTypeOrdering:
+		return scoreOrdering(question, answer, policy)
+	default:
+		return scoreSingleChoice(question, answer, policy)
+	}
+}
+
+func scoreSingleChoice(question entity.Question, answer Answer, policy Policy) float64 {
+	if len(answer.SelectedOptions) == 0 {
+		return 0
+	}
+	if len(answer.SelectedOptions) == 1 && isCorrectOption(question, answer.SelectedOptions[0]) {
+		return 1
+	}
+	return wrongAnswerPenalty(question, policy)
+}
+
+func scoreMultipleChoice(question entity.Question, answer Answer, policy Policy) float64 {
+	if len(answer.SelectedOptions) == 0 {
+		return 0
+	}
+
+	var correctCount, incorrectCount, correctSelected, incorrectSelected int
+	for i := range question.QuestionOptions {
+		if isCorrectOption(question, uint(i)) {
+			correctCount++
+		} else {
+			incorrectCount++
+		}
+	}
+	for _, position := range answer.SelectedOptions {
+		if isCorrectOption(question, position) {
+			correctSelected++
+		} else {
+			incorrectSelected++
+		}
+	}
+
+	if !policy.PartialCredit {
+		if correctSelected == correctCount && incorrectSelected == 0 {
+			return 1
+		}
+		return wrongAnswerPenalty(question, policy)
+	}
+
+	var fraction float64
+	if correctCount > 0 {
+		fraction += float64(correctSelected) / float64(correctCount)
+	}
+	if incorrectCount > 0 {
+		fraction -= float64(incorrectSelected) / float64(incorrectCount)
+	}
+	if !policy.NegativeMarking {
+		fraction = math.Max(fraction, 0)
+	}
+	return fraction
+}
+
+func scoreNumeric(question entity.Question, answer Answer) float64 {
+	if question.NumericAnswer == nil || answer.NumericValue == nil {
+		return 0
+	}
+	if math.Abs(*answer.NumericValue-*question.NumericAnswer) <= question.NumericTolerance {
+		return 1
+	}
+	return 0
+}
+
+// scoreOrdering compares the candidate order to the question option order.
+// Partial credit is the fraction of option pairs in the right relative order
+func scoreOrdering(question entity.Question, answer Answer, policy Policy) float64 {
+	optionCount := len(question.QuestionOptions)
+	if len(answer.SelectedOptions) != optionCount || optionCount == 0 {
+		return 0
+	}
+	seen := make([]bool, optionCount)
+	for _, position := range answer.SelectedOptions {
+		if position >= uint(optionCount) || seen[position] {
+			return 0
+		}
+		seen[position] = true
+	}
+
+	totalPairs := optionCount * (optionCount - 1) / 2
+	if totalPairs == 0 {
+		return 1
+	}
+	concordantPairs := 0
+	for i := 0; i < optionCount; i++ {
+		for j := i + 1; j < optionCount; j++ {
+			if answer.SelectedOptions[i] < answer.SelectedOptions[j] {
+				concordantPairs++
+			}
+		}
+	}
+
+	if concordantPairs == totalPairs {
+		return 1
+	}
+	if !policy.PartialCredit {
+		return 0
+	}
+	return float64(concordantPairs) / float64(totalPairs)
+}
+
+// wrongAnswerPenalty is the fraction lost by a wrong choice answer, so that guessing has no expected gain
+func wrongAnswerPenalty(question entity.Question, policy Policy) float64 {
+	if !policy.NegativeMarking || len(question.QuestionOptions) < 2 {
+		return 0
+	}
+	return -1 / float64(len(question.QuestionOptions)-1)
+}
+
+func isCorrectOption(question entity.Question, position uint) bool {
+	if position >= uint(len(question.QuestionOptions)) {
+		return false
+	}
+	correct := question.QuestionOptions[position].Correct
+	return correct != nil && *correct
+}
@@ -0,0 +1,159 @@
+package scoring_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/scoring"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newChoiceQuestion returns a question whose options are correct when their flag is true
+func newChoiceQuestion(questionType string, correctFlags ...bool) entity.Question {
+	question := entity.Question{Type: questionType, QuestionOptions: make([]entity.QuestionOption, len(correctFlags))}
+	for i := range correctFlags {
+		question.QuestionOptions[i].Correct = &correctFlags[i]
+	}
+	return question
+}
+
+func newOrderingQuestion(optionCount int) entity.Question {
+	return entity.Question{Type: entity.QuestionTypeOrdering, QuestionOptions: make([]entity.QuestionOption, optionCount)}
+}
+
+func newNumericQuestion(answer float64, tolerance float64) entity.Question {
+	return entity.Question{Type: entity.QuestionTypeNumeric, NumericAnswer: &answer, NumericTolerance: tolerance}
+}
+
+func numericValue(value float64) *float64 {
+	return &value
+}
+
+func TestScoreQuestion(t *testing.T) {
+	allOrNothing := scoring.Policy{}
+	partialCredit := scoring.Policy{PartialCredit: true}
+	negativeMarking := scoring.Policy{NegativeMarking: true}
+	partialCreditNegativeMarking := scoring.Policy{PartialCredit: true, NegativeMarking: true}
+
+	singleChoice := newChoiceQuestion(entity.QuestionTypeSingleChoice, false, true, false, false)
+	untypedSingleChoice := newChoiceQuestion("", true, false)
+	multipleChoice := newChoiceQuestion(entity.QuestionTypeMultipleChoice, true, true, false, false)
+
+	type Test struct {
+		TestName      string
+		Question      entity.Question
+		Answer        scoring.Answer
+		Policy        scoring.Policy
+		ExpectedScore float64
+	}
+	tests := []Test{
+		// Single choice
+		{"SingleChoiceCorrect", singleChoice, scoring.Answer{SelectedOptions: []uint{1}}, allOrNothing, 1},
+		{"SingleChoiceWrong", singleChoice, scoring.Answer{SelectedOptions: []uint{0}}, allOrNothing, 0},
+		{"SingleChoiceUnanswered", singleChoice, scoring.Answer{}, negativeMarking, 0},
+		{"SingleChoiceWrongNegativeMarking", singleChoice, scoring.Answer{SelectedOptions: []uint{0}}, negativeMarking, -1.0 / 3},
+		{"SingleChoiceManyOptions", singleChoice, scoring.Answer{SelectedOptions: []uint{1, 2}}, allOrNothing, 0},
+		{"SingleChoicePartialCreditIgnored", singleChoice, scoring.Answer{SelectedOptions: []uint{0}}, partialCredit, 0},
+		{"SingleChoiceUnknownOption", singleChoice, scoring.Answer{SelectedOptions: []uint{9}}, allOrNothing, 0},
+		{"UntypedIsSingleChoice", untypedSingleChoice, scoring.Answer{SelectedOptions: []uint{0}}, allOrNothing, 1},
+
+		// Multiple choice
+		{"MultipleChoiceCorrect", multipleChoice, scoring.Answer{SelectedOptions: []uint{1, 0}}, allOrNothing, 1},
+		{"MultipleChoiceMissingOption", multipleChoice, scoring.Answer{SelectedOptions: []uint{0}}, allOrNothing, 0},
+		{"MultipleChoiceExtraOption", multipleChoice, scoring.Answer{SelectedOptions: []uint{0, 1, 2}}, allOrNothing, 0},
+		{"MultipleChoiceUnanswered", multipleChoice, scoring.Answer{}, partialCreditNegativeMarking, 0},
+		{"MultipleChoiceWrongNegativeMarking", multipleChoice, scoring.Answer{SelectedOptions: []uint{2}}, negativeMarking, -1.0 / 3},
+		{"MultipleChoicePartialCorrect", multipleChoice, scoring.Answer{SelectedOptions: []uint{0, 1}}, partialCredit, 1},
+		{"MultipleChoicePartialMissingOption", multipleChoice, scoring.Answer{SelectedOptions: []uint{0}}, partialCredit, 0.5},
+		{"MultipleChoicePartialExtraOption", multipleChoice, scoring.Answer{SelectedOptions: []uint{0, 1, 2}}, partialCredit, 0.5},
+		{"MultipleChoicePartialFloored", multipleChoice, scoring.Answer{SelectedOptions: []uint{0, 2, 3}}, partialCredit, 0},
+		{"MultipleChoicePartialNegativeMarking", multipleChoice, scoring.Answer{SelectedOptions: []uint{0, 2, 3}}, partialCreditNegativeMarking, -0.5},
+		{"MultipleChoicePartialAllWrong", multipleChoice, scoring.Answer{SelectedOptions: []uint{2, 3}}, partialCreditNegativeMarking, -1},
+
+		// Numeric
+		{"NumericExact", newNumericQuestion(3.14, 0), scoring.Answer{NumericValue: numericValue(3.14)}, allOrNothing, 1},
+		{"NumericWithinTolerance", newNumericQuestion(3.14, 0.01), scoring.Answer{NumericValue: numericValue(3.15)}, allOrNothing, 1},
+		{"NumericBelowTolerance", newNumericQuestion(3.14, 0.01), scoring.Answer{NumericValue: numericValue(3.12)}, allOrNothing, 0},
+		{"NumericOutsideTolerance", newNumericQuestion(10, 1), scoring.Answer{NumericValue: numericValue(11.5)}, partialCredit, 0},
+		{"NumericUnanswered", newNumericQuestion(10, 1), scoring.Answer{}, negativeMarking, 0},
+		{"NumericWithoutAnswer", entity.Question{Type: entity.QuestionTypeNumeric}, scoring.Answer{NumericValue: numericValue(1)}, allOrNothing, 0},
+
+		// Ordering
+		{"OrderingCorrect", newOrderingQuestion(4), scoring.Answer{SelectedOptions: []uint{0, 1, 2, 3}}, allOrNothing, 1},
+		{"OrderingSwapAllOrNothing", newOrderingQuestion(4), scoring.Answer{SelectedOptions: []uint{1, 0, 2, 3}}, allOrNothing, 0},
+		{"OrderingSwapPartialCredit", newOrderingQuestion(4), scoring.Answer{SelectedOptions: []uint{1, 0, 2, 3}}, partialCredit, 5.0 / 6},
+		{"OrderingReversedPartialCredit", newOrderingQuestion(4), scoring.Answer{SelectedOptions: []uint{3, 2, 1, 0}}, partialCredit, 0},
+		{"OrderingRotatedPartialCredit", newOrderingQuestion(3), scoring.Answer{SelectedOptions: []uint{1, 2, 0}}, partialCredit, 1.0 / 3},
+		{"OrderingIncomplete", newOrderingQuestion(3), scoring.Answer{SelectedOptions: []uint{0, 1}}, partialCredit, 0},
+		{"OrderingDuplicates", newOrderingQuestion(3), scoring.Answer{SelectedOptions: []uint{0, 0, 1}}, partialCredit, 0},
+		{"OrderingUnknownOption", newOrderingQuestion(3), scoring.Answer{SelectedOptions: []uint{0, 1, 5}}, partialCredit, 0},
+		{"OrderingUnanswered", newOrderingQuestion(3), scoring.Answer{}, negativeMarking, 0},
+		{"OrderingSingleOption", newOrderingQuestion(1), scoring.Answer{SelectedOptions: []uint{0}}, allOrNothing, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			assert.InDelta(t, test.ExpectedScore, scoring.ScoreQuestion(test.Question, test.Answer, test.Policy), 1e-9)
+		})
+	}
+}
+
+func TestScoreAttempt(t *testing.T) {
+	points := 3.0
+	assessment := entity.Assessment{
+		PassingScore: 50,
+		AssessmentQuestions: []entity.AssessmentQuestion{
+			{Id: 1, Snapshot: entity.QuestionSnapshot(newChoiceQuestion(entity.QuestionTypeSingleChoice, true, false))},
+			{Id: 2, Points: &points, Snapshot: entity.QuestionSnapshot(newChoiceQuestion(entity.QuestionTypeMultipleChoice, true, true, false))},
+			{Id: 3, Snapshot: entity.QuestionSnapshot(newNumericQuestion(42, 0))},
+		},
+	}
+
+	type Test struct {
+		TestName             string
+		PartialCredit        bool
+		AttemptAnswers       []entity.AttemptAnswer
+		ExpectedScore        float64
+		ExpectedPassed       bool
+		ExpectedAnswerScores map[uint]float64
+	}
+	tests := []Test{
+		{
+			TestName: "AllCorrect",
+			AttemptAnswers: []entity.AttemptAnswer{
+				{AssessmentQuestionId: 1, SelectedOptions: entity.OptionPositions{0}},
+				{AssessmentQuestionId: 2, SelectedOptions: entity.OptionPositions{0, 1}},
+				{AssessmentQuestionId: 3, NumericValue: numericValue(42)},
+			},
+			ExpectedScore:        5,
+			ExpectedPassed:       true,
+			ExpectedAnswerScores: map[uint]float64{1: 1, 2: 3, 3: 1},
+		},
+		{
+			TestName:      "PartialCredit",
+			PartialCredit: true,
+			AttemptAnswers: []entity.AttemptAnswer{
+				{AssessmentQuestionId: 2, SelectedOptions: entity.OptionPositions{0}},
+				{AssessmentQuestionId: 3, NumericValue: numericValue(41)},
+			},
+			ExpectedScore:        1.5,
+			ExpectedPassed:       false,
+			ExpectedAnswerScores: map[uint]float64{2: 1.5, 3: 0},
+		},
+		{
+			TestName:             "Unanswered",
+			ExpectedScore:        0,
+			ExpectedPassed:       false,
+			ExpectedAnswerScores: map[uint]float64{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			assessment.PartialCredit = test.PartialCredit
+			attemptScore := scoring.ScoreAttempt(assessment, test.AttemptAnswers)
+			assert.Equal(t, 5.0, attemptScore.MaxScore)
+			assert.InDelta(t, test.ExpectedScore, attemptScore.Score, 1e-9)
+			assert.Equal(t, test.ExpectedPassed, attemptScore.Passed)
+			assert.Equal(t, test.ExpectedAnswerScores, attemptScore.AnswerScores)
+		})
+	}
+}
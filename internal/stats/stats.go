@@ -0,0 +1,159 @@
+// Package stats computes psychometric statistics of questions from the running sums
+// recorded every time an attempt finishes
+package stats
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/scoring"
+	"math"
+)
+
+const (
+	// FlagNeverSelected marks options that no candidate picked
+	FlagNeverSelected = "never_selected"
+	// FlagAttractsHighScorers marks incorrect options picked by candidates scoring above average
+	FlagAttractsHighScorers = "attracts_high_scorers"
+)
+
+// EffectiveDistractorRate is the selection rate above which an incorrect option is a working distractor
+const EffectiveDistractorRate = 0.05
+
+type QuestionReport struct {
+	QuestionId       uint    `json:"questionId"`
+	QuestionRevision uint    `json:"questionRevision"`
+	Responses        uint    `json:"responses"`
+	Difficulty       float64 `json:"difficulty"` // p-value, the mean fraction of points earned. Higher is easier
+	// Discrimination is the correlation between the question score and the attempt score,
+	// unknown until scores vary
+	Discrimination *float64       `json:"discrimination"`
+	Options        []OptionReport `json:"options"`
+}
+
+type OptionReport struct {
+	Position      uint     `json:"position"`
+	Correct       bool     `json:"correct"`
+	Selections    uint     `json:"selections"`
+	SelectionRate float64  `json:"selectionRate"`
+	MeanTotal     *float64 `json:"meanTotal"` // Mean attempt score fraction of the candidates that selected it
+	Effective     *bool    `json:"effective"` // Only set for incorrect options
+	Flags         []string `json:"flags"`
+}
+
+// AttemptIncrements returns the sums an attempt adds to the stats of every question in its assessment.
+// Unanswered questions count as responses scoring zero
+func AttemptIncrements(assessment entity.Assessment, attemptAnswers []entity.AttemptAnswer, attemptScore scoring.AttemptScore) ([]entity.QuestionStats, []entity.QuestionOptionStats) {
+	var total float64
+	if attemptScore.MaxScore > 0 {
+		total = attemptScore.Score / attemptScore.MaxScore
+	}
+
+	answersByQuestion := map[uint]entity.AttemptAnswer{}
+	for _, attemptAnswer := range attemptAnswers {
+		answersByQuestion[attemptAnswer.AssessmentQuestionId] = attemptAnswer
+	}
+
+	questionStats := make([]entity.QuestionStats, 0, len(assessment.AssessmentQuestions))
+	questionOptionStats := []entity.QuestionOptionStats{}
+	for _, assessmentQuestion := range assessment.AssessmentQuestions {
+		var score float64
+		if points := assessmentQuestion.GetPoints(); points != 0 {
+			score = attemptScore.AnswerScores[assessmentQuestion.Id] / points
+		}
+		questionStats = append(questionStats, entity.QuestionStats{
+			QuestionId:       assessmentQuestion.QuestionId,
+			QuestionRevision: assessmentQuestion.QuestionRevision,
+			Responses:        1,
+			ScoreSum:         score,
+			ScoreSquaresSum:  score * score,
+			TotalSum:         total,
+			TotalSquaresSum:  total * total,
+			ScoreTotalSum:    score * total,
+		})
+
+		// Only choice options have a selection distribution, ordering questions select them all
+		question := entity.Question(assessmentQuestion.Snapshot)
+		if question.GetType() != entity.QuestionTypeSingleChoice && question.GetType() != entity.QuestionTypeMultipleChoice {
+			continue
+		}
+		selected := map[uint]bool{}
+		for _, position := range answersByQuestion[assessmentQuestion.Id].SelectedOptions {
+			selected[position] = true
+		}
+		for i, questionOption := range question.QuestionOptions {
+			optionStats := entity.QuestionOptionStats{
+				QuestionId:       assessmentQuestion.QuestionId,
+				QuestionRevision: assessmentQuestion.QuestionRevision,
+				Position:         uint(i),
+				Correct:          questionOption.Correct != nil && *questionOption.Correct,
+			}
+			if selected[uint(i)] {
+				optionStats.Selections = 1
+				optionStats.TotalSum = total
+			}
+			questionOptionStats = append(questionOptionStats, optionStats)
+		}
+	}
+
+	return questionStats, questionOptionStats
+}
+
+// Analyze computes the statistics of a question revision from its running sums
+func Analyze(questionStats entity.QuestionStats, questionOptionStats []entity.QuestionOptionStats) QuestionReport {
+	report := QuestionReport{
+		QuestionId:       questionStats.QuestionId,
+		QuestionRevision: questionStats.QuestionRevision,
+		Responses:        questionStats.Responses,
+		Options:          make([]OptionReport, len(questionOptionStats)),
+	}
+	if questionStats.Responses == 0 {
+		for i, optionStats := range questionOptionStats {
+			report.Options[i] = OptionReport{Position: optionStats.Position, Correct: optionStats.Correct, Flags: []string{}}
+		}
+		return report
+	}
+
+	n := float64(questionStats.Responses)
+	report.Difficulty = questionStats.ScoreSum / n
+	report.Discrimination = correlation(n, questionStats.ScoreSum, questionStats.ScoreSquaresSum,
+		questionStats.TotalSum, questionStats.TotalSquaresSum, questionStats.ScoreTotalSum)
+	meanTotal := questionStats.TotalSum / n
+
+	for i, optionStats := range questionOptionStats {
+		optionReport := OptionReport{
+			Position:      optionStats.Position,
+			Correct:       optionStats.Correct,
+			Selections:    optionStats.Selections,
+			SelectionRate: float64(optionStats.Selections) / n,
+			Flags:         []string{},
+		}
+		if optionStats.Selections == 0 {
+			optionReport.Flags = append(optionReport.Flags, FlagNeverSelected)
+		} else {
+			optionMeanTotal := optionStats.TotalSum / float64(optionStats.Selections)
+			optionReport.MeanTotal = &optionMeanTotal
+			if !optionStats.Correct && optionMeanTotal > meanTotal {
+				optionReport.Flags = append(optionReport.Flags, FlagAttractsHighScorers)
+			}
+		}
+		if !optionStats.Correct {
+			effective := optionReport.SelectionRate >= EffectiveDistractorRate && optionReport.MeanTotal != nil && *optionReport.MeanTotal <= meanTotal
+			optionReport.Effective = &effective
+		}
+		report.Options[i] = optionReport
+	}
+
+	return report
+}
+
+// correlation returns the Pearson correlation of x and y from their sums, or nil when either does not vary
+func correlation(n, xSum, xSquaresSum, ySum, ySquaresSum, xySum float64) *float64 {
+	xVariance := n*xSquaresSum - xSum*xSum
+	yVariance := n*ySquaresSum - ySum*ySum
+	// Rounding errors leave tiny variances when every value is the same
+	if xVariance <= 1e-9 || yVariance <= 1e-9 {
+		return nil
+	}
+	r := (n*xySum - xSum*ySum) / math.Sqrt(xVariance*yVariance)
+	r = math.Max(-1, math.Min(1, r))
+	return &r
+}
@@ -0,0 +1,146 @@
+package stats_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/scoring"
+	"challenge/internal/stats"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatsAssessment() entity.Assessment {
+	correct := true
+	incorrect := false
+	return entity.Assessment{
+		AssessmentQuestions: []entity.AssessmentQuestion{
+			{
+				Id:               10,
+				QuestionId:       1,
+				QuestionRevision: 1,
+				Snapshot: entity.QuestionSnapshot{QuestionOptions: []entity.QuestionOption{
+					{Correct: &correct}, {Correct: &incorrect}, {Correct: &incorrect}, {Correct: &incorrect},
+				}},
+			},
+			{
+				Id:               20,
+				QuestionId:       2,
+				QuestionRevision: 3,
+				Snapshot: entity.QuestionSnapshot{QuestionOptions: []entity.QuestionOption{
+					{Correct: &correct}, {Correct: &incorrect},
+				}},
+			},
+		},
+	}
+}
+
+// recordAttempts scores every attempt and sums their increments, as the repositories do
+func recordAttempts(assessment entity.Assessment, attempts [][]entity.AttemptAnswer) (map[uint]entity.QuestionStats, map[uint][]entity.QuestionOptionStats) {
+	questionStatsById := map[uint]entity.QuestionStats{}
+	questionOptionStatsById := map[uint][]entity.QuestionOptionStats{}
+	for _, attemptAnswers := range attempts {
+		attemptScore := scoring.ScoreAttempt(assessment, attemptAnswers)
+		questionStats, questionOptionStats := stats.AttemptIncrements(assessment, attemptAnswers, attemptScore)
+		for _, increment := range questionStats {
+			sum := questionStatsById[increment.QuestionId]
+			sum.QuestionId = increment.QuestionId
+			sum.QuestionRevision = increment.QuestionRevision
+			sum.Responses += increment.Responses
+			sum.ScoreSum += increment.ScoreSum
+			sum.ScoreSquaresSum += increment.ScoreSquaresSum
+			sum.TotalSum += increment.TotalSum
+			sum.TotalSquaresSum += increment.TotalSquaresSum
+			sum.ScoreTotalSum += increment.ScoreTotalSum
+			questionStatsById[increment.QuestionId] = sum
+		}
+		for _, increment := range questionOptionStats {
+			sums := questionOptionStatsById[increment.QuestionId]
+			if int(increment.Position) >= len(sums) {
+				sums = append(sums, increment)
+			} else {
+				sums[increment.Position].Selections += increment.Selections
+				sums[increment.Position].TotalSum += increment.TotalSum
+			}
+			questionOptionStatsById[increment.QuestionId] = sums
+		}
+	}
+	return questionStatsById, questionOptionStatsById
+}
+
+func TestAnalyze(t *testing.T) {
+	assessment := newStatsAssessment()
+	answer := func(questionId uint, position uint) entity.AttemptAnswer {
+		return entity.AttemptAnswer{AssessmentQuestionId: questionId, SelectedOptions: entity.OptionPositions{position}}
+	}
+	questionStatsById, questionOptionStatsById := recordAttempts(assessment, [][]entity.AttemptAnswer{
+		// Top scorers pick option 2 of the first question
+		{answer(10, 2), answer(20, 0)},
+		{answer(10, 2), answer(20, 0)},
+		{answer(10, 0), answer(20, 1)},
+		{answer(10, 1), answer(20, 1)},
+		{answer(20, 1)},
+	})
+
+	report := stats.Analyze(questionStatsById[1], questionOptionStatsById[1])
+	assert.Equal(t, uint(1), report.QuestionId)
+	assert.Equal(t, uint(1), report.QuestionRevision)
+	assert.Equal(t, uint(5), report.Responses)
+	assert.InDelta(t, 0.2, report.Difficulty, 1e-9)
+	require.NotNil(t, report.Discrimination)
+	assert.Less(t, *report.Discrimination, 0.5)
+	require.Len(t, report.Options, 4)
+
+	assert.True(t, report.Options[0].Correct)
+	assert.Equal(t, uint(1), report.Options[0].Selections)
+	assert.InDelta(t, 0.2, report.Options[0].SelectionRate, 1e-9)
+	assert.Nil(t, report.Options[0].Effective)
+	assert.Empty(t, report.Options[0].Flags)
+
+	require.NotNil(t, report.Options[1].Effective)
+	assert.True(t, *report.Options[1].Effective)
+	assert.Empty(t, report.Options[1].Flags)
+
+	assert.Equal(t, uint(2), report.Options[2].Selections)
+	require.NotNil(t, report.Options[2].Effective)
+	assert.False(t, *report.Options[2].Effective)
+	assert.Equal(t, []string{stats.FlagAttractsHighScorers}, report.Options[2].Flags)
+
+	assert.Equal(t, uint(0), report.Options[3].Selections)
+	assert.Nil(t, report.Options[3].MeanTotal)
+	require.NotNil(t, report.Options[3].Effective)
+	assert.False(t, *report.Options[3].Effective)
+	assert.Equal(t, []string{stats.FlagNeverSelected}, report.Options[3].Flags)
+
+	// Answering the second question right is what top scorers do
+	report = stats.Analyze(questionStatsById[2], questionOptionStatsById[2])
+	assert.Equal(t, uint(3), report.QuestionRevision)
+	assert.InDelta(t, 0.4, report.Difficulty, 1e-9)
+	require.NotNil(t, report.Discrimination)
+	assert.Greater(t, *report.Discrimination, 0.5)
+	assert.Empty(t, report.Options[1].Flags)
+}
+
+func TestAnalyze_NoVariance(t *testing.T) {
+	assessment := newStatsAssessment()
+	answers := []entity.AttemptAnswer{
+		{AssessmentQuestionId: 10, SelectedOptions: entity.OptionPositions{0}},
+		{AssessmentQuestionId: 20, SelectedOptions: entity.OptionPositions{0}},
+	}
+	questionStatsById, questionOptionStatsById := recordAttempts(assessment, [][]entity.AttemptAnswer{answers, answers})
+
+	report := stats.Analyze(questionStatsById[1], questionOptionStatsById[1])
+	assert.InDelta(t, 1, report.Difficulty, 1e-9)
+	assert.Nil(t, report.Discrimination)
+}
+
+func TestAnalyze_NoResponses(t *testing.T) {
+	report := stats.Analyze(
+		entity.QuestionStats{QuestionId: 1, QuestionRevision: 2},
+		[]entity.QuestionOptionStats{{QuestionId: 1, QuestionRevision: 2, Position: 0, Correct: true}},
+	)
+	assert.Equal(t, uint(0), report.Responses)
+	assert.Nil(t, report.Discrimination)
+	require.Len(t, report.Options, 1)
+	assert.Empty(t, report.Options[0].Flags)
+}
@@ -0,0 +1,253 @@
+// Package webhook posts question events to the subscribed urls, retrying failed deliveries with
+// exponential backoff until they are delivered or dead
+package webhook
+
+import (
+	"bytes"
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/pkg/env"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// Headers of the deliveries
+const (
+	HeaderEventId   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	maxBackoff        = 6 * time.Hour
+	maxLastErrorBytes = 512
+	// DispatchBatchSize is how many deliveries, or outbox events, a dispatch loads at once
+	DispatchBatchSize = 100
+)
+
+// MaxAttempts reads how many times a delivery is attempted before it is dead, 8 by default
+func MaxAttempts() uint {
+	maxAttempts, err := strconv.ParseUint(env.GetOrDefault("WEBHOOK_MAX_ATTEMPTS", "8"), 10, 32)
+	if err != nil || maxAttempts == 0 {
+		log.Warn().Err(err).Msg("Invalid WEBHOOK_MAX_ATTEMPTS, using 8")
+		return 8
+	}
+	return uint(maxAttempts)
+}
+
+// RetryBackoff reads the delay before the first retry, doubled on every retry, 30 seconds by default
+func RetryBackoff() time.Duration {
+	return env.GetDurationOrDefault("WEBHOOK_RETRY_BACKOFF", 30*time.Second)
+}
+
+// DispatchInterval reads how often due deliveries are attempted, every 5 seconds by default
+func DispatchInterval() time.Duration {
+	return env.GetDurationOrDefault("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second)
+}
+
+// Timeout reads how long receivers have to answer a delivery, 10 seconds by default
+func Timeout() time.Duration {
+	return env.GetDurationOrDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+}
+
+// LastError returns the message of a failed delivery or publication, truncated to the size kept
+func LastError(err error) string {
+	lastError := err.Error()
+	if len(lastError) > maxLastErrorBytes {
+		return lastError[:maxLastErrorBytes]
+	}
+	return lastError
+}
+
+// NewEvent returns an event of the type with the data, identified by a new ulid
+func NewEvent(eventType string, data any, now time.Time) (entity.WebhookEvent, error) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return entity.WebhookEvent{}, err
+	}
+	return entity.WebhookEvent{Id: ulid.Make().String(), Type: eventType, CreatedAt: now, Data: dataBytes}, nil
+}
+
+// Sign returns the signature header of a delivery: the hex HMAC-SHA256 of the timestamp,
+// a dot and the body, keyed by the subscription secret. The timestamp lets receivers reject replays
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the next attempt of a delivery that failed the given number of times
+func Backoff(retryBackoff time.Duration, attempts uint) time.Duration {
+	backoff := retryBackoff
+	for i := uint(1); i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+type DispatchResult struct {
+	Delivered uint `json:"delivered"`
+	Retried   uint `json:"retried"` // Failed and scheduled for a later attempt
+	Dead      uint `json:"dead"`    // Failed their last attempt
+}
+
+// Dispatcher posts the due deliveries to their subscription
+type Dispatcher struct {
+	webhookDeliveryRepository repository.WebhookDeliveryRepository
+	client                    *http.Client
+	maxAttempts               uint
+	retryBackoff              time.Duration
+}
+
+func NewDispatcher(
+	webhookDeliveryRepository repository.WebhookDeliveryRepository,
+	client *http.Client,
+	maxAttempts uint,
+	retryBackoff time.Duration,
+) *Dispatcher {
+	return &Dispatcher{
+		webhookDeliveryRepository: webhookDeliveryRepository,
+		client:                    client,
+		maxAttempts:               maxAttempts,
+		retryBackoff:              retryBackoff,
+	}
+}
+
+// Dispatch attempts every delivery due at the given time
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) (DispatchResult, error) {
+	var result DispatchResult
+	for {
+		webhookDeliveries, err := d.webhookDeliveryRepository.ListDueWebhookDeliveries(ctx, now, DispatchBatchSize)
+		if err != nil {
+			return result, err
+		}
+		for i := range webhookDeliveries {
+			webhookDelivery := &webhookDeliveries[i]
+			d.attempt(ctx, webhookDelivery, now)
+			err := d.webhookDeliveryRepository.UpdateWebhookDelivery(ctx, webhookDelivery)
+			if err != nil {
+				return result, err
+			}
+
+			switch webhookDelivery.Status {
+			case entity.WebhookDeliveryDelivered:
+				result.Delivered++
+			case entity.WebhookDeliveryDead:
+				result.Dead++
+			default:
+				result.Retried++
+			}
+		}
+		// Failed deliveries are due later, so the next page only has deliveries not attempted yet
+		if len(webhookDeliveries) < DispatchBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// attempt posts the delivery and records the outcome on it
+func (d *Dispatcher) attempt(ctx context.Context, webhookDelivery *entity.WebhookDelivery, now time.Time) {
+	webhookDelivery.Attempts++
+	webhookDelivery.LastAttemptAt = &now
+
+	statusCode, err := d.post(ctx, webhookDelivery, now)
+	webhookDelivery.LastStatusCode = statusCode
+	if err == nil {
+		webhookDelivery.Status = entity.WebhookDeliveryDelivered
+		webhookDelivery.DeliveredAt = &now
+		webhookDelivery.LastError = ""
+		return
+	}
+
+	webhookDelivery.LastError = LastError(err)
+	if webhookDelivery.Attempts >= d.maxAttempts {
+		webhookDelivery.Status = entity.WebhookDeliveryDead
+		return
+	}
+	webhookDelivery.NextAttemptAt = now.Add(Backoff(d.retryBackoff, webhookDelivery.Attempts))
+}
+
+// post sends the delivery, any status other than 2xx is a failure
+func (d *Dispatcher) post(ctx context.Context, webhookDelivery *entity.WebhookDelivery, now time.Time) (int, error) {
+	// The subscription was deleted since the delivery was listed
+	if webhookDelivery.WebhookSubscription == nil {
+		return 0, fmt.Errorf("webhook %d not found", webhookDelivery.WebhookSubscriptionId)
+	}
+
+	body := []byte(webhookDelivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookDelivery.WebhookSubscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventId, webhookDelivery.EventId)
+	req.Header.Set(HeaderEventType, webhookDelivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhookDelivery.WebhookSubscription.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Drain a bit of the body so the connection can be reused
+	_, _ = io.CopyN(io.Discard, res.Body, 4096)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Run dispatches the due deliveries every interval until the context is done
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := d.Dispatch(ctx, now.UTC())
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to dispatch webhook deliveries")
+				continue
+			}
+			if result.Delivered > 0 || result.Retried > 0 || result.Dead > 0 {
+				log.Info().
+					Uint("delivered", result.Delivered).
+					Uint("retried", result.Retried).
+					Uint("dead", result.Dead).
+					Msg("Dispatched webhook deliveries")
+			}
+		}
+	}
+}
+
+// QuestionDeleted is the data of question.deleted events
+type QuestionDeleted struct {
+	Id uint `json:"id"`
+}
+
+// QuestionPublished is the data of question.published events, the question is the snapshot taken by the assessment
+type QuestionPublished struct {
+	AssessmentId uint            `json:"assessmentId"`
+	Question     entity.Question `json:"question"`
+}
@@ -0,0 +1,93 @@
+package webhook_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/repository"
+	"challenge/internal/webhook"
+	"challenge/pkg/gormprovider"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhook.Backoff(30*time.Second, 1))
+	assert.Equal(t, time.Minute, webhook.Backoff(30*time.Second, 2))
+	assert.Equal(t, 4*time.Minute, webhook.Backoff(30*time.Second, 4))
+	assert.Equal(t, 6*time.Hour, webhook.Backoff(30*time.Second, 30))
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	databaseInitSql, err := os.ReadFile("../../database_init.sql")
+	require.NoError(t, err)
+	sqlProvider := gormprovider.NewTestSQLiteProvider(t, string(databaseInitSql))
+	webhookSubscriptionRepo := repository.NewWebhookSubscriptionRepository(sqlProvider)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(sqlProvider)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	status := http.StatusInternalServerError
+	requests := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, webhook.Sign("0123456789abcdef", timestamp, body), r.Header.Get(webhook.HeaderSignature))
+		assert.Equal(t, entity.WebhookEventQuestionCreated, r.Header.Get(webhook.HeaderEventType))
+		assert.NotEmpty(t, r.Header.Get(webhook.HeaderEventId))
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	err = webhookSubscriptionRepo.CreateWebhookSubscription(ctx, &entity.WebhookSubscription{
+		Url:       receiver.URL,
+		Events:    entity.WebhookEventTypes{entity.WebhookEventQuestionCreated},
+		Secret:    "0123456789abcdef",
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+	event, err := webhook.NewEvent(entity.WebhookEventQuestionCreated, entity.Question{Id: 1}, now)
+	require.NoError(t, err)
+	require.NoError(t, webhookDeliveryRepo.EnqueueWebhookEvent(ctx, event))
+
+	dispatcher := webhook.NewDispatcher(webhookDeliveryRepo, receiver.Client(), 2, time.Minute)
+
+	// The first failure is retried after the backoff
+	result, err := dispatcher.Dispatch(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DispatchResult{Retried: 1}, result)
+	result, err = dispatcher.Dispatch(ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DispatchResult{}, result)
+
+	// The last failure is a dead letter
+	result, err = dispatcher.Dispatch(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DispatchResult{Dead: 1}, result)
+	webhookDeliveries, err := webhookDeliveryRepo.ListWebhookDeliveries(ctx, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, webhookDeliveries, 1)
+	assert.Equal(t, entity.WebhookDeliveryDead, webhookDeliveries[0].Status)
+	assert.Equal(t, http.StatusInternalServerError, webhookDeliveries[0].LastStatusCode)
+	assert.Equal(t, uint(2), webhookDeliveries[0].Attempts)
+
+	// Retried dead letters are delivered on the next dispatch
+	retried, err := webhookDeliveryRepo.RetryWebhookDelivery(ctx, webhookDeliveries[0].Id, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, retried)
+	status = http.StatusNoContent
+	result, err = dispatcher.Dispatch(ctx, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DispatchResult{Delivered: 1}, result)
+	assert.Equal(t, 3, requests)
+}
@@ -44,6 +44,29 @@ func (_m *QuestionOptionRepository) BulkReplaceQuestionOptions(ctx context.Conte
 	return r0
 }
 
+// ListQuestionOptions provides a mock function with given fields: ctx, questionIds
+func (_m *QuestionOptionRepository) ListQuestionOptions(ctx context.Context, questionIds []uint) ([]entity.QuestionOption, error) {
+	ret := _m.Called(ctx, questionIds)
+
+	var r0 []entity.QuestionOption
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) []entity.QuestionOption); ok {
+		r0 = rf(ctx, questionIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.QuestionOption)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []uint) error); ok {
+		r1 = rf(ctx, questionIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewQuery provides a mock function with given fields: ctx
 func (_m *QuestionOptionRepository) NewQuery(ctx context.Context) *gorm.DB {
 	ret := _m.Called(ctx)
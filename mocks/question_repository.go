@@ -4,12 +4,12 @@ package mocks
 
 import (
 	entity "challenge/internal/entity"
+	gormprovider "challenge/pkg/gormprovider"
+
 	context "context"
 
 	gorm "gorm.io/gorm"
 
-	gormprovider "challenge/pkg/gormprovider"
-
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -46,20 +46,27 @@ func (_m *QuestionRepository) DeleteQuestion(ctx context.Context, id uint) error
 	return r0
 }
 
-// GetQuestion provides a mock function with given fields: ctx, id
-func (_m *QuestionRepository) GetQuestion(ctx context.Context, id uint) (entity.Question, error) {
-	ret := _m.Called(ctx, id)
+// GetQuestion provides a mock function with given fields: ctx, id, opts
+func (_m *QuestionRepository) GetQuestion(ctx context.Context, id uint, opts ...gormprovider.Option) (entity.Question, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 entity.Question
-	if rf, ok := ret.Get(0).(func(context.Context, uint) entity.Question); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, ...gormprovider.Option) entity.Question); ok {
+		r0 = rf(ctx, id, opts...)
 	} else {
 		r0 = ret.Get(0).(entity.Question)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, ...gormprovider.Option) error); ok {
+		r1 = rf(ctx, id, opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
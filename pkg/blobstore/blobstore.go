@@ -0,0 +1,47 @@
+package blobstore
+
+import (
+	"challenge/pkg/env"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps the content of binary objects under opaque keys
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound when there is no blob with the key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete does not fail when there is no blob with the key
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context) ([]Blob, error)
+}
+
+type Blob struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// NewStoreFromEnv builds the store selected by BLOB_STORE, filesystem by default
+func NewStoreFromEnv() (Store, error) {
+	switch storeType := env.GetOrDefault("BLOB_STORE", "filesystem"); storeType {
+	case "filesystem":
+		return NewFileSystemStore(env.GetOrDefault("BLOB_STORE_DIR", "attachments"))
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        env.GetOrDefault("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          env.GetOrDefault("S3_REGION", "us-east-1"),
+			Bucket:          env.GetOrDefault("S3_BUCKET", ""),
+			AccessKeyId:     env.GetOrDefault("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: env.GetOrDefault("S3_SECRET_ACCESS_KEY", ""),
+			VirtualHosted:   env.GetOrDefault("S3_VIRTUAL_HOSTED", "false") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", storeType)
+	}
+}
@@ -0,0 +1,259 @@
+// Package client is a typed client of the questions API served by httpserver. It adds the JWT of the caller to every
+// request, retries the idempotent requests that fail with a network error or a transient status and decodes the
+// error responses into Error and ValidationError
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries      = 3
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultMaxRetryBackoff = 5 * time.Second
+)
+
+type Config struct {
+	// BaseURL is the URL the API is served at, like http://localhost:3000
+	BaseURL string
+	// Token is the JWT sent as a bearer token, requests are anonymous without one
+	Token string
+	// TokenSource returns the JWT of each request and takes precedence over Token, for tokens that expire
+	TokenSource func(ctx context.Context) (string, error)
+	// MaxRetries is how many times an idempotent request is retried, 3 by default and none when negative
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every other retry up to MaxRetryBackoff.
+	// 100ms and 5s by default
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+type Client struct {
+	config  Config
+	baseURL *url.URL
+}
+
+func New(config Config) (*Client, error) {
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q", config.BaseURL)
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultRetryBackoff
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Client{config: config, baseURL: baseURL}, nil
+}
+
+// request is a call of the API. Its body is kept in memory, unless it is a reader, so that it can be sent again
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any
+	contentType string
+	idempotent  bool
+}
+
+// do sends the request until it gets a response that isn't worth retrying and returns it with an error for error
+// statuses. The caller closes the body of the response
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	bodyReader, streamed := req.body.(io.Reader)
+	if req.body != nil && !streamed {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		if req.contentType == "" {
+			req.contentType = "application/json"
+		}
+	}
+
+	maxRetries := c.config.MaxRetries
+	// Streamed bodies are consumed by the first attempt
+	if !req.idempotent || streamed || maxRetries < 0 {
+		maxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		httpReq, err := c.newRequest(ctx, req, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.config.HTTPClient.Do(httpReq)
+		if err == nil && res.StatusCode < 300 {
+			return res, nil
+		}
+		if attempt >= maxRetries || !isRetryable(res, err) {
+			if err != nil {
+				return nil, err
+			}
+			return nil, newResponseError(res)
+		}
+		backoff := c.backoff(attempt)
+		if res != nil {
+			backoff = c.retryAfter(res, backoff)
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doJSON sends the request and decodes the JSON of its response into res, when not nil
+func (c *Client) doJSON(ctx context.Context, req request, res any) error {
+	httpRes, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+	if res == nil {
+		return nil
+	}
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doReport sends a request the API answers with the same report on success and on 422, and decodes the report
+// in both cases
+func (c *Client) doReport(ctx context.Context, req request, res any) error {
+	httpRes, err := c.do(ctx, req)
+	var resErr *Error
+	if errors.As(err, &resErr) && resErr.StatusCode == http.StatusUnprocessableEntity && len(resErr.Body) > 0 {
+		if decodeErr := json.Unmarshal(resErr.Body, res); decodeErr != nil {
+			return fmt.Errorf("failed to decode response: %w", decodeErr)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, req request, body io.Reader) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + req.path
+	u.RawQuery = req.query.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+
+	token := c.config.Token
+	if c.config.TokenSource != nil {
+		token, err = c.config.TokenSource(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return httpReq, nil
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	backoff := c.config.RetryBackoff
+	for i := 0; i < attempt && backoff < c.config.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > c.config.MaxRetryBackoff {
+		return c.config.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// isRetryable tells whether a request failing with the response or the error may succeed when sent again
+func isRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		// The context of the request is done, sending it again fails the same way
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads the delay of the Retry-After header in seconds, up to MaxRetryBackoff. The backoff is kept when
+// it is longer
+func (c *Client) retryAfter(res *http.Response, backoff time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil {
+		return backoff
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > c.config.MaxRetryBackoff {
+		delay = c.config.MaxRetryBackoff
+	}
+	if delay < backoff {
+		return backoff
+	}
+	return delay
+}
+
+// newIdempotencyKey returns a random key, so that the retries of a creation replay its response
+func newIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func pathId(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,447 @@
+package client_test
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/httpserver"
+	"challenge/mocks"
+	"challenge/pkg/client"
+	"challenge/pkg/gormprovider"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newBaseURL serves the API of the repositories on a random port
+func newBaseURL(t *testing.T, repositories httpserver.Repositories) string {
+	server := httpserver.NewServer(repositories, nil, nil)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Listener(listener)
+	t.Cleanup(func() {
+		server.Shutdown()
+	})
+	return "http://" + listener.Addr().String()
+}
+
+func newToken(t *testing.T, userId string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userId}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func newClient(t *testing.T, config client.Config) *client.Client {
+	c, err := client.New(config)
+	require.NoError(t, err)
+	return c
+}
+
+// flakyTransport answers the first requests with 503 after the API handled them, like a proxy losing the responses
+type flakyTransport struct {
+	failures int
+	requests int
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests++
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || f.failures == 0 {
+		return res, err
+	}
+	f.failures--
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestNew(t *testing.T) {
+	_, err := client.New(client.Config{BaseURL: "localhost:3000"})
+	assert.Error(t, err)
+	_, err = client.New(client.Config{BaseURL: "http://localhost:3000"})
+	assert.NoError(t, err)
+}
+
+func TestIterateQuestions(t *testing.T) {
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("ListQuestions", mock.Anything, uint(2), mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(_ context.Context, pageSize uint, lastId *uint, _ ...gormprovider.Option) []entity.Question {
+			var questions []entity.Question
+			id := uint(1)
+			if lastId != nil {
+				id = *lastId + 1
+			}
+			for ; id <= 5 && uint(len(questions)) < pageSize; id++ {
+				questions = append(questions, entity.Question{Id: id, Body: "question"})
+			}
+			return questions
+		}, nil)
+	c := newClient(t, client.Config{BaseURL: newBaseURL(t, httpserver.Repositories{Question: questionRepository})})
+
+	questions, err := c.ListAllQuestions(context.Background(), client.ListQuestionsRequest{PageSize: 2})
+	require.NoError(t, err)
+	ids := []uint{}
+	for _, question := range questions {
+		ids = append(ids, question.Id)
+	}
+	assert.Equal(t, []uint{1, 2, 3, 4, 5}, ids)
+	// The last page is shorter than the page size, so it isn't followed by an empty one
+	questionRepository.AssertNumberOfCalls(t, "ListQuestions", 3)
+
+	lastId := uint(4)
+	it := c.IterateQuestions(client.ListQuestionsRequest{PageSize: 2, LastId: &lastId})
+	require.True(t, it.Next(context.Background()))
+	assert.Equal(t, uint(5), it.Question().Id)
+	assert.False(t, it.Next(context.Background()))
+	assert.NoError(t, it.Err())
+}
+
+func TestIterateQuestionsError(t *testing.T) {
+	c := newClient(t, client.Config{BaseURL: newBaseURL(t, httpserver.Repositories{})})
+
+	it := c.IterateQuestions(client.ListQuestionsRequest{PageSize: 2000})
+	assert.False(t, it.Next(context.Background()))
+	var validationErr *client.ValidationError
+	require.ErrorAs(t, it.Err(), &validationErr)
+	assert.Equal(t, http.StatusBadRequest, validationErr.StatusCode)
+	assert.Equal(t, map[string][]string{"ListQuestionsRequest.PageSize": {"max"}}, validationErr.Errors)
+}
+
+func TestCreateQuestion(t *testing.T) {
+	storedKeys := map[string]entity.IdempotencyKey{}
+	idempotencyKeyRepository := mocks.NewIdempotencyKeyRepository(t)
+	idempotencyKeyRepository.On("GetIdempotencyKey", mock.Anything, uint(1), mock.Anything, mock.Anything).
+		Return(
+			func(_ context.Context, _ uint, key string, _ time.Time) entity.IdempotencyKey {
+				return storedKeys[key]
+			},
+			func(_ context.Context, _ uint, key string, _ time.Time) error {
+				if _, found := storedKeys[key]; !found {
+					return gorm.ErrRecordNotFound
+				}
+				return nil
+			},
+		).Maybe()
+	idempotencyKeyRepository.On("SaveIdempotencyKey", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, idempotencyKey *entity.IdempotencyKey) bool {
+			storedKeys[idempotencyKey.Key] = *idempotencyKey
+			return true
+		}, nil).Maybe()
+	var createdQuestions uint
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).Maybe()
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			createdQuestions++
+			question.Id = createdQuestions
+			return nil
+		}).Maybe()
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	auditEntryRepository := mocks.NewAuditEntryRepository(t)
+	auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil).Maybe()
+	outboxRepository := mocks.NewOutboxRepository(t)
+	outboxRepository.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	baseURL := newBaseURL(t, httpserver.Repositories{
+		Question:       questionRepository,
+		QuestionOption: questionOptionRepository,
+		QuestionTag:    questionTagRepository,
+		IdempotencyKey: idempotencyKeyRepository,
+		Outbox:         outboxRepository,
+		AuditEntry:     auditEntryRepository,
+	})
+	correct := true
+	incorrect := false
+	question := client.Question{
+		Body: "Which?",
+		QuestionOptions: []client.QuestionOption{
+			{Body: "this", Correct: &correct},
+			{Body: "that", Correct: &incorrect},
+		},
+	}
+
+	type Test struct {
+		TestName              string
+		Token                 string
+		TokenSource           func(ctx context.Context) (string, error)
+		Question              client.Question
+		Failures              int
+		ExpectedQuestionId    uint
+		ExpectedErr           error
+		ExpectedValidationErr *client.ValidationError
+		ExpectedRequests      int
+	}
+	tests := []Test{
+		{
+			TestName:           "Create",
+			Token:              newToken(t, "1"),
+			Question:           question,
+			ExpectedQuestionId: 1,
+			ExpectedRequests:   1,
+		},
+		{
+			TestName: "TokenSource",
+			Token:    "expired",
+			TokenSource: func(ctx context.Context) (string, error) {
+				return newToken(t, "1"), nil
+			},
+			Question:           question,
+			ExpectedQuestionId: 2,
+			ExpectedRequests:   1,
+		},
+		{
+			TestName:           "RetryIsReplayed",
+			Token:              newToken(t, "1"),
+			Question:           question,
+			Failures:           2,
+			ExpectedQuestionId: 3,
+			ExpectedRequests:   3,
+		},
+		{
+			TestName:         "TooManyFailures",
+			Token:            newToken(t, "1"),
+			Question:         question,
+			Failures:         4,
+			ExpectedErr:      &client.Error{StatusCode: http.StatusServiceUnavailable, Message: "Service Unavailable", Body: []byte{}},
+			ExpectedRequests: 4,
+		},
+		{
+			TestName:         "Anonymous",
+			Question:         question,
+			ExpectedErr:      &client.Error{StatusCode: http.StatusBadRequest, Message: "Missing or malformed JWT", Body: []byte("Missing or malformed JWT")},
+			ExpectedRequests: 1,
+		},
+		{
+			TestName:         "InvalidToken",
+			Token:            "invalid",
+			Question:         question,
+			ExpectedErr:      &client.Error{StatusCode: http.StatusUnauthorized, Message: "Invalid or expired JWT", Body: []byte("Invalid or expired JWT")},
+			ExpectedRequests: 1,
+		},
+		{
+			TestName: "Invalid",
+			Token:    newToken(t, "1"),
+			Question: client.Question{Difficulty: "impossible"},
+			ExpectedValidationErr: &client.ValidationError{StatusCode: http.StatusBadRequest, Errors: map[string][]string{
+				"Question.Body":            {"required"},
+				"Question.Difficulty":      {"oneof"},
+				"Question.QuestionOptions": {"required_unless"},
+			}},
+			ExpectedRequests: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			transport := &flakyTransport{failures: test.Failures}
+			c := newClient(t, client.Config{
+				BaseURL:      baseURL,
+				Token:        test.Token,
+				TokenSource:  test.TokenSource,
+				RetryBackoff: time.Millisecond,
+				HTTPClient:   &http.Client{Transport: transport},
+			})
+
+			created, err := c.CreateQuestion(context.Background(), test.Question)
+			assert.Equal(t, test.ExpectedRequests, transport.requests)
+			if test.ExpectedValidationErr != nil {
+				var validationErr *client.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, test.ExpectedValidationErr, validationErr)
+				return
+			}
+			if test.ExpectedErr != nil {
+				var resErr *client.Error
+				require.ErrorAs(t, err, &resErr)
+				assert.Equal(t, test.ExpectedErr, resErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedQuestionId, created.Id)
+		})
+	}
+	// Every question was created once, the retries were replayed
+	assert.Equal(t, uint(4), createdQuestions)
+}
+
+func TestRetries(t *testing.T) {
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything).
+		Return(entity.Question{}, gorm.ErrRecordNotFound)
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything, mock.Anything).
+		Return(entity.Question{}, gorm.ErrRecordNotFound)
+	baseURL := newBaseURL(t, httpserver.Repositories{Question: questionRepository})
+
+	type Test struct {
+		TestName         string
+		MaxRetries       int
+		Call             func(c *client.Client) error
+		ExpectedStatus   int
+		ExpectedRequests int
+	}
+	tests := []Test{
+		{
+			TestName: "GetIsRetried",
+			Call: func(c *client.Client) error {
+				_, err := c.ListQuestionOptions(context.Background(), 1, client.ReadOptions{})
+				return err
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedRequests: 2,
+		},
+		{
+			TestName:   "RetriesDisabled",
+			MaxRetries: -1,
+			Call: func(c *client.Client) error {
+				_, err := c.ListQuestionOptions(context.Background(), 1, client.ReadOptions{})
+				return err
+			},
+			ExpectedStatus:   http.StatusServiceUnavailable,
+			ExpectedRequests: 1,
+		},
+		{
+			TestName: "PostIsNotRetried",
+			Call: func(c *client.Client) error {
+				_, err := c.CreateQuestionOption(context.Background(), 1, client.CreateQuestionOptionRequest{Body: "option"})
+				return err
+			},
+			ExpectedStatus:   http.StatusServiceUnavailable,
+			ExpectedRequests: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			transport := &flakyTransport{failures: 1}
+			c := newClient(t, client.Config{
+				BaseURL:      baseURL,
+				Token:        newToken(t, "1"),
+				MaxRetries:   test.MaxRetries,
+				RetryBackoff: time.Millisecond,
+				HTTPClient:   &http.Client{Transport: transport},
+			})
+
+			err := test.Call(c)
+			var resErr *client.Error
+			require.ErrorAs(t, err, &resErr)
+			assert.Equal(t, test.ExpectedStatus, resErr.StatusCode)
+			assert.Equal(t, test.ExpectedRequests, transport.requests)
+		})
+	}
+}
+
+func TestRetriesStopWithContext(t *testing.T) {
+	c := newClient(t, client.Config{
+		BaseURL:      newBaseURL(t, httpserver.Repositories{}),
+		RetryBackoff: time.Hour,
+		HTTPClient:   &http.Client{Transport: &flakyTransport{failures: 1}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.ListQuestionTranslations(ctx, 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestBatchQuestions(t *testing.T) {
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).Maybe()
+	questionRepository.On("GetQuestion", mock.Anything, uint(1), mock.Anything).
+		Return(entity.Question{}, gorm.ErrRecordNotFound).Maybe()
+	c := newClient(t, client.Config{
+		BaseURL: newBaseURL(t, httpserver.Repositories{Question: questionRepository, AuditEntry: mocks.NewAuditEntryRepository(t)}),
+		Token:   newToken(t, "1"),
+	})
+
+	res, err := c.BatchQuestions(context.Background(), client.BatchQuestionsRequest{
+		Operations: []client.BatchOperation{
+			{Op: client.BatchOpUpdate, Id: 1, Question: &client.Question{Body: "question"}},
+		},
+	})
+	var resErr *client.Error
+	require.ErrorAs(t, err, &resErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, resErr.StatusCode)
+	require.Len(t, res.Results, 1)
+	assert.Equal(t, http.StatusBadRequest, res.Results[0].Status)
+
+	var validationErr *client.ValidationError
+	require.ErrorAs(t, res.Results[0].Err(), &validationErr)
+	assert.Contains(t, validationErr.Errors, "Question.QuestionOptions")
+}
+
+func TestStreamQuestionEvents(t *testing.T) {
+	// The server notices the closed stream on the next heartbeat
+	t.Setenv("QUESTION_EVENTS_HEARTBEAT", "50ms")
+	questionRepository := mocks.NewQuestionRepository(t)
+	questionRepository.On("RunInTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	questionRepository.On("CreateQuestion", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, question *entity.Question) error {
+			question.Id = 1
+			return nil
+		})
+	questionOptionRepository := mocks.NewQuestionOptionRepository(t)
+	questionOptionRepository.On("BulkCreateQuestionOptions", mock.Anything, uint(1), mock.Anything).Return(nil)
+	questionTagRepository := mocks.NewQuestionTagRepository(t)
+	questionTagRepository.On("BulkCreateQuestionTags", mock.Anything, uint(1), mock.Anything).Return(nil)
+	outboxRepository := mocks.NewOutboxRepository(t)
+	outboxRepository.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(nil)
+	auditEntryRepository := mocks.NewAuditEntryRepository(t)
+	auditEntryRepository.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil)
+	c := newClient(t, client.Config{
+		BaseURL: newBaseURL(t, httpserver.Repositories{
+			Question:       questionRepository,
+			QuestionOption: questionOptionRepository,
+			QuestionTag:    questionTagRepository,
+			Outbox:         outboxRepository,
+			AuditEntry:     auditEntryRepository,
+		}),
+		Token: newToken(t, "1"),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := c.StreamQuestionEvents(ctx, client.StreamQuestionEventsRequest{AuthorId: 1})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	correct := true
+	incorrect := false
+	_, err = c.CreateQuestionWithIdempotencyKey(ctx, client.Question{
+		Body: "Which?",
+		QuestionOptions: []client.QuestionOption{
+			{Body: "this", Correct: &correct},
+			{Body: "that", Correct: &incorrect},
+		},
+	}, "")
+	require.NoError(t, err)
+
+	event, err := stream.Next()
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.Id)
+	assert.Equal(t, "question.created", event.Type)
+	assert.Contains(t, string(event.Data), `"body":"Which?"`)
+}
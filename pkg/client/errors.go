@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxErrorBodySize bounds how much of an error response is read
+const maxErrorBodySize = 1 << 20
+
+// Error is a response with an error status, with the message of its ErrorResponse or its plain text body
+type Error struct {
+	StatusCode int
+	Message    string
+	// Body is the raw body of the response, for the errors answered with a report
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("questions api: %d %s", e.StatusCode, e.Message)
+}
+
+// ValidationError is a response with the errors of the fields of the request, from its ValidationErrorResponse.
+// Errors are keyed by the namespace of the field, like Question.Body, and list the failed rules, like required
+type ValidationError struct {
+	StatusCode int
+	Errors     map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field, rules := range e.Errors {
+		fields = append(fields, fmt.Sprintf("%s: %s", field, strings.Join(rules, " ")))
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("questions api: %d invalid request: %s", e.StatusCode, strings.Join(fields, ", "))
+}
+
+// errorResponse decodes both the ErrorResponse and the ValidationErrorResponse of httpserver
+type errorResponse struct {
+	Error  string
+	Errors map[string][]string
+}
+
+// newResponseError reads and closes the body of the error response and returns its typed error
+func newResponseError(res *http.Response) error {
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	if err != nil {
+		return fmt.Errorf("questions api: %d: failed to read response: %w", res.StatusCode, err)
+	}
+
+	var errRes errorResponse
+	if json.Unmarshal(body, &errRes) == nil {
+		if len(errRes.Errors) > 0 {
+			return &ValidationError{StatusCode: res.StatusCode, Errors: errRes.Errors}
+		}
+		if errRes.Error != "" {
+			return &Error{StatusCode: res.StatusCode, Message: errRes.Error, Body: body}
+		}
+		return &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode), Body: body}
+	}
+	// The JWT middleware answers in plain text
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(res.StatusCode)
+	}
+	return &Error{StatusCode: res.StatusCode, Message: message, Body: body}
+}
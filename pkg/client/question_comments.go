@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func questionCommentsPath(questionId uint) string {
+	return "/questions/" + pathId(questionId) + "/comments"
+}
+
+// ListQuestionComments returns the comment threads of a question, for its author and reviewers
+func (c *Client) ListQuestionComments(ctx context.Context, questionId uint) ([]QuestionComment, error) {
+	var questionComments []QuestionComment
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       questionCommentsPath(questionId),
+		idempotent: true,
+	}, &questionComments)
+	return questionComments, err
+}
+
+func (c *Client) CreateQuestionComment(ctx context.Context, questionId uint, req CreateQuestionCommentRequest) (QuestionComment, error) {
+	var questionComment QuestionComment
+	err := c.doJSON(ctx, request{
+		method: http.MethodPost,
+		path:   questionCommentsPath(questionId),
+		body:   req,
+	}, &questionComment)
+	return questionComment, err
+}
+
+// UpdateQuestionComment edits the body of a comment of the caller
+func (c *Client) UpdateQuestionComment(ctx context.Context, questionId uint, commentId uint, body string) (QuestionComment, error) {
+	var questionComment QuestionComment
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPut,
+		path:       questionCommentsPath(questionId) + "/" + pathId(commentId),
+		body:       map[string]string{"body": body},
+		idempotent: true,
+	}, &questionComment)
+	return questionComment, err
+}
+
+// DeleteQuestionComment deletes a comment of the caller with its replies
+func (c *Client) DeleteQuestionComment(ctx context.Context, questionId uint, commentId uint) error {
+	return c.doJSON(ctx, request{
+		method:     http.MethodDelete,
+		path:       questionCommentsPath(questionId) + "/" + pathId(commentId),
+		idempotent: true,
+	}, nil)
+}
+
+func (c *Client) ResolveQuestionComment(ctx context.Context, questionId uint, commentId uint) (QuestionComment, error) {
+	var questionComment QuestionComment
+	err := c.doJSON(ctx, request{
+		method: http.MethodPost,
+		path:   questionCommentsPath(questionId) + "/" + pathId(commentId) + "/resolve",
+	}, &questionComment)
+	return questionComment, err
+}
+
+func (c *Client) UnresolveQuestionComment(ctx context.Context, questionId uint, commentId uint) (QuestionComment, error) {
+	var questionComment QuestionComment
+	err := c.doJSON(ctx, request{
+		method: http.MethodPost,
+		path:   questionCommentsPath(questionId) + "/" + pathId(commentId) + "/unresolve",
+	}, &questionComment)
+	return questionComment, err
+}
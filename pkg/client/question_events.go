@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxEventSize bounds the lines of the events, the data of an event is a whole question
+const maxEventSize = 16 << 20
+
+// QuestionEvent is a question change, with the type and payload of the webhook events
+type QuestionEvent struct {
+	Id   string
+	Type string
+	Data json.RawMessage
+}
+
+// QuestionEventStream reads the server-sent events of the question changes
+type QuestionEventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// StreamQuestionEvents opens the stream of the question changes matching the request, the caller closes it
+func (c *Client) StreamQuestionEvents(ctx context.Context, req StreamQuestionEventsRequest) (*QuestionEventStream, error) {
+	query := url.Values{}
+	if req.AuthorId != 0 {
+		query.Set("authorId", pathId(req.AuthorId))
+	}
+	if len(req.Tags) > 0 {
+		query.Set("tags", strings.Join(req.Tags, ","))
+	}
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if req.LastEventId != "" {
+		header.Set("Last-Event-ID", req.LastEventId)
+	}
+
+	res, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/questions/events",
+		query:      query,
+		header:     header,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxEventSize)
+	return &QuestionEventStream{body: res.Body, scanner: scanner}, nil
+}
+
+// Next blocks until the next event and returns io.EOF once the stream ends. The id of the last event received
+// resumes a new stream after it
+func (s *QuestionEventStream) Next() (QuestionEvent, error) {
+	var event QuestionEvent
+	var data []string
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			// Comments, like the heartbeats, end without data
+			if data == nil {
+				continue
+			}
+			event.Data = json.RawMessage(strings.Join(data, "\n"))
+			return event, nil
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.Id = value
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return QuestionEvent{}, err
+	}
+	return QuestionEvent{}, io.EOF
+}
+
+func (s *QuestionEventStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func questionOptionsPath(questionId uint) string {
+	return "/questions/" + pathId(questionId) + "/options"
+}
+
+// ListQuestionOptions returns the options of a question in order
+func (c *Client) ListQuestionOptions(ctx context.Context, questionId uint, opts ReadOptions) ([]QuestionOption, error) {
+	var questionOptions []QuestionOption
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       questionOptionsPath(questionId),
+		query:      readQuery(opts),
+		idempotent: true,
+	}, &questionOptions)
+	return questionOptions, err
+}
+
+// CreateQuestionOption adds an option to a question of the caller, at the end unless req.Position is set
+func (c *Client) CreateQuestionOption(ctx context.Context, questionId uint, req CreateQuestionOptionRequest) (QuestionOption, error) {
+	var questionOption QuestionOption
+	err := c.doJSON(ctx, request{
+		method: http.MethodPost,
+		path:   questionOptionsPath(questionId),
+		body:   req,
+	}, &questionOption)
+	return questionOption, err
+}
+
+func (c *Client) UpdateQuestionOption(ctx context.Context, questionId uint, optionId uint, req UpdateQuestionOptionRequest) (QuestionOption, error) {
+	var questionOption QuestionOption
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPut,
+		path:       questionOptionsPath(questionId) + "/" + pathId(optionId),
+		body:       req,
+		idempotent: true,
+	}, &questionOption)
+	return questionOption, err
+}
+
+func (c *Client) DeleteQuestionOption(ctx context.Context, questionId uint, optionId uint) error {
+	return c.doJSON(ctx, request{
+		method:     http.MethodDelete,
+		path:       questionOptionsPath(questionId) + "/" + pathId(optionId),
+		idempotent: true,
+	}, nil)
+}
+
+// MoveQuestionOption moves an option to the position and returns the options in their new order
+func (c *Client) MoveQuestionOption(ctx context.Context, questionId uint, optionId uint, position uint) ([]QuestionOption, error) {
+	var questionOptions []QuestionOption
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPut,
+		path:       questionOptionsPath(questionId) + "/" + pathId(optionId) + "/position",
+		body:       map[string]uint{"position": position},
+		idempotent: true,
+	}, &questionOptions)
+	return questionOptions, err
+}
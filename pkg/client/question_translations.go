@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func questionTranslationsPath(questionId uint) string {
+	return "/questions/" + pathId(questionId) + "/translations"
+}
+
+func (c *Client) ListQuestionTranslations(ctx context.Context, questionId uint) ([]QuestionTranslation, error) {
+	var questionTranslations []QuestionTranslation
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       questionTranslationsPath(questionId),
+		idempotent: true,
+	}, &questionTranslations)
+	return questionTranslations, err
+}
+
+// SaveQuestionTranslation creates or replaces the translation of a question of the caller into the language
+func (c *Client) SaveQuestionTranslation(ctx context.Context, questionId uint, lang string, questionTranslation QuestionTranslation) (QuestionTranslation, error) {
+	var saved QuestionTranslation
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPut,
+		path:       questionTranslationsPath(questionId) + "/" + url.PathEscape(lang),
+		body:       questionTranslation,
+		idempotent: true,
+	}, &saved)
+	return saved, err
+}
+
+func (c *Client) DeleteQuestionTranslation(ctx context.Context, questionId uint, lang string) error {
+	return c.doJSON(ctx, request{
+		method:     http.MethodDelete,
+		path:       questionTranslationsPath(questionId) + "/" + url.PathEscape(lang),
+		idempotent: true,
+	}, nil)
+}
@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// iteratorPageSize is the size of the pages of the iterators that don't set one
+const iteratorPageSize = 100
+
+// ListQuestions returns a page of the questions in id order, after req.LastId
+func (c *Client) ListQuestions(ctx context.Context, req ListQuestionsRequest) ([]Question, error) {
+	var questions []Question
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       "/questions",
+		query:      readQuery(req.ReadOptions),
+		body:       req,
+		idempotent: true,
+	}, &questions)
+	return questions, err
+}
+
+// QuestionIterator pages through the questions of a ListQuestionsRequest, with the id of the last question read as
+// the cursor of the next page
+type QuestionIterator struct {
+	client   *Client
+	req      ListQuestionsRequest
+	page     []Question
+	question Question
+	done     bool
+	err      error
+}
+
+// IterateQuestions returns an iterator over every question of the request from req.LastId, 100 per page unless
+// req.PageSize is set
+func (c *Client) IterateQuestions(req ListQuestionsRequest) *QuestionIterator {
+	if req.PageSize == 0 {
+		req.PageSize = iteratorPageSize
+	}
+	return &QuestionIterator{client: c, req: req}
+}
+
+// Next advances to the next question, fetching the next page when needed. It returns false once every question was
+// read or when a page failed, see Err
+func (it *QuestionIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		page, err := it.client.ListQuestions(ctx, it.req)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.done = uint(len(page)) < it.req.PageSize
+		if len(page) == 0 {
+			return false
+		}
+		lastId := page[len(page)-1].Id
+		it.req.LastId = &lastId
+		it.page = page
+	}
+	it.question = it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// Question returns the question Next advanced to
+func (it *QuestionIterator) Question() Question {
+	return it.question
+}
+
+// Err returns the error that stopped the iteration
+func (it *QuestionIterator) Err() error {
+	return it.err
+}
+
+// ListAllQuestions reads every question of the request from req.LastId
+func (c *Client) ListAllQuestions(ctx context.Context, req ListQuestionsRequest) ([]Question, error) {
+	questions := []Question{}
+	it := c.IterateQuestions(req)
+	for it.Next(ctx) {
+		questions = append(questions, it.Question())
+	}
+	return questions, it.Err()
+}
+
+// CreateQuestion creates a question of the caller. It is sent with a random Idempotency-Key, so that retries replay
+// the response of the question created first
+func (c *Client) CreateQuestion(ctx context.Context, question Question) (Question, error) {
+	idempotencyKey, err := newIdempotencyKey()
+	if err != nil {
+		return Question{}, fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return c.CreateQuestionWithIdempotencyKey(ctx, question, idempotencyKey)
+}
+
+// CreateQuestionWithIdempotencyKey creates a question of the caller once per key, the API replays the response of the
+// first request with the key for a day
+func (c *Client) CreateQuestionWithIdempotencyKey(ctx context.Context, question Question, idempotencyKey string) (Question, error) {
+	var created Question
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPost,
+		path:       "/questions",
+		header:     http.Header{"Idempotency-Key": {idempotencyKey}},
+		body:       question,
+		idempotent: true,
+	}, &created)
+	return created, err
+}
+
+// UpdateQuestion replaces a question of the caller, its options are matched by id
+func (c *Client) UpdateQuestion(ctx context.Context, id uint, question Question) (Question, error) {
+	var updated Question
+	err := c.doJSON(ctx, request{
+		method:     http.MethodPut,
+		path:       "/questions/" + pathId(id),
+		body:       question,
+		idempotent: true,
+	}, &updated)
+	return updated, err
+}
+
+// MergePatchQuestion updates the fields of a question of the caller present in the JSON Merge Patch, like
+// map[string]any{"difficulty": "hard"}
+func (c *Client) MergePatchQuestion(ctx context.Context, id uint, patch any) (Question, error) {
+	var patched Question
+	err := c.doJSON(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/questions/" + pathId(id),
+		body:        patch,
+		contentType: "application/merge-patch+json",
+	}, &patched)
+	return patched, err
+}
+
+// JSONPatchQuestion applies the JSON Patch operations to a question of the caller, all of them or none
+func (c *Client) JSONPatchQuestion(ctx context.Context, id uint, operations []PatchOperation) (Question, error) {
+	var patched Question
+	err := c.doJSON(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/questions/" + pathId(id),
+		body:        operations,
+		contentType: "application/json-patch+json",
+	}, &patched)
+	return patched, err
+}
+
+// DeleteQuestion deletes a question, deleting a missing question succeeds
+func (c *Client) DeleteQuestion(ctx context.Context, id uint) error {
+	return c.doJSON(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/questions/" + pathId(id),
+		idempotent: true,
+	}, nil)
+}
+
+// BatchQuestions runs the operations in one request. When an atomic batch fails the response reports the failed
+// operation and the error is an Error with status 422
+func (c *Client) BatchQuestions(ctx context.Context, req BatchQuestionsRequest) (BatchQuestionsResponse, error) {
+	var res BatchQuestionsResponse
+	err := c.doReport(ctx, request{
+		method: http.MethodPost,
+		path:   "/questions/batch",
+		body:   req,
+	}, &res)
+	return res, err
+}
+
+// ExportQuestions streams the questions of the request as a file in req.Format, the caller closes it
+func (c *Client) ExportQuestions(ctx context.Context, req ExportQuestionsRequest) (io.ReadCloser, error) {
+	query := url.Values{}
+	if req.Format != "" {
+		query.Set("format", req.Format)
+	}
+	if req.AuthorId != 0 {
+		query.Set("authorId", pathId(req.AuthorId))
+	}
+	if req.Difficulty != "" {
+		query.Set("difficulty", req.Difficulty)
+	}
+	if len(req.Tags) > 0 {
+		query.Set("tags", strings.Join(req.Tags, ","))
+	}
+
+	res, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/questions/export",
+		query:      query,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// ImportQuestions creates the questions of the file read from r. The response reports every question of the file,
+// also when nothing was imported and the error is an Error with status 422
+func (c *Client) ImportQuestions(ctx context.Context, req ImportQuestionsRequest, r io.Reader) (ImportQuestionsResponse, error) {
+	query := url.Values{"format": {req.Format}}
+	if req.Mode != "" {
+		query.Set("mode", req.Mode)
+	}
+	header := http.Header{}
+	if req.ExportVersion != "" {
+		header.Set("X-Export-Version", req.ExportVersion)
+	}
+
+	var res ImportQuestionsResponse
+	err := c.doReport(ctx, request{
+		method:      http.MethodPost,
+		path:        "/questions/import",
+		query:       query,
+		header:      header,
+		body:        r,
+		contentType: "application/octet-stream",
+	}, &res)
+	return res, err
+}
+
+// ListMissingTranslations reports the questions of the caller without translation, or with an outdated one, into the
+// languages
+func (c *Client) ListMissingTranslations(ctx context.Context, languages []string) ([]MissingTranslation, error) {
+	var missingTranslations []MissingTranslation
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       "/questions/translations/missing",
+		query:      url.Values{"languages": {strings.Join(languages, ",")}},
+		idempotent: true,
+	}, &missingTranslations)
+	return missingTranslations, err
+}
+
+// GetQuestionStats reports the statistics of a revision of a question, its current revision when revision is 0
+func (c *Client) GetQuestionStats(ctx context.Context, id uint, revision uint) (QuestionReport, error) {
+	query := url.Values{}
+	if revision != 0 {
+		query.Set("revision", strconv.FormatUint(uint64(revision), 10))
+	}
+	var report QuestionReport
+	err := c.doJSON(ctx, request{
+		method:     http.MethodGet,
+		path:       "/questions/" + pathId(id) + "/stats",
+		query:      query,
+		idempotent: true,
+	}, &report)
+	return report, err
+}
+
+// readQuery returns the query params of the read options, the lang param takes precedence over Accept-Language
+func readQuery(opts ReadOptions) url.Values {
+	query := url.Values{}
+	if opts.Lang != "" {
+		query.Set("lang", opts.Lang)
+	}
+	if opts.RenderHTML {
+		query.Set("render", "html")
+	}
+	return query
+}
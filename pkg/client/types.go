@@ -0,0 +1,176 @@
+package client
+
+import (
+	"challenge/internal/entity"
+	"challenge/internal/stats"
+	"challenge/pkg/jsonpatch"
+	"encoding/json"
+)
+
+// The resources are the types the API serves, so the client decodes every field the server encodes
+type (
+	Question                  = entity.Question
+	QuestionOption            = entity.QuestionOption
+	QuestionTag               = entity.QuestionTag
+	QuestionTranslation       = entity.QuestionTranslation
+	QuestionOptionTranslation = entity.QuestionOptionTranslation
+	QuestionComment           = entity.QuestionComment
+	QuestionReport            = stats.QuestionReport
+	OptionReport              = stats.OptionReport
+	PatchOperation            = jsonpatch.Operation
+)
+
+const (
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+	ExportFormatQTI    = "qti"
+)
+
+const (
+	ImportFormatJSON   = "json"
+	ImportFormatNDJSON = "ndjson"
+	ImportFormatCSV    = "csv"
+	ImportFormatGIFT   = "gift"
+	ImportFormatQTI    = "qti"
+)
+
+const (
+	// ImportModeAllOrNothing imports nothing unless every question is valid
+	ImportModeAllOrNothing = "all_or_nothing"
+	// ImportModeBestEffort imports the valid questions and reports the others
+	ImportModeBestEffort = "best_effort"
+)
+
+const (
+	BatchModeAtomic      = "atomic"
+	BatchModeIndependent = "independent"
+)
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// ReadOptions localize and render the questions and options read
+type ReadOptions struct {
+	// Lang is the language of the translation to read, the original is read when the question isn't translated
+	Lang string
+	// RenderHTML fills the HTML of the bodies, explanations and feedbacks
+	RenderHTML bool
+}
+
+type ListQuestionsRequest struct {
+	LastId     *uint    `json:"lastId,omitempty"`
+	PageSize   uint     `json:"pageSize,omitempty"` // At most 1000
+	AuthorId   *uint    `json:"authorId,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+
+	ReadOptions `json:"-"`
+}
+
+type ExportQuestionsRequest struct {
+	Format     string // Defaults to json
+	AuthorId   uint
+	Difficulty string
+	Tags       []string
+}
+
+type ImportQuestionsRequest struct {
+	Format string
+	Mode   string // Defaults to all or nothing
+	// ExportVersion is the X-Export-Version of the export of the file, checked by the API when set
+	ExportVersion string
+}
+
+type ImportQuestionsResponse struct {
+	Imported uint              `json:"imported"`
+	Failed   uint              `json:"failed"`
+	Rows     []ImportRowReport `json:"rows"`
+}
+
+// ImportRowReport is the outcome of a question in the import file
+type ImportRowReport struct {
+	Row        int      `json:"row"` // Element or line number where the question starts
+	QuestionId *uint    `json:"questionId,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type BatchQuestionsRequest struct {
+	Mode       string           `json:"mode,omitempty"` // Defaults to atomic
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchOperation struct {
+	Op       string    `json:"op"`
+	Id       uint      `json:"id,omitempty"`
+	Question *Question `json:"question,omitempty"`
+}
+
+type BatchQuestionsResponse struct {
+	Results []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult has the status the operation would have had as a single request
+type BatchOperationResult struct {
+	Status   int             `json:"status"`
+	Question *Question       `json:"question,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+}
+
+// Err returns the typed error of a failed operation, nil when it succeeded
+func (r BatchOperationResult) Err() error {
+	if len(r.Error) == 0 {
+		return nil
+	}
+	var errRes errorResponse
+	if err := json.Unmarshal(r.Error, &errRes); err != nil {
+		return &Error{StatusCode: r.Status, Message: string(r.Error), Body: r.Error}
+	}
+	if len(errRes.Errors) > 0 {
+		return &ValidationError{StatusCode: r.Status, Errors: errRes.Errors}
+	}
+	return &Error{StatusCode: r.Status, Message: errRes.Error, Body: r.Error}
+}
+
+type MissingTranslation struct {
+	QuestionId uint   `json:"questionId"`
+	Language   string `json:"language"`
+	Missing    bool   `json:"missing"`   // The question has no translation into the language
+	Outdated   bool   `json:"outdated"`  // The question changed after it was translated
+	OptionIds  []uint `json:"optionIds"` // Options without translation
+}
+
+type CreateQuestionOptionRequest struct {
+	Body           string `json:"body"`
+	BodyFormat     string `json:"bodyFormat,omitempty"`
+	Feedback       string `json:"feedback,omitempty"`
+	FeedbackFormat string `json:"feedbackFormat,omitempty"`
+	Correct        *bool  `json:"correct,omitempty"`  // Required except for ordering questions
+	Position       *uint  `json:"position,omitempty"` // Defaults to the end
+	AttachmentId   *uint  `json:"attachmentId,omitempty"`
+}
+
+type UpdateQuestionOptionRequest struct {
+	Body           string `json:"body"`
+	BodyFormat     string `json:"bodyFormat,omitempty"`
+	Feedback       string `json:"feedback,omitempty"`
+	FeedbackFormat string `json:"feedbackFormat,omitempty"`
+	Correct        *bool  `json:"correct,omitempty"` // Required except for ordering questions
+	AttachmentId   *uint  `json:"attachmentId,omitempty"`
+}
+
+type CreateQuestionCommentRequest struct {
+	Body     string `json:"body"`
+	OptionId *uint  `json:"optionId,omitempty"`
+	ParentId *uint  `json:"parentId,omitempty"` // Replies to the comment
+}
+
+type StreamQuestionEventsRequest struct {
+	AuthorId uint
+	Tags     []string
+	// LastEventId resumes the stream after the event, as long as the API still keeps it
+	LastEventId string
+}
@@ -2,6 +2,9 @@ package env
 
 import (
 	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 func GetOrDefault(key string, defaultValue string) string {
@@ -11,3 +14,14 @@ func GetOrDefault(key string, defaultValue string) string {
 	}
 	return val
 }
+
+// GetDurationOrDefault parses the duration in the variable, like "30s". Invalid or non-positive durations are logged
+// and the default is used instead
+func GetDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(GetOrDefault(key, defaultValue.String()))
+	if err != nil || duration <= 0 {
+		log.Warn().Err(err).Msgf("Invalid %s, using %s", key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
@@ -18,3 +18,14 @@ type PreloadOption string
 func (o PreloadOption) Apply(db *gorm.DB) *gorm.DB {
 	return db.Preload(string(o))
 }
+
+type OrderedPreloadOption struct {
+	Association string
+	Order       string
+}
+
+func (o OrderedPreloadOption) Apply(db *gorm.DB) *gorm.DB {
+	return db.Preload(o.Association, func(db *gorm.DB) *gorm.DB {
+		return db.Order(o.Order)
+	})
+}
@@ -17,11 +17,55 @@ type RepositoryImp struct {
 }
 
 func (r *RepositoryImp) NewQuery(ctx context.Context) *gorm.DB {
-	return r.db.WithContext(ctx).Table(r.tableName)
+	return dbFromContext(ctx, r.db).WithContext(ctx).Table(r.tableName)
 }
 
 func (r *RepositoryImp) RunInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		return fn(context.WithValue(ctx, contextTransactionKey, tx))
+	return runInTransaction(ctx, r.db, fn)
+}
+
+// runInTransaction runs fn in a transaction of db, or in a savepoint of the transaction in ctx.
+// The functions fn passes to AfterCommit run once the outermost transaction commits
+func runInTransaction(ctx context.Context, db *gorm.DB, fn func(txCtx context.Context) error) error {
+	parent, nested := ctx.Value(contextAfterCommitKey).(*afterCommit)
+	hooks := &afterCommit{}
+	err := dbFromContext(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(context.WithValue(ctx, contextTransactionKey, tx), contextAfterCommitKey, hooks))
 	})
+	if err != nil {
+		return err
+	}
+
+	// Savepoints hand their functions to the outer transaction, which may still roll back
+	if nested {
+		parent.fns = append(parent.fns, hooks.fns...)
+		return nil
+	}
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+type afterCommit struct {
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction in ctx commits, it never runs if the transaction rolls back.
+// Outside a transaction fn runs right away
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(contextAfterCommitKey).(*afterCommit); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// dbFromContext returns the transaction started by RunInTransaction, if any.
+// Nested transactions become savepoints of the outer one
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(contextTransactionKey).(*gorm.DB); ok {
+		return tx
+	}
+	return db
 }
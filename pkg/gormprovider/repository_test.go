@@ -0,0 +1,51 @@
+package gormprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAfterCommit(t *testing.T) {
+	provider := NewTestSQLiteProvider(t, "CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	repository := provider.NewRepository("items")
+	ctx := context.Background()
+
+	committed := []string{}
+	afterCommit := func(ctx context.Context, name string) {
+		AfterCommit(ctx, func() {
+			committed = append(committed, name)
+		})
+	}
+
+	afterCommit(ctx, "outside")
+	assert.Equal(t, []string{"outside"}, committed)
+
+	err := provider.RunInTransaction(ctx, func(txCtx context.Context) error {
+		afterCommit(txCtx, "outer")
+		require.NoError(t, repository.RunInTransaction(txCtx, func(txCtx context.Context) error {
+			afterCommit(txCtx, "savepoint")
+			return nil
+		}))
+		require.Error(t, repository.RunInTransaction(txCtx, func(txCtx context.Context) error {
+			afterCommit(txCtx, "rolled back savepoint")
+			return errors.New("rolled back")
+		}))
+
+		// Nothing runs before the outer transaction commits
+		assert.Len(t, committed, 1)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outside", "outer", "savepoint"}, committed)
+
+	err = repository.RunInTransaction(ctx, func(txCtx context.Context) error {
+		afterCommit(txCtx, "rolled back")
+		return errors.New("rolled back")
+	})
+	require.Error(t, err)
+	assert.Len(t, committed, 3)
+}
@@ -13,7 +13,10 @@ import (
 	"gorm.io/gorm"
 )
 
-const contextTransactionKey ContextString = "tx"
+const (
+	contextTransactionKey ContextString = "tx"
+	contextAfterCommitKey ContextString = "afterCommit"
+)
 
 type ContextString string
 
@@ -56,7 +59,33 @@ func (p *SQLiteProvider) NewRepository(tableName string) Repository {
 }
 
 func (p *SQLiteProvider) RunInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
-	return p.DB.Transaction(func(tx *gorm.DB) error {
-		return fn(context.WithValue(ctx, contextTransactionKey, tx))
-	})
+	return runInTransaction(ctx, p.DB, fn)
+}
+
+// Column is a column added to a table after the table was first created
+type Column struct {
+	Table      string
+	Name       string
+	Definition string // Type and constraints, like "INTEGER NOT NULL DEFAULT 1"
+}
+
+// AddMissingColumns adds the columns the tables don't have yet, so that databases created before the columns
+// existed get them too. The columns present are left as they are, so it runs on every start
+func (p *SQLiteProvider) AddMissingColumns(columns []Column) error {
+	for _, column := range columns {
+		var count int64
+		err := p.DB.Raw("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", column.Table, column.Name).Scan(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		err = p.DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", column.Table, column.Name, column.Definition)).Error
+		if err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", column.Table, column.Name, err)
+		}
+	}
+	return nil
 }
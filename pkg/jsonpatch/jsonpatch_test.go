@@ -0,0 +1,167 @@
+package jsonpatch_test
+
+import (
+	"challenge/pkg/jsonpatch"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	type Test struct {
+		TestName string
+		Doc      string
+		Patch    string
+		Expected string
+	}
+	// Examples from RFC 7396 appendix A
+	tests := []Test{
+		{TestName: "ReplaceMember", Doc: `{"a":"b"}`, Patch: `{"a":"c"}`, Expected: `{"a":"c"}`},
+		{TestName: "AddMember", Doc: `{"a":"b"}`, Patch: `{"b":"c"}`, Expected: `{"a":"b","b":"c"}`},
+		{TestName: "RemoveMember", Doc: `{"a":"b","b":"c"}`, Patch: `{"a":null}`, Expected: `{"b":"c"}`},
+		{TestName: "NestedMember", Doc: `{"a":{"b":"c"}}`, Patch: `{"a":{"b":"d","c":null}}`, Expected: `{"a":{"b":"d"}}`},
+		{TestName: "ReplaceArray", Doc: `{"a":[{"b":"c"}]}`, Patch: `{"a":[1]}`, Expected: `{"a":[1]}`},
+		{TestName: "ReplaceDocument", Doc: `{"a":"foo"}`, Patch: `["c"]`, Expected: `["c"]`},
+		{TestName: "ObjectIntoScalar", Doc: `{"e":null}`, Patch: `{"a":{"bb":{"ccc":null}}}`, Expected: `{"e":null,"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			patched, err := jsonpatch.MergePatch([]byte(test.Doc), []byte(test.Patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, test.Expected, string(patched))
+		})
+	}
+
+	_, err := jsonpatch.MergePatch([]byte(`{}`), []byte(`{`))
+	assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+}
+
+func TestApply(t *testing.T) {
+	type Test struct {
+		TestName    string
+		Doc         string
+		Patch       string
+		Expected    string
+		ExpectedErr error
+	}
+	// Mostly examples from RFC 6902 appendix A
+	tests := []Test{
+		{
+			TestName: "AddMember",
+			Doc:      `{"foo":"bar"}`,
+			Patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			Expected: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			TestName: "AddArrayElement",
+			Doc:      `{"foo":["bar","baz"]}`,
+			Patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			Expected: `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			TestName: "AppendArrayElement",
+			Doc:      `{"foo":["bar"]}`,
+			Patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			Expected: `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			TestName: "RemoveArrayElement",
+			Doc:      `{"foo":["bar","qux","baz"]}`,
+			Patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			Expected: `{"foo":["bar","baz"]}`,
+		},
+		{
+			TestName: "ReplaceValue",
+			Doc:      `{"baz":"qux","foo":"bar"}`,
+			Patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			Expected: `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			TestName: "MoveValue",
+			Doc:      `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			Patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			Expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			TestName: "MoveArrayElement",
+			Doc:      `{"foo":["all","grass","cows","eat"]}`,
+			Patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			Expected: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			TestName: "CopyValue",
+			Doc:      `{"foo":{"bar":1}}`,
+			Patch:    `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+			Expected: `{"foo":{"bar":1},"baz":{"bar":2}}`,
+		},
+		{
+			TestName: "TestSuccess",
+			Doc:      `{"baz":"qux","foo":["a",2,"c"]}`,
+			Patch:    `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			Expected: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			TestName: "EscapedPointer",
+			Doc:      `{"a/b":{"m~n":1}}`,
+			Patch:    `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`,
+			Expected: `{"a/b":{"m~n":2}}`,
+		},
+		{
+			TestName:    "TestFailure",
+			Doc:         `{"baz":"qux"}`,
+			Patch:       `[{"op":"test","path":"/baz","value":"bar"}]`,
+			ExpectedErr: jsonpatch.ErrTestFailed,
+		},
+		{
+			TestName:    "AddToMissingParent",
+			Doc:         `{"foo":"bar"}`,
+			Patch:       `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			ExpectedErr: jsonpatch.ErrPathNotFound,
+		},
+		{
+			TestName:    "RemoveMissingMember",
+			Doc:         `{"foo":"bar"}`,
+			Patch:       `[{"op":"remove","path":"/baz"}]`,
+			ExpectedErr: jsonpatch.ErrPathNotFound,
+		},
+		{
+			TestName:    "IndexOutOfRange",
+			Doc:         `{"foo":["bar"]}`,
+			Patch:       `[{"op":"replace","path":"/foo/01","value":"baz"}]`,
+			ExpectedErr: jsonpatch.ErrPathNotFound,
+		},
+		{
+			TestName:    "MissingValue",
+			Doc:         `{"foo":"bar"}`,
+			Patch:       `[{"op":"add","path":"/baz"}]`,
+			ExpectedErr: jsonpatch.ErrInvalidPatch,
+		},
+		{
+			TestName:    "UnknownOp",
+			Doc:         `{"foo":"bar"}`,
+			Patch:       `[{"op":"merge","path":"/foo","value":"baz"}]`,
+			ExpectedErr: jsonpatch.ErrInvalidPatch,
+		},
+		{
+			TestName:    "MoveIntoChild",
+			Doc:         `{"foo":{"bar":{}}}`,
+			Patch:       `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			ExpectedErr: jsonpatch.ErrInvalidPatch,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.TestName, func(t *testing.T) {
+			var operations []jsonpatch.Operation
+			require.NoError(t, json.Unmarshal([]byte(test.Patch), &operations))
+			patched, err := jsonpatch.Apply([]byte(test.Doc), operations)
+			if test.ExpectedErr != nil {
+				assert.ErrorIs(t, err, test.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.Expected, string(patched))
+		})
+	}
+}
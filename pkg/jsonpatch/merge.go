@@ -0,0 +1,40 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch applies an RFC 7396 merge patch to the document
+func MergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var docValue any
+	if err := json.Unmarshal(doc, &docValue); err != nil {
+		return nil, err
+	}
+	var patchValue any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+	return json.Marshal(MergeValue(docValue, patchValue))
+}
+
+// MergeValue merges a decoded patch into a decoded document. Objects are merged member by member,
+// null members are removed and every other value, arrays included, replaces the target
+func MergeValue(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = MergeValue(targetObject[key], value)
+	}
+	return targetObject
+}